@@ -74,6 +74,7 @@ func SetupRoutes(r *gin.Engine, h *handler.Handler, m *middleware.AuthMiddleware
 			// More specific GET routes first so they are not matched by /:id
 			videos.GET("/:id/metadata", h.Video.GetMetadata)
 			videos.GET("/:id/playback-url", h.Video.GetPlaybackURL)
+			videos.GET("/:id/waveform", h.Video.GetWaveform)
 			videos.POST("/:id/auto-cut", h.Video.AutoCut)
 			videos.GET("/:id", h.Video.GetByID)
 			videos.DELETE("/:id", h.Video.Delete)
@@ -99,12 +100,20 @@ func SetupRoutes(r *gin.Engine, h *handler.Handler, m *middleware.AuthMiddleware
 		{
 			clips.POST("", h.Clip.Create)
 			clips.GET("", h.Clip.List)
+			clips.POST("/batch-render", h.Clip.BatchRender)
+			clips.GET("/batch-render/:jobId/download", h.Clip.BatchDownload)
+			clips.GET("/export-presets", h.Clip.ListExportPresets)
 			clips.GET("/:id/playback-url", h.Clip.GetPlaybackURL)
 			clips.GET("/:id", h.Clip.GetByID)
 			clips.PUT("/:id", h.Clip.Update)
 			clips.DELETE("/:id", h.Clip.Delete)
 			clips.GET("/:id/captions/srt", h.Clip.GetCaptionsSRT)
 			clips.GET("/:id/captions/vtt", h.Clip.GetCaptionsVTT)
+			clips.GET("/:id/edit-list", h.Clip.GetEditList)
+			clips.GET("/:id/reframe", h.Clip.GetReframe)
+			clips.PUT("/:id/reframe", h.Clip.UpdateReframe)
+			clips.GET("/:id/segments", h.Clip.GetSegments)
+			clips.PUT("/:id/segments", h.Clip.UpdateSegments)
 			clips.POST("/:id/render", h.Clip.Render)
 			clips.POST("/:id/cancel", h.Clip.CancelRender)
 			clips.GET("/:id/status", h.Clip.GetRenderStatus)
@@ -116,6 +125,7 @@ func SetupRoutes(r *gin.Engine, h *handler.Handler, m *middleware.AuthMiddleware
 		{
 			clipStyles.GET("", h.Clip.GetStyle)
 			clipStyles.PUT("", h.Clip.UpdateStyle)
+			clipStyles.POST("/assets", h.Clip.StyleAssetUploadURL)
 			clipStyles.POST("/apply-template/:templateId", h.Clip.ApplyTemplate)
 		}
 
@@ -141,11 +151,19 @@ func SetupRoutes(r *gin.Engine, h *handler.Handler, m *middleware.AuthMiddleware
 		}
 	}
 
-	// Thumbnail: allow Bearer or ?token= so <img src="...?token=..."> works (no CORS to MinIO)
+	// Thumbnail, HLS playlists and thumbnail track: allow Bearer or ?token= so <img src="...?token=...">, <track> and native HLS players work (no CORS to MinIO)
 	videoThumbnail := r.Group("/api/v1/videos")
 	videoThumbnail.Use(m.AuthenticateThumbnailOrBearer())
 	{
 		videoThumbnail.GET("/:id/thumbnail", h.Video.GetThumbnail)
+		videoThumbnail.GET("/:id/hls/*file", h.Video.GetHLSPlaylist)
+		videoThumbnail.GET("/:id/thumbnails.vtt", h.Video.GetThumbnailTrack)
+	}
+	clipThumbnail := r.Group("/api/v1/clips")
+	clipThumbnail.Use(m.AuthenticateClipThumbnailOrBearer())
+	{
+		clipThumbnail.GET("/:id/thumbnail", h.Clip.GetThumbnail)
+		clipThumbnail.GET("/:id/preview", h.Clip.GetPreview)
 	}
 
 	r.GET("/ws", m.AuthenticateWS(), h.WebSocket.Handle)
@@ -20,6 +20,7 @@ import (
 	"reelcut/internal/queue"
 	"reelcut/internal/repository"
 	"reelcut/internal/service"
+	"reelcut/internal/video"
 	"reelcut/internal/worker"
 	"reelcut/pkg/database"
 	"reelcut/pkg/logger"
@@ -112,6 +113,10 @@ func main() {
 	if err != nil {
 		log.Fatalf("storage: %v", err)
 	}
+	sourceCache, err := service.NewSourceCache(storageSvc, cfg.Video.SourceCacheDir, int64(cfg.Video.SourceCacheMaxGB)<<30)
+	if err != nil {
+		log.Fatalf("source cache: %v", err)
+	}
 
 	// Queue
 	queueClient, err := queue.NewQueueClient(cfg.Asynq.RedisURL)
@@ -150,8 +155,21 @@ func main() {
 	videoSvc := service.NewVideoService(videoRepo, projectRepo, jobRepo, storageSvc, queueClient, userRepo, usageLogRepo)
 	transcriptionSvc := service.NewTranscriptionService(transcriptionRepo, segmentRepo, wordRepo, videoRepo, queueClient)
 	analysisSvc := service.NewAnalysisService(videoAnalysisRepo, transcriptionRepo, segmentRepo, videoRepo, queueClient)
-	renderingSvc := service.NewRenderingService(clipRepo, clipStyleRepo, videoRepo, transcriptionSvc, storageSvc)
-	clipSvc := service.NewClipService(clipRepo, clipStyleRepo, videoRepo, transcriptionSvc, jobRepo, queueClient, templateRepo, userRepo, usageLogRepo)
+	renderCutMode, err := video.ParseCutMode(cfg.Video.RenderCutMode)
+	if err != nil {
+		log.Fatalf("RENDER_CUT_MODE: %v", err)
+	}
+	autocutCutMode, err := video.ParseCutMode(cfg.Video.AutoCutMode)
+	if err != nil {
+		log.Fatalf("AUTOCUT_CUT_MODE: %v", err)
+	}
+	fillerWords, err := service.LoadFillerDictionary(cfg.Video.FillerWordsFile)
+	if err != nil {
+		log.Fatalf("FILLER_WORDS_FILE: %v", err)
+	}
+	renderingSvc := service.NewRenderingService(clipRepo, clipStyleRepo, videoRepo, videoAnalysisRepo, transcriptionSvc, storageSvc, sourceCache, renderCutMode, fillerWords)
+	clipSvc := service.NewClipService(clipRepo, clipStyleRepo, videoRepo, storageSvc, transcriptionSvc, jobRepo, queueClient, templateRepo, userRepo, usageLogRepo, renderingSvc)
+	batchRenderSvc := service.NewBatchRenderService(clipRepo, clipStyleRepo, videoRepo, jobRepo, userRepo, usageLogRepo, clipSvc, storageSvc, queueClient, renderingSvc)
 	templateSvc := service.NewTemplateService(templateRepo)
 	subscriptionSvc := service.NewSubscriptionService(subscriptionRepo, userRepo, cfg.Stripe.SecretKey, cfg.Stripe.PriceIDPro)
 	var transcriber ai.Transcriber
@@ -172,16 +190,18 @@ func main() {
 	asynqOpt, _ := asynq.ParseRedisURI(cfg.Asynq.RedisURL)
 	asynqSrv := asynq.NewServer(asynqOpt, asynq.Config{Concurrency: cfg.Asynq.Concurrency})
 	mux := asynq.NewServeMux()
-	videoWorker := worker.NewVideoWorker(videoRepo, jobRepo, storageSvc, jobNotifier)
+	videoWorker := worker.NewVideoWorker(videoRepo, jobRepo, storageSvc, sourceCache, cfg.Video.SpriteInterval, jobNotifier)
 	videoWorker.Register(mux)
-	transcriptionWorker := worker.NewTranscriptionWorker(transcriptionRepo, segmentRepo, wordRepo, videoRepo, storageSvc, transcriber, queueClient)
+	transcriptionWorker := worker.NewTranscriptionWorker(transcriptionRepo, segmentRepo, wordRepo, videoRepo, sourceCache, transcriber, queueClient)
 	transcriptionWorker.Register(mux)
-	analysisWorker := worker.NewAnalysisWorker(videoAnalysisRepo, videoRepo, transcriptionRepo, segmentRepo, storageSvc)
+	analysisWorker := worker.NewAnalysisWorker(videoAnalysisRepo, videoRepo, transcriptionRepo, segmentRepo, sourceCache)
 	analysisWorker.Register(mux)
-	autocutWorker := worker.NewAutoCutWorker(videoRepo, clipRepo, analysisSvc, clipSvc, storageSvc, transcriptionRepo, segmentRepo)
+	autocutWorker := worker.NewAutoCutWorker(videoRepo, clipRepo, analysisSvc, clipSvc, storageSvc, sourceCache, transcriptionRepo, segmentRepo, autocutCutMode, jobRepo, jobNotifier)
 	autocutWorker.Register(mux)
-	renderingWorker := worker.NewRenderingWorker(renderingSvc, clipRepo, jobRepo, jobNotifier)
+	renderingWorker := worker.NewRenderingWorker(renderingSvc, batchRenderSvc, clipRepo, jobRepo, jobNotifier)
 	renderingWorker.Register(mux)
+	batchExportWorker := worker.NewBatchExportWorker(batchRenderSvc, jobNotifier)
+	batchExportWorker.Register(mux)
 	go func() {
 		if err := asynqSrv.Run(mux); err != nil {
 			log.Printf("asynq worker: %v", err)
@@ -196,9 +216,9 @@ func main() {
 		Video:        handler.NewVideoHandler(videoSvc, transcriptionSvc, queueClient, cfg.JWT.Secret, cfg.S3.Endpoint),
 		Transcription: handler.NewTranscriptionHandler(transcriptionSvc),
 		Analysis:     handler.NewAnalysisHandler(analysisSvc, videoSvc),
-		Clip:         handler.NewClipHandler(clipSvc, videoSvc),
+		Clip:         handler.NewClipHandler(clipSvc, videoSvc, batchRenderSvc, cfg.JWT.Secret),
 		Template:     handler.NewTemplateHandler(templateSvc),
-		Job:          handler.NewJobHandler(jobRepo),
+		Job:          handler.NewJobHandler(jobRepo, queueClient, batchRenderSvc),
 		Subscription: handler.NewSubscriptionHandler(subscriptionSvc),
 		Webhook:      handler.NewWebhookHandler(cfg.Stripe.WebhookSecret, subscriptionRepo, userRepo),
 		WebSocket:    handler.NewWebSocketHandler(wsHub),
@@ -1,20 +1,234 @@
 package ai
 
-import "encoding/json"
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"sort"
+	"sync"
 
-// FaceDetection is a placeholder for face/speaker detection (MediaPipe/OpenCV later).
+	pigo "github.com/esimov/pigo/core"
+)
+
+// facefinder is pigo's frontal face cascade (see cascade/LICENSE).
+//
+//go:embed cascade/facefinder
+var facefinder []byte
+
+var (
+	faceCascadeOnce sync.Once
+	faceCascade     *pigo.Pigo
+	faceCascadeErr  error
+)
+
+const (
+	// FaceSampleFPS is how many frames per second DetectFaces samples. High enough to see lips move.
+	FaceSampleFPS = 5
+	// faceSampleWidth is the width frames are scaled to for detection.
+	faceSampleWidth = 320
+	// faceMinQuality is the lowest pigo score kept as a face.
+	faceMinQuality = 5.0
+	// faceTrackMaxGap is how long (seconds) a track survives without a matching detection.
+	faceTrackMaxGap = 1.0
+	// faceTrackMaxJump is how far (fraction of frame width) a face may move between samples and stay
+	// in its track.
+	faceTrackMaxJump = 0.15
+	// faceTrackMinDetections drops tracks seen in fewer samples (mostly false positives).
+	faceTrackMinDetections = 3
+)
+
+// FaceDetection is one face in one sampled frame. Bbox is x, y, w, h as fractions of the frame.
+// Activity is how much the mouth region changed since the previous sample (0-1), a cheap cue for who
+// is speaking.
 type FaceDetection struct {
 	Timestamp  float64   `json:"timestamp"`
 	Bbox       []float64 `json:"bbox"` // x, y, w, h
 	Confidence float64   `json:"confidence"`
+	Activity   float64   `json:"activity"`
+}
+
+// FaceTrack is one face followed across samples, detections in time order.
+type FaceTrack struct {
+	ID         int             `json:"id"`
+	Detections []FaceDetection `json:"detections"`
+}
+
+// Center returns the centre of the detection's box.
+func (d FaceDetection) Center() (x, y float64) {
+	if len(d.Bbox) < 4 {
+		return 0.5, 0.5
+	}
+	return d.Bbox[0] + d.Bbox[2]/2, d.Bbox[1] + d.Bbox[3]/2
+}
+
+// DetectFaces samples videoPath at FaceSampleFPS and finds frontal faces on the CPU with pigo.
+// width and height are the source frame size, used to scale samples without distorting them. It
+// returns nil when the video path or size is unknown.
+func DetectFaces(ctx context.Context, videoPath string, width, height int) ([]FaceDetection, error) {
+	if videoPath == "" || width <= 0 || height <= 0 {
+		return nil, nil
+	}
+	classifier, err := loadFaceCascade()
+	if err != nil {
+		return nil, err
+	}
+	cols := faceSampleWidth
+	rows := max(2, int(float64(cols)*float64(height)/float64(width)/2+0.5)*2)
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-v", "error",
+		"-i", videoPath,
+		"-an",
+		"-vf", fmt.Sprintf("fps=%d,scale=%d:%d,format=gray", FaceSampleFPS, cols, rows),
+		"-f", "rawvideo", "-pix_fmt", "gray",
+		"pipe:1",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	r := bufio.NewReaderSize(stdout, cols*rows)
+	frame, prev := make([]byte, cols*rows), make([]byte, cols*rows)
+	var out []FaceDetection
+	for i := 0; ; i++ {
+		if _, err := io.ReadFull(r, frame); err != nil {
+			break
+		}
+		for _, d := range detectFrame(classifier, frame, cols, rows) {
+			d.Timestamp = float64(i) / FaceSampleFPS
+			if i > 0 {
+				d.Activity = mouthActivity(frame, prev, cols, rows, d.Bbox)
+			}
+			out = append(out, d)
+		}
+		frame, prev = prev, frame
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("ffmpeg face samples: %w (output: %s)", err, stderr.String())
+	}
+	return out, nil
+}
+
+// loadFaceCascade unpacks the embedded cascade once.
+func loadFaceCascade() (*pigo.Pigo, error) {
+	faceCascadeOnce.Do(func() {
+		faceCascade, faceCascadeErr = pigo.NewPigo().Unpack(facefinder)
+	})
+	return faceCascade, faceCascadeErr
+}
+
+// detectFrame runs the cascade over one grayscale frame and returns the clustered faces.
+func detectFrame(classifier *pigo.Pigo, pixels []byte, cols, rows int) []FaceDetection {
+	params := pigo.CascadeParams{
+		MinSize:     max(20, cols/20),
+		MaxSize:     min(cols, rows),
+		ShiftFactor: 0.1,
+		ScaleFactor: 1.1,
+		ImageParams: pigo.ImageParams{Pixels: pixels, Rows: rows, Cols: cols, Dim: cols},
+	}
+	dets := classifier.ClusterDetections(classifier.RunCascade(params, 0), 0.2)
+	var out []FaceDetection
+	for _, d := range dets {
+		if d.Q < faceMinQuality {
+			continue
+		}
+		s := float64(d.Scale)
+		out = append(out, FaceDetection{
+			Bbox: []float64{
+				(float64(d.Col) - s/2) / float64(cols),
+				(float64(d.Row) - s/2) / float64(rows),
+				s / float64(cols),
+				s / float64(rows),
+			},
+			Confidence: float64(d.Q),
+		})
+	}
+	return out
+}
+
+// mouthActivity is the mean absolute change, scaled to 0-1, of the lower-middle part of a face box
+// (where the mouth is) between two frames.
+func mouthActivity(frame, prev []byte, cols, rows int, bbox []float64) float64 {
+	x0, x1 := int((bbox[0]+bbox[2]*0.25)*float64(cols)), int((bbox[0]+bbox[2]*0.75)*float64(cols))
+	y0, y1 := int((bbox[1]+bbox[3]*0.6)*float64(rows)), int((bbox[1]+bbox[3]*0.95)*float64(rows))
+	x0, x1, y0, y1 = max(x0, 0), min(x1, cols), max(y0, 0), min(y1, rows)
+	var sum, n int
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			d := int(frame[y*cols+x]) - int(prev[y*cols+x])
+			if d < 0 {
+				d = -d
+			}
+			sum += d
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(sum) / float64(n) / 255
 }
 
-// DetectFaces stub returns empty list. Replace with MediaPipe/OpenCV when needed.
-func DetectFaces(_ string) ([]FaceDetection, error) {
-	return nil, nil
+// TrackFaces links detections into tracks: each detection joins the nearest track whose last face was
+// seen within faceTrackMaxGap and faceTrackMaxJump, or starts a new one. Tracks with fewer than
+// faceTrackMinDetections faces are dropped.
+func TrackFaces(dets []FaceDetection) []FaceTrack {
+	sorted := append([]FaceDetection(nil), dets...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp < sorted[j].Timestamp })
+	var tracks []*FaceTrack
+	for i := 0; i < len(sorted); {
+		// All faces of one sample are assigned together so two faces cannot join the same track.
+		j := i
+		for j < len(sorted) && sorted[j].Timestamp == sorted[i].Timestamp {
+			j++
+		}
+		taken := map[*FaceTrack]bool{}
+		for _, d := range sorted[i:j] {
+			dx, dy := d.Center()
+			var best *FaceTrack
+			bestDist := faceTrackMaxJump
+			for _, t := range tracks {
+				last := t.Detections[len(t.Detections)-1]
+				if taken[t] || d.Timestamp-last.Timestamp > faceTrackMaxGap {
+					continue
+				}
+				lx, ly := last.Center()
+				if dist := math.Hypot(dx-lx, dy-ly); dist <= bestDist {
+					best, bestDist = t, dist
+				}
+			}
+			if best == nil {
+				best = &FaceTrack{ID: len(tracks) + 1}
+				tracks = append(tracks, best)
+			}
+			best.Detections = append(best.Detections, d)
+			taken[best] = true
+		}
+		i = j
+	}
+	var out []FaceTrack
+	for _, t := range tracks {
+		if len(t.Detections) >= faceTrackMinDetections {
+			out = append(out, *t)
+		}
+	}
+	return out
 }
 
 // FacesToJSON returns JSON for video_analysis.faces_detected.
-func FacesToJSON(faces []FaceDetection) ([]byte, error) {
-	return json.Marshal(faces)
+func FacesToJSON(tracks []FaceTrack) ([]byte, error) {
+	if tracks == nil {
+		tracks = []FaceTrack{}
+	}
+	return json.Marshal(tracks)
 }
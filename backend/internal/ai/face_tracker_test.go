@@ -0,0 +1,65 @@
+package ai
+
+import "testing"
+
+func face(ts, x, y float64) FaceDetection {
+	return FaceDetection{Timestamp: ts, Bbox: []float64{x - 0.05, y - 0.05, 0.1, 0.1}, Confidence: 10}
+}
+
+func TestTrackFaces(t *testing.T) {
+	var dets []FaceDetection
+	for i := 0; i < 5; i++ {
+		ts := float64(i) * 0.2
+		dets = append(dets, face(ts, 0.25+0.01*float64(i), 0.4), face(ts, 0.75, 0.4))
+	}
+	// A one-off false positive and a face that reappears after a long gap start their own tracks; the
+	// false positive is dropped for being too short.
+	dets = append(dets, face(0.4, 0.5, 0.9))
+	for i := 0; i < 3; i++ {
+		dets = append(dets, face(5+float64(i)*0.2, 0.25, 0.4))
+	}
+
+	tracks := TrackFaces(dets)
+	if len(tracks) != 3 {
+		t.Fatalf("TrackFaces: %d tracks, want 3: %+v", len(tracks), tracks)
+	}
+	for i, want := range []struct {
+		n    int
+		x, y float64
+	}{{5, 0.25, 0.4}, {5, 0.75, 0.4}, {3, 0.25, 0.4}} {
+		tr := tracks[i]
+		x, y := tr.Detections[0].Center()
+		if len(tr.Detections) != want.n || x != want.x || y != want.y {
+			t.Errorf("track %d: %d detections from (%.2f, %.2f), want %d from (%.2f, %.2f)", i, len(tr.Detections), x, y, want.n, want.x, want.y)
+		}
+	}
+}
+
+func TestMouthActivity(t *testing.T) {
+	const cols, rows = 40, 40
+	prev, frame := make([]byte, cols*rows), make([]byte, cols*rows)
+	bbox := []float64{0.25, 0.25, 0.5, 0.5}
+	if got := mouthActivity(frame, prev, cols, rows, bbox); got != 0 {
+		t.Errorf("mouthActivity(still) = %v, want 0", got)
+	}
+	// Light up the mouth region: lower part of the box, middle half across.
+	for y := 22; y < 29; y++ {
+		for x := 15; x < 25; x++ {
+			frame[y*cols+x] = 255
+		}
+	}
+	if got := mouthActivity(frame, prev, cols, rows, bbox); got != 1 {
+		t.Errorf("mouthActivity(moving) = %v, want 1", got)
+	}
+}
+
+func TestDetectFrame_Blank(t *testing.T) {
+	classifier, err := loadFaceCascade()
+	if err != nil {
+		t.Fatalf("loadFaceCascade: %v", err)
+	}
+	const cols, rows = 320, 180
+	if got := detectFrame(classifier, make([]byte, cols*rows), cols, rows); len(got) != 0 {
+		t.Errorf("detectFrame(blank) = %+v, want no faces", got)
+	}
+}
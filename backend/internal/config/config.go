@@ -17,6 +17,21 @@ type Config struct {
 	Asynq    AsynqConfig
 	Whisper  WhisperConfig
 	Stripe   StripeConfig
+	Video    VideoConfig
+}
+
+// VideoConfig selects FFmpeg processing modes. Cut modes: copy (keyframe-snapped), accurate (re-encode
+// boundary GOPs only) or reencode (whole range). SourceCacheDir/SourceCacheMaxGB bound the worker's
+// local cache of downloaded source videos. SpriteInterval is the time between timeline thumbnails.
+// FillerWordsFile is an optional JSON object of language code to filler words, replacing the
+// built-in lists for those languages.
+type VideoConfig struct {
+	AutoCutMode      string
+	RenderCutMode    string
+	SourceCacheDir   string
+	SourceCacheMaxGB int
+	SpriteInterval   time.Duration
+	FillerWordsFile  string
 }
 
 // EmailConfig for transactional email (password reset, verification). Use SMTP (e.g. SendGrid SMTP relay).
@@ -188,6 +203,14 @@ func Load() (*Config, error) {
 			WebhookSecret: getEnv("STRIPE_WEBHOOK_SECRET", ""),
 			PriceIDPro:    getEnv("STRIPE_PRICE_ID_PRO", ""),
 		},
+		Video: VideoConfig{
+			AutoCutMode:   getEnv("AUTOCUT_CUT_MODE", "accurate"),
+			RenderCutMode: getEnv("RENDER_CUT_MODE", "accurate"),
+			SourceCacheDir:   getEnv("SOURCE_CACHE_DIR", ""),
+			SourceCacheMaxGB: getEnvInt("SOURCE_CACHE_MAX_GB", 20),
+			SpriteInterval:   getEnvDuration("SPRITE_INTERVAL", 2*time.Second),
+			FillerWordsFile:  getEnv("FILLER_WORDS_FILE", ""),
+		},
 		Email: EmailConfig{
 			From:              getEnv("EMAIL_FROM", "noreply@reelcut.local"),
 			FrontendBaseURL:   getEnv("FRONTEND_BASE_URL", "http://localhost:5173"),
@@ -15,10 +15,18 @@ type Clip struct {
 	EndTime          float64    `json:"end_time"`
 	DurationSeconds  *float64   `json:"duration_seconds,omitempty"`
 	AspectRatio      string     `json:"aspect_ratio"`
+	Resolution       string     `json:"resolution"`
+	FitMode          string     `json:"fit_mode"`
+	FitColor         *string    `json:"fit_color,omitempty"`
+	Reframe          Reframe    `json:"reframe,omitempty"`
+	Layout           *Layout    `json:"layout,omitempty"`
+	Segments         Segments   `json:"segments,omitempty"`
 	ViralityScore    *float64   `json:"virality_score,omitempty"`
 	Status           string     `json:"status"`
 	StoragePath      *string    `json:"storage_path,omitempty"`
+	Exports          Exports    `json:"exports,omitempty"`
 	ThumbnailURL     *string    `json:"thumbnail_url,omitempty"`
+	PreviewURL       *string    `json:"preview_url,omitempty"`
 	IsAISuggested    bool       `json:"is_ai_suggested"`
 	SuggestionReason *string    `json:"suggestion_reason,omitempty"`
 	ViewCount        int        `json:"view_count"`
@@ -42,6 +50,7 @@ type ClipStyle struct {
 	CaptionMaxWords       int        `json:"caption_max_words"`
 	BrandLogoURL          *string    `json:"brand_logo_url,omitempty"`
 	BrandLogoPosition     *string    `json:"brand_logo_position,omitempty"`
+	// BrandLogoScale multiplies the default logo width (15% of the frame) and must be in (0, 2].
 	BrandLogoScale        float64    `json:"brand_logo_scale"`
 	BrandWatermarkOpacity float64    `json:"brand_watermark_opacity"`
 	OverlayTemplate       *string    `json:"overlay_template,omitempty"`
@@ -49,6 +58,60 @@ type ClipStyle struct {
 	BackgroundMusicURL    *string    `json:"background_music_url,omitempty"`
 	BackgroundMusicVolume float64    `json:"background_music_volume"`
 	OriginalAudioVolume   float64    `json:"original_audio_volume"`
+	// RemoveSilences jump-cuts pauses of at least SilenceMinDuration seconds out of the render.
+	RemoveSilences        bool       `json:"remove_silences"`
+	SilenceMinDuration    float64    `json:"silence_min_duration"`
+	// RemoveFillers cuts filler words (per-language dictionary) and stutters, using word timings.
+	RemoveFillers         bool       `json:"remove_fillers"`
 	CreatedAt             time.Time `json:"created_at"`
 	UpdatedAt             time.Time `json:"updated_at"`
 }
+
+// ReframeKeyframe places the crop window of a clip Time seconds after its start. X and Y are the
+// window centre as fractions of the source frame (0.5, 0.5 is centred) and Zoom magnifies the window:
+// 1 is the largest window of the clip's aspect ratio, 2 is half as wide and high.
+type ReframeKeyframe struct {
+	Time float64 `json:"time"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Zoom float64 `json:"zoom"`
+}
+
+// Reframe is a clip's reframe keyframes, ordered by time. The render eases between them.
+type Reframe []ReframeKeyframe
+
+// Layout composes a clip from several regions of its source instead of one crop. Regions are source
+// rectangles as fractions of the frame, in panel order: top then bottom for "split", left then right for
+// "side_by_side", and background then inset for "pip". PiPPosition and PiPScale (inset width as a
+// fraction of the output width) only apply to "pip".
+type Layout struct {
+	Preset      string         `json:"preset"`
+	Regions     []LayoutRegion `json:"regions"`
+	PiPPosition string         `json:"pip_position,omitempty"`
+	PiPScale    float64        `json:"pip_scale,omitempty"`
+}
+
+// LayoutRegion is a rectangle of the source frame: X, Y is its top-left corner and W, H its size, all
+// fractions of the frame.
+type LayoutRegion struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+// ClipSegment is one source range of a multi-segment clip, from any of the user's videos.
+type ClipSegment struct {
+	VideoID   uuid.UUID `json:"video_id"`
+	StartTime float64   `json:"start_time"`
+	EndTime   float64   `json:"end_time"`
+}
+
+// Segments is the ordered source ranges of a multi-segment clip. A clip without segments is the single
+// range StartTime-EndTime of its video; a clip with segments keeps VideoID, StartTime and EndTime equal
+// to its first segment.
+type Segments []ClipSegment
+
+// Exports maps each platform export preset a clip was rendered for (see video.Presets) to the storage
+// key of that render.
+type Exports map[string]string
@@ -25,3 +25,27 @@ type ProcessingJob struct {
 	CreatedAt    time.Time       `json:"created_at"`
 	UpdatedAt    time.Time       `json:"updated_at"`
 }
+
+// JobFinished reports whether a job in status is done for good: completed, failed or cancelled.
+func JobFinished(status string) bool {
+	return status == "completed" || status == "failed" || status == "cancelled"
+}
+
+// BatchRenderMetadata is the Metadata of a "batch_render" parent job.
+type BatchRenderMetadata struct {
+	VideoID     string   `json:"video_id"`
+	ClipIDs     []string `json:"clip_ids"`
+	ChildJobIDs []string `json:"child_job_ids"`
+	// Presets are the export presets rendered for each clip; empty renders the clips' own outputs.
+	Presets []string `json:"presets,omitempty"`
+	// DownloadKey is the storage key of the ZIP export once the batch has finished.
+	DownloadKey string `json:"download_key,omitempty"`
+}
+
+// RenderJobMetadata is the Metadata of a "rendering" job started as part of a batch or for export
+// presets.
+type RenderJobMetadata struct {
+	ParentJobID string `json:"parent_job_id,omitempty"`
+	// Presets are the export presets to render instead of the clip's own output.
+	Presets []string `json:"presets,omitempty"`
+}
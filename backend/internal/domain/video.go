@@ -24,6 +24,9 @@ type Video struct {
 	Status            string          `json:"status"`
 	ErrorMessage      *string         `json:"error_message,omitempty"`
 	Metadata          json.RawMessage `json:"metadata,omitempty"`
+	HLSPath           *string         `json:"hls_path,omitempty"`
+	ProxyPath         *string         `json:"proxy_path,omitempty"`
+	RenditionsStatus  *string         `json:"renditions_status,omitempty"`
 	CreatedAt         time.Time       `json:"created_at"`
 	UpdatedAt         time.Time       `json:"updated_at"`
 	DeletedAt         *time.Time      `json:"-"`
@@ -1,25 +1,32 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"reelcut/internal/domain"
 	"reelcut/internal/middleware"
 	"reelcut/internal/service"
 	"reelcut/internal/utils"
+	"reelcut/internal/video"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type ClipHandler struct {
-	clipSvc  *service.ClipService
-	videoSvc *service.VideoService
+	clipSvc   *service.ClipService
+	videoSvc  *service.VideoService
+	batchSvc  *service.BatchRenderService
+	jwtSecret string
 }
 
-func NewClipHandler(clipSvc *service.ClipService, videoSvc *service.VideoService) *ClipHandler {
-	return &ClipHandler{clipSvc: clipSvc, videoSvc: videoSvc}
+func NewClipHandler(clipSvc *service.ClipService, videoSvc *service.VideoService, batchSvc *service.BatchRenderService, jwtSecret string) *ClipHandler {
+	return &ClipHandler{clipSvc: clipSvc, videoSvc: videoSvc, batchSvc: batchSvc, jwtSecret: jwtSecret}
 }
 
 // Create godoc
@@ -28,8 +35,10 @@ func NewClipHandler(clipSvc *service.ClipService, videoSvc *service.VideoService
 // @Accept		json
 // @Produce		json
 // @Security	BearerAuth
-// @Param		body	body		object	true	"video_id, name, start_time, end_time, aspect_ratio, ..."
+// @Param		body	body		object	true	"video_id, name, start_time, end_time, aspect_ratio (W:H), resolution (720p, 1080p, 1440p, 4k), ..."
 // @Success	201	{object}	object
+// @Failure	400	{object}	utils.ErrorResponse
+// @Failure	403	{object}	utils.ErrorResponse
 // @Failure	404	{object}	utils.ErrorResponse
 // @Router		/api/v1/clips [post]
 func (h *ClipHandler) Create(c *gin.Context) {
@@ -45,6 +54,7 @@ func (h *ClipHandler) Create(c *gin.Context) {
 		StartTime     float64  `json:"start_time" binding:"required"`
 		EndTime       float64  `json:"end_time" binding:"required"`
 		AspectRatio   string   `json:"aspect_ratio"`
+		Resolution    string   `json:"resolution"`
 		ViralityScore *float64 `json:"virality_score"`
 		FromSuggestion string  `json:"from_suggestion"`
 	}
@@ -52,8 +62,17 @@ func (h *ClipHandler) Create(c *gin.Context) {
 		utils.ValidationError(c, []utils.ErrorDetail{{Message: err.Error()}})
 		return
 	}
-	clip, err := h.clipSvc.Create(c.Request.Context(), uid, body.VideoID, body.Name, body.StartTime, body.EndTime, body.AspectRatio, body.ViralityScore, body.FromSuggestion != "")
+	clip, err := h.clipSvc.Create(c.Request.Context(), uid, body.VideoID, body.Name, body.StartTime, body.EndTime, body.AspectRatio, body.Resolution, body.ViralityScore, body.FromSuggestion != "")
 	if err != nil {
+		var ve *domain.ValidationError
+		if errors.As(err, &ve) {
+			utils.ValidationError(c, []utils.ErrorDetail{{Field: ve.Field, Message: ve.Message}})
+			return
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			utils.Forbidden(c, err.Error())
+			return
+		}
 		if err == domain.ErrNotFound {
 			utils.NotFound(c, "Video not found")
 			return
@@ -126,10 +145,12 @@ func (h *ClipHandler) GetByID(c *gin.Context) {
 
 // GetPlaybackURL godoc
 // @Summary		Get presigned URL for clip video playback (cut file)
+// @Description	With preset, returns the clip's render for that export preset (url is null until it exists). thumbnail_url and preview_url carry a token, so <img> can load them without Authorization.
 // @Tags			clips
 // @Produce		json
 // @Security	BearerAuth
 // @Param		id	path		string	true	"Clip ID"
+// @Param		preset	query		string	false	"Export preset (tiktok, reels, shorts, linkedin)"
 // @Success	200	{object}	object	"url"
 // @Failure	404	{object}	utils.ErrorResponse
 // @Router		/api/v1/clips/{id}/playback-url [get]
@@ -145,16 +166,87 @@ func (h *ClipHandler) GetPlaybackURL(c *gin.Context) {
 		utils.NotFound(c, "Clip not found")
 		return
 	}
-	if clip.StoragePath == nil || *clip.StoragePath == "" {
-		c.JSON(http.StatusOK, gin.H{"url": nil})
+	key := ""
+	if clip.StoragePath != nil {
+		key = *clip.StoragePath
+	}
+	if preset := c.Query("preset"); preset != "" {
+		key = clip.Exports[preset]
+	}
+	resp := gin.H{"url": nil}
+	if clip.ThumbnailURL != nil || clip.PreviewURL != nil {
+		token, err := utils.IssueClipThumbnailToken(clipID, userID, h.jwtSecret, thumbnailTokenExpiry)
+		if err != nil {
+			utils.Internal(c, "")
+			return
+		}
+		if clip.ThumbnailURL != nil {
+			resp["thumbnail_url"] = "/api/v1/clips/" + clipID + "/thumbnail?token=" + url.QueryEscape(token)
+		}
+		if clip.PreviewURL != nil {
+			resp["preview_url"] = "/api/v1/clips/" + clipID + "/preview?token=" + url.QueryEscape(token)
+		}
+	}
+	if key == "" {
+		c.JSON(http.StatusOK, resp)
 		return
 	}
-	url, err := h.videoSvc.GetPresignedDownloadURL(c.Request.Context(), *clip.StoragePath, 3600)
+	playbackURL, err := h.videoSvc.GetPresignedDownloadURL(c.Request.Context(), key, 3600)
 	if err != nil {
 		utils.Internal(c, "")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"url": url})
+	resp["url"] = playbackURL
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetThumbnail godoc
+// @Summary		Redirect to clip thumbnail URL
+// @Tags			clips
+// @Security	BearerAuth
+// @Param		id	path		string	true	"Clip ID"
+// @Success	302	"Redirect to thumbnail (JPEG)"
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/clips/{id}/thumbnail [get]
+func (h *ClipHandler) GetThumbnail(c *gin.Context) {
+	h.redirectToClipAsset(c, func(clip *domain.Clip) *string { return clip.ThumbnailURL }, "Clip or thumbnail not found")
+}
+
+// GetPreview godoc
+// @Summary		Redirect to clip animated preview URL
+// @Tags			clips
+// @Security	BearerAuth
+// @Param		id	path		string	true	"Clip ID"
+// @Success	302	"Redirect to looping preview (animated WebP)"
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/clips/{id}/preview [get]
+func (h *ClipHandler) GetPreview(c *gin.Context) {
+	h.redirectToClipAsset(c, func(clip *domain.Clip) *string { return clip.PreviewURL }, "Clip or preview not found")
+}
+
+// redirectToClipAsset redirects to a presigned URL for the storage key picked from the clip.
+func (h *ClipHandler) redirectToClipAsset(c *gin.Context, key func(*domain.Clip) *string, notFound string) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	clip, err := h.clipSvc.GetByID(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		utils.NotFound(c, notFound)
+		return
+	}
+	k := key(clip)
+	if k == nil || *k == "" {
+		utils.NotFound(c, notFound)
+		return
+	}
+	url, err := h.videoSvc.GetPresignedDownloadURL(c.Request.Context(), *k, 900) // 15 min
+	if err != nil {
+		utils.Internal(c, "")
+		return
+	}
+	c.Redirect(http.StatusFound, url)
 }
 
 // Update godoc
@@ -164,8 +256,10 @@ func (h *ClipHandler) GetPlaybackURL(c *gin.Context) {
 // @Produce		json
 // @Security	BearerAuth
 // @Param		id		path		string	true	"Clip ID"
-// @Param		body	body		object	true	"name, start_time, end_time, aspect_ratio"
+// @Param		body	body		object	true	"name, start_time, end_time, aspect_ratio (W:H), resolution (720p, 1080p, 1440p, 4k), fit_mode (crop, blur, color), fit_color (#RRGGBB), layout ({preset: split|side_by_side|pip, regions: [{x, y, w, h}, ...], pip_position, pip_scale} or null)"
 // @Success	200	{object}	object
+// @Failure	400	{object}	utils.ErrorResponse
+// @Failure	403	{object}	utils.ErrorResponse
 // @Failure	404	{object}	utils.ErrorResponse
 // @Router		/api/v1/clips/{id} [put]
 func (h *ClipHandler) Update(c *gin.Context) {
@@ -181,10 +275,14 @@ func (h *ClipHandler) Update(c *gin.Context) {
 		return
 	}
 	var body struct {
-		Name        *string  `json:"name"`
-		StartTime   *float64 `json:"start_time"`
-		EndTime     *float64 `json:"end_time"`
-		AspectRatio *string  `json:"aspect_ratio"`
+		Name        *string         `json:"name"`
+		StartTime   *float64        `json:"start_time"`
+		EndTime     *float64        `json:"end_time"`
+		AspectRatio *string         `json:"aspect_ratio"`
+		Resolution  *string         `json:"resolution"`
+		FitMode     *string         `json:"fit_mode"`
+		FitColor    *string         `json:"fit_color"`
+		Layout      json.RawMessage `json:"layout"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		utils.ValidationError(c, nil)
@@ -202,11 +300,40 @@ func (h *ClipHandler) Update(c *gin.Context) {
 	if body.AspectRatio != nil {
 		clip.AspectRatio = *body.AspectRatio
 	}
+	if body.Resolution != nil {
+		clip.Resolution = *body.Resolution
+	}
+	if body.FitMode != nil {
+		clip.FitMode = *body.FitMode
+	}
+	if body.FitColor != nil {
+		clip.FitColor = body.FitColor
+	}
+	if body.Layout != nil {
+		clip.Layout = nil
+		if string(body.Layout) != "null" {
+			var l domain.Layout
+			if err := json.Unmarshal(body.Layout, &l); err != nil {
+				utils.ValidationError(c, []utils.ErrorDetail{{Field: "layout", Message: "must be an object with preset and regions, or null"}})
+				return
+			}
+			clip.Layout = &l
+		}
+	}
 	if clip.EndTime > clip.StartTime {
 		dur := clip.EndTime - clip.StartTime
 		clip.DurationSeconds = &dur
 	}
 	if err := h.clipSvc.Update(c.Request.Context(), clip); err != nil {
+		var ve *domain.ValidationError
+		if errors.As(err, &ve) {
+			utils.ValidationError(c, []utils.ErrorDetail{{Field: ve.Field, Message: ve.Message}})
+			return
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			utils.Forbidden(c, err.Error())
+			return
+		}
 		utils.Internal(c, "")
 		return
 	}
@@ -236,10 +363,17 @@ func (h *ClipHandler) Delete(c *gin.Context) {
 
 // Render godoc
 // @Summary		Start clip render job
+// @Description	Returns 200 with cached=true and no credit charged when an identical render already exists. With presets, renders one output per export preset (one credit each unless cached) into the clip's exports instead of its own output.
 // @Tags			clips
+// @Accept		json
 // @Security	BearerAuth
 // @Param		id	path		string	true	"Clip ID"
+// @Param		body	body		object	false	"presets (optional): export preset names, see /clips/export-presets"
+// @Success	200	{object}	object
+// @Success	202	{object}	object
+// @Failure	400	{object}	utils.ErrorResponse
 // @Failure	401	{object}	utils.ErrorResponse
+// @Failure	403	{object}	utils.ErrorResponse
 // @Failure	501	{object}	object
 // @Router		/api/v1/clips/{id}/render [post]
 func (h *ClipHandler) Render(c *gin.Context) {
@@ -249,15 +383,41 @@ func (h *ClipHandler) Render(c *gin.Context) {
 		return
 	}
 	clipID := c.Param("id")
-	jobID, err := h.clipSvc.StartRender(c.Request.Context(), clipID, userID)
+	var body struct {
+		Presets []string `json:"presets"`
+	}
+	// The body is optional: an empty one renders the clip's own output.
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		utils.ValidationError(c, []utils.ErrorDetail{{Field: "body", Message: err.Error()}})
+		return
+	}
+	jobID, cached, err := h.clipSvc.StartRender(c.Request.Context(), clipID, userID, body.Presets)
 	if err != nil {
+		var ve *domain.ValidationError
+		if errors.As(err, &ve) {
+			utils.ValidationError(c, []utils.ErrorDetail{{Field: ve.Field, Message: ve.Message}})
+			return
+		}
 		if err == domain.ErrInsufficientCredits {
 			utils.Error(c, http.StatusPaymentRequired, "INSUFFICIENT_CREDITS", "Insufficient credits", nil)
 			return
 		}
+		if errors.Is(err, domain.ErrForbidden) {
+			utils.Forbidden(c, err.Error())
+			return
+		}
 		utils.NotFound(c, "Clip not found")
 		return
 	}
+	if cached {
+		c.JSON(http.StatusOK, gin.H{
+			"message":    "Render reused from cache",
+			"job_id":     jobID,
+			"status_url": "/api/v1/jobs/" + jobID,
+			"cached":     true,
+		})
+		return
+	}
 	c.JSON(http.StatusAccepted, gin.H{
 		"message":   "Render started",
 		"job_id":    jobID,
@@ -265,6 +425,84 @@ func (h *ClipHandler) Render(c *gin.Context) {
 	})
 }
 
+// BatchRender godoc
+// @Summary		Render several clips of a video under one job
+// @Description	Renders clip_ids (or every clip of the video when omitted) and packages the results as a ZIP with captions and a manifest. With presets, each clip is rendered once per export preset.
+// @Tags			clips
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		body	body		object	true	"video_id, clip_ids (optional), presets (optional)"
+// @Success	202	{object}	object
+// @Failure	400	{object}	utils.ErrorResponse
+// @Failure	402	{object}	utils.ErrorResponse
+// @Failure	403	{object}	utils.ErrorResponse
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/clips/batch-render [post]
+func (h *ClipHandler) BatchRender(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	var body struct {
+		VideoID string   `json:"video_id" binding:"required"`
+		ClipIDs []string `json:"clip_ids"`
+		Presets []string `json:"presets"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ValidationError(c, nil)
+		return
+	}
+	job, err := h.batchSvc.Start(c.Request.Context(), userID, body.VideoID, body.ClipIDs, body.Presets)
+	if err != nil {
+		var ve *domain.ValidationError
+		switch {
+		case errors.As(err, &ve):
+			utils.ValidationError(c, []utils.ErrorDetail{{Field: ve.Field, Message: ve.Message}})
+		case err == domain.ErrInsufficientCredits:
+			utils.Error(c, http.StatusPaymentRequired, "INSUFFICIENT_CREDITS", "Insufficient credits", nil)
+		case errors.Is(err, domain.ErrForbidden):
+			utils.Forbidden(c, err.Error())
+		case err == domain.ErrNotFound:
+			utils.NotFound(c, "Video or clip not found")
+		default:
+			utils.Internal(c, "")
+		}
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":      "Batch render started",
+		"job_id":       job.ID.String(),
+		"status_url":   "/api/v1/jobs/" + job.ID.String(),
+		"download_url": "/api/v1/clips/batch-render/" + job.ID.String() + "/download",
+		"job":          job,
+	})
+}
+
+// BatchDownload godoc
+// @Summary		Get presigned URL for a finished batch render ZIP
+// @Tags			clips
+// @Produce		json
+// @Security	BearerAuth
+// @Param		jobId	path		string	true	"Batch job ID"
+// @Success	200	{object}	object	"url"
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/clips/batch-render/{jobId}/download [get]
+func (h *ClipHandler) BatchDownload(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	url, err := h.batchSvc.DownloadURL(c.Request.Context(), c.Param("jobId"), userID)
+	if err != nil {
+		utils.NotFound(c, "Batch export not found or not ready")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": url})
+}
+
 // GetRenderStatus godoc
 // @Summary		Get clip render job status
 // @Tags			clips
@@ -281,6 +519,44 @@ func (h *ClipHandler) GetRenderStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"render_job": gin.H{"status": "pending"}})
 }
 
+// ListExportPresets godoc
+// @Summary		List platform export presets
+// @Description	Each preset sets the output aspect ratio (empty keeps the clip's), resolution tier, frame rate, maximum duration (longer clips are rejected), bitrates, loudness target and the caption safe area (fractions of the frame edges).
+// @Tags			clips
+// @Produce		json
+// @Security	BearerAuth
+// @Success	200	{object}	object	"presets"
+// @Router		/api/v1/clips/export-presets [get]
+func (h *ClipHandler) ListExportPresets(c *gin.Context) {
+	if middleware.GetUserID(c) == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	presets := make([]gin.H, 0, len(video.Presets))
+	for _, name := range video.PresetNames() {
+		p := video.Presets[name]
+		presets = append(presets, gin.H{
+			"name":               p.Name,
+			"label":              p.Label,
+			"aspect_ratio":       p.AspectRatio,
+			"resolution":         p.Resolution,
+			"fps":                p.FPS,
+			"max_duration":       p.MaxDuration,
+			"video_bitrate_kbps": p.VideoBitrate,
+			"audio_bitrate_kbps": p.AudioBitrate,
+			"loudness_lufs":      p.Loudness,
+			"true_peak_db":       p.TruePeak,
+			"safe_area": gin.H{
+				"top":    p.SafeArea.Top,
+				"bottom": p.SafeArea.Bottom,
+				"left":   p.SafeArea.Left,
+				"right":  p.SafeArea.Right,
+			},
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"presets": presets})
+}
+
 // Download godoc
 // @Summary		Download rendered clip
 // @Tags			clips
@@ -350,7 +626,7 @@ func (h *ClipHandler) GetStyle(c *gin.Context) {
 // @Produce		json
 // @Security	BearerAuth
 // @Param		id		path		string	true	"Clip ID"
-// @Param		body	body		domain.ClipStyle	true	"Style config"
+// @Param		body	body		service.StyleUpdate	true	"Style fields to change; brand_logo_url and background_music_url take keys from /clips/{id}/style/assets"
 // @Success	200	{object}	object
 // @Failure	404	{object}	utils.ErrorResponse
 // @Router		/api/v1/clips/{id}/style [put]
@@ -361,12 +637,17 @@ func (h *ClipHandler) UpdateStyle(c *gin.Context) {
 		return
 	}
 	clipID := c.Param("id")
-	var body domain.ClipStyle
+	var body service.StyleUpdate
 	if err := c.ShouldBindJSON(&body); err != nil {
 		utils.ValidationError(c, nil)
 		return
 	}
 	if err := h.clipSvc.UpdateStyle(c.Request.Context(), clipID, userID, &body); err != nil {
+		var ve *domain.ValidationError
+		if errors.As(err, &ve) {
+			utils.ValidationError(c, []utils.ErrorDetail{{Field: ve.Field, Message: ve.Message}})
+			return
+		}
 		utils.NotFound(c, "Clip not found")
 		return
 	}
@@ -374,6 +655,49 @@ func (h *ClipHandler) UpdateStyle(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"style": style})
 }
 
+// StyleAssetUploadURL godoc
+// @Summary		Get presigned URL for a style asset upload
+// @Description	Upload the file with PUT and the same Content-Type, then set the returned key as the style's brand_logo_url (logo) or background_music_url (music).
+// @Tags			clips
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		id		path		string	true	"Clip ID"
+// @Param		body	body		object	true	"kind (logo or music), content_type"
+// @Success	200	{object}	object	"upload_url, key"
+// @Failure	400	{object}	utils.ErrorResponse
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/clips/{id}/style/assets [post]
+func (h *ClipHandler) StyleAssetUploadURL(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	var body struct {
+		Kind        string `json:"kind" binding:"required"`
+		ContentType string `json:"content_type" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ValidationError(c, []utils.ErrorDetail{{Message: err.Error()}})
+		return
+	}
+	uploadURL, key, err := h.clipSvc.StyleAssetUploadURL(c.Request.Context(), c.Param("id"), userID, body.Kind, body.ContentType)
+	if err != nil {
+		var ve *domain.ValidationError
+		switch {
+		case errors.As(err, &ve):
+			utils.ValidationError(c, []utils.ErrorDetail{{Field: ve.Field, Message: ve.Message}})
+		case errors.Is(err, domain.ErrNotFound):
+			utils.NotFound(c, "Clip not found")
+		default:
+			utils.Internal(c, "")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"upload_url": uploadURL, "method": "PUT", "key": key})
+}
+
 // GetCaptionsSRT godoc
 // @Summary		Get clip captions as SRT
 // @Tags			clips
@@ -424,6 +748,174 @@ func (h *ClipHandler) GetCaptionsVTT(c *gin.Context) {
 	c.Data(http.StatusOK, "text/vtt; charset=utf-8", []byte(vtt))
 }
 
+// GetEditList godoc
+// @Summary		Preview the jump cuts a render of the clip will make
+// @Description	Ranges (seconds from the clip start) removed by the style's silence and filler-word options, with the
+// @Description	reason (silence, filler, stutter) and the words removed. Re-fetch after changing the style or transcript.
+// @Tags			clips
+// @Produce		json
+// @Security	BearerAuth
+// @Param		id	path		string	true	"Clip ID"
+// @Success	200	{object}	service.EditList
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/clips/{id}/edit-list [get]
+func (h *ClipHandler) GetEditList(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	edits, err := h.clipSvc.EditList(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			utils.NotFound(c, "Clip not found")
+			return
+		}
+		utils.Internal(c, "")
+		return
+	}
+	c.JSON(http.StatusOK, edits)
+}
+
+// GetReframe godoc
+// @Summary		Get the clip's reframe keyframes
+// @Description	Keyframes steer the crop window: time (seconds from the clip start), x and y (window centre as fractions
+// @Description	of the source frame) and zoom (1 to 4). The render eases between them. Without keyframes, renders follow
+// @Description	the active speaker using auto_keyframes, derived from the video analysis' face tracks.
+// @Tags			clips
+// @Produce		json
+// @Security	BearerAuth
+// @Param		id	path		string	true	"Clip ID"
+// @Success	200	{object}	object
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/clips/{id}/reframe [get]
+func (h *ClipHandler) GetReframe(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	keys, auto, err := h.clipSvc.GetReframe(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		utils.NotFound(c, "Clip not found")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"keyframes": keys, "auto_keyframes": auto})
+}
+
+// UpdateReframe godoc
+// @Summary		Replace the clip's reframe keyframes
+// @Description	An empty list restores the centre crop. Keyframes apply when the clip's fit mode is crop.
+// @Tags			clips
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		id		path		string	true	"Clip ID"
+// @Param		body	body		object	true	"keyframes: [{time, x, y, zoom}]"
+// @Success	200	{object}	object
+// @Failure	400	{object}	utils.ErrorResponse
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/clips/{id}/reframe [put]
+func (h *ClipHandler) UpdateReframe(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	var body struct {
+		Keyframes domain.Reframe `json:"keyframes"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ValidationError(c, []utils.ErrorDetail{{Message: err.Error()}})
+		return
+	}
+	keys, err := h.clipSvc.UpdateReframe(c.Request.Context(), c.Param("id"), userID, body.Keyframes)
+	if err != nil {
+		var ve *domain.ValidationError
+		if errors.As(err, &ve) {
+			utils.ValidationError(c, []utils.ErrorDetail{{Field: ve.Field, Message: ve.Message}})
+			return
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			utils.NotFound(c, "Clip not found")
+			return
+		}
+		utils.Internal(c, "")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"keyframes": keys})
+}
+
+// GetSegments godoc
+// @Summary		Get the clip's source segments
+// @Description	Segments are the source ranges the clip is cut from, in order: video_id, start_time and end_time in
+// @Description	seconds of that video. A single-range clip has one.
+// @Tags			clips
+// @Produce		json
+// @Security	BearerAuth
+// @Param		id	path		string	true	"Clip ID"
+// @Success	200	{object}	object
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/clips/{id}/segments [get]
+func (h *ClipHandler) GetSegments(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	segs, err := h.clipSvc.GetSegments(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		utils.NotFound(c, "Clip not found")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"segments": segs})
+}
+
+// UpdateSegments godoc
+// @Summary		Replace the clip's source segments
+// @Description	Segments may come from any of the user's videos and are joined in order, with the style's
+// @Description	transition_effect (an FFmpeg xfade transition) between them or hard cuts when it is unset. Captions are
+// @Description	stitched across the segments. A single segment makes the clip a plain single-range clip.
+// @Tags			clips
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		id		path		string	true	"Clip ID"
+// @Param		body	body		object	true	"segments: [{video_id, start_time, end_time}]"
+// @Success	200	{object}	object
+// @Failure	400	{object}	utils.ErrorResponse
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/clips/{id}/segments [put]
+func (h *ClipHandler) UpdateSegments(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	var body struct {
+		Segments domain.Segments `json:"segments"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ValidationError(c, []utils.ErrorDetail{{Message: err.Error()}})
+		return
+	}
+	clip, err := h.clipSvc.UpdateSegments(c.Request.Context(), c.Param("id"), userID, body.Segments)
+	if err != nil {
+		var ve *domain.ValidationError
+		if errors.As(err, &ve) {
+			utils.ValidationError(c, []utils.ErrorDetail{{Field: ve.Field, Message: ve.Message}})
+			return
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			utils.NotFound(c, "Clip not found")
+			return
+		}
+		utils.Internal(c, "")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"clip": clip})
+}
+
 // ApplyTemplate godoc
 // @Summary		Apply template to clip style
 // @Tags			clips
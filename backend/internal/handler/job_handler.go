@@ -5,18 +5,22 @@ import (
 	"strconv"
 
 	"reelcut/internal/middleware"
+	"reelcut/internal/queue"
 	"reelcut/internal/repository"
+	"reelcut/internal/service"
 	"reelcut/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 type JobHandler struct {
-	jobRepo repository.ProcessingJobRepository
+	jobRepo  repository.ProcessingJobRepository
+	queue    *queue.QueueClient
+	batchSvc *service.BatchRenderService
 }
 
-func NewJobHandler(jobRepo repository.ProcessingJobRepository) *JobHandler {
-	return &JobHandler{jobRepo: jobRepo}
+func NewJobHandler(jobRepo repository.ProcessingJobRepository, queueClient *queue.QueueClient, batchSvc *service.BatchRenderService) *JobHandler {
+	return &JobHandler{jobRepo: jobRepo, queue: queueClient, batchSvc: batchSvc}
 }
 
 // List godoc
@@ -73,13 +77,19 @@ func (h *JobHandler) GetByID(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"job": j})
 }
 
+// cancellableJobTypes are the jobs whose work stops when cancelled: renders (their FFmpeg is killed or
+// skipped when dequeued) and batch renders, which cancel their child renders.
+var cancellableJobTypes = map[string]bool{"rendering": true, "batch_render": true}
+
 // Cancel godoc
 // @Summary		Cancel a job
+// @Description	Only rendering and batch_render jobs can be cancelled; upload processing (metadata, transcode, waveform, sprites) and auto-cut jobs run to completion.
 // @Tags			jobs
 // @Produce		json
 // @Security	BearerAuth
 // @Param		id	path		string	true	"Job ID"
 // @Success	200	{object}	object
+// @Failure	400	{object}	utils.ErrorResponse
 // @Failure	404	{object}	utils.ErrorResponse
 // @Router		/api/v1/jobs/{id}/cancel [post]
 func (h *JobHandler) Cancel(c *gin.Context) {
@@ -93,10 +103,30 @@ func (h *JobHandler) Cancel(c *gin.Context) {
 		utils.NotFound(c, "Job not found")
 		return
 	}
+	if j.Status != "pending" && j.Status != "processing" {
+		c.JSON(http.StatusOK, gin.H{"message": "Job already finished"})
+		return
+	}
+	if !cancellableJobTypes[j.JobType] {
+		utils.ValidationError(c, []utils.ErrorDetail{{Field: "id", Message: j.JobType + " jobs cannot be cancelled"}})
+		return
+	}
 	j.Status = "cancelled"
 	if err := h.jobRepo.Update(c.Request.Context(), j); err != nil {
 		utils.Internal(c, "")
 		return
 	}
+	// Stops the worker's FFmpeg if the task is running; queued tasks are skipped when dequeued.
+	if err := h.queue.CancelJob(j.ID); err != nil {
+		utils.Internal(c, "")
+		return
+	}
+	// A batch runs in its child renders, which are cancelled with it.
+	if j.JobType == "batch_render" {
+		if err := h.batchSvc.CancelChildren(c.Request.Context(), j.ID.String()); err != nil {
+			utils.Internal(c, "")
+			return
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Job cancelled"})
 }
@@ -2,9 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"reelcut/internal/domain"
 	"reelcut/internal/middleware"
 	"reelcut/internal/service"
 	"reelcut/internal/utils"
@@ -74,6 +76,7 @@ func (h *TemplateHandler) GetPublicTemplates(c *gin.Context) {
 // @Security	BearerAuth
 // @Param		body	body		object	true	"name, category, is_public, style_config"
 // @Success	201	{object}	object
+// @Failure	400	{object}	utils.ErrorResponse
 // @Router		/api/v1/templates [post]
 func (h *TemplateHandler) Create(c *gin.Context) {
 	userID := middleware.GetUserID(c)
@@ -93,6 +96,11 @@ func (h *TemplateHandler) Create(c *gin.Context) {
 		return
 	}
 	t, err := h.templateSvc.Create(c.Request.Context(), &uid, body.Name, body.Category, body.IsPublic, body.StyleConfig)
+	var ve *domain.ValidationError
+	if errors.As(err, &ve) {
+		utils.ValidationError(c, []utils.ErrorDetail{{Field: ve.Field, Message: ve.Message}})
+		return
+	}
 	if err != nil {
 		utils.Internal(c, "")
 		return
@@ -131,6 +139,7 @@ func (h *TemplateHandler) GetByID(c *gin.Context) {
 // @Param		id		path		string	true	"Template ID"
 // @Param		body	body		object	true	"name, category, is_public, style_config"
 // @Success	200	{object}	object
+// @Failure	400	{object}	utils.ErrorResponse
 // @Failure	404	{object}	utils.ErrorResponse
 // @Router		/api/v1/templates/{id} [put]
 func (h *TemplateHandler) Update(c *gin.Context) {
@@ -166,6 +175,11 @@ func (h *TemplateHandler) Update(c *gin.Context) {
 		t.StyleConfig = body.StyleConfig
 	}
 	if err := h.templateSvc.Update(c.Request.Context(), t); err != nil {
+		var ve *domain.ValidationError
+		if errors.As(err, &ve) {
+			utils.ValidationError(c, []utils.ErrorDetail{{Field: ve.Field, Message: ve.Message}})
+			return
+		}
 		utils.Internal(c, "")
 		return
 	}
@@ -14,6 +14,7 @@ import (
 	"reelcut/internal/queue"
 	"reelcut/internal/service"
 	"reelcut/internal/utils"
+	"reelcut/internal/video"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -21,6 +22,10 @@ import (
 
 const thumbnailTokenExpiry = 5 * time.Minute
 
+// playlistTokenExpiry bounds the token embedded in hls_url; it only has to last until the player has
+// fetched the master and variant playlists.
+const playlistTokenExpiry = time.Hour
+
 type VideoHandler struct {
 	videoSvc         *service.VideoService
 	transcriptionSvc  *service.TranscriptionService
@@ -469,11 +474,14 @@ func (h *VideoHandler) GetMetadata(c *gin.Context) {
 
 // GetPlaybackURL godoc
 // @Summary		Get presigned URL for video playback
+// @Description	url is the editing proxy once renditions are ready, else the original upload. hls_url (adaptive
+// @Description	360p/720p/1080p ladder, carrying its own access token) is set once renditions are ready.
+// @Description	thumbnails_url is the WebVTT thumbnail track for scrubbing (404 until its sprite sheets are generated).
 // @Tags			videos
 // @Produce		json
 // @Security	BearerAuth
 // @Param		id	path		string	true	"Video ID"
-// @Success	200	{object}	object	"url"
+// @Success	200	{object}	object	"url, original_url, hls_url, thumbnails_url, renditions_status"
 // @Failure	404	{object}	utils.ErrorResponse
 // @Router		/api/v1/videos/{id}/playback-url [get]
 func (h *VideoHandler) GetPlaybackURL(c *gin.Context) {
@@ -497,12 +505,174 @@ func (h *VideoHandler) GetPlaybackURL(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"url": nil, "status": status})
 		return
 	}
-	url, err := h.videoSvc.GetPresignedDownloadURL(c.Request.Context(), v.StoragePath, 3600) // 1h
+	originalURL, err := h.videoSvc.GetPresignedDownloadURL(c.Request.Context(), v.StoragePath, 3600) // 1h
+	if err != nil {
+		utils.Internal(c, "")
+		return
+	}
+	resp := gin.H{"url": originalURL, "original_url": originalURL, "renditions_status": v.RenditionsStatus}
+	if v.ProxyPath != nil && *v.ProxyPath != "" {
+		if proxyURL, err := h.videoSvc.GetPresignedDownloadURL(c.Request.Context(), *v.ProxyPath, 3600); err == nil {
+			resp["url"] = proxyURL
+		}
+	}
+	token, err := utils.IssueThumbnailToken(videoID, userID, h.jwtSecret, playlistTokenExpiry)
+	if err != nil {
+		utils.Internal(c, "")
+		return
+	}
+	if v.HLSPath != nil && *v.HLSPath != "" {
+		resp["hls_url"] = "/api/v1/videos/" + videoID + "/hls/master.m3u8?token=" + url.QueryEscape(token)
+	}
+	resp["thumbnails_url"] = "/api/v1/videos/" + videoID + "/thumbnails.vtt?token=" + url.QueryEscape(token)
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetHLSPlaylist godoc
+// @Summary		Get an HLS playlist of the video's playback ladder
+// @Description	Serves master.m3u8 or <rendition>/index.m3u8 with segment URIs rewritten to presigned storage URLs.
+// @Tags			videos
+// @Produce		application/vnd.apple.mpegurl
+// @Security	BearerAuth
+// @Param		id		path		string	true	"Video ID"
+// @Param		file	path		string	true	"Playlist path"
+// @Param		token	query		string	false	"Playback token from playback-url"
+// @Success	200	{string}	string
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/videos/{id}/hls/{file} [get]
+func (h *VideoHandler) GetHLSPlaylist(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	v, err := h.videoSvc.GetByID(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		utils.NotFound(c, "Video not found")
+		return
+	}
+	query := ""
+	if token := c.Query("token"); token != "" {
+		query = "token=" + url.QueryEscape(token)
+	}
+	playlist, err := h.videoSvc.HLSPlaylist(c.Request.Context(), v, c.Param("file"), query)
 	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			utils.NotFound(c, "Playlist not found")
+			return
+		}
 		utils.Internal(c, "")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"url": url})
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/vnd.apple.mpegurl", []byte(playlist))
+}
+
+// GetThumbnailTrack godoc
+// @Summary		Get the WebVTT thumbnail track of the video for timeline scrubbing
+// @Description	Each cue maps a time range to a tile of a sprite sheet: a presigned image URL with a #xywh= fragment.
+// @Tags			videos
+// @Produce		text/vtt
+// @Security	BearerAuth
+// @Param		id		path		string	true	"Video ID"
+// @Param		token	query		string	false	"Playback token from playback-url"
+// @Success	200	{string}	string
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/videos/{id}/thumbnails.vtt [get]
+func (h *VideoHandler) GetThumbnailTrack(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	v, err := h.videoSvc.GetByID(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		utils.NotFound(c, "Video not found")
+		return
+	}
+	track, err := h.videoSvc.ThumbnailTrack(c.Request.Context(), v)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			utils.NotFound(c, "Thumbnail track not ready")
+			return
+		}
+		utils.Internal(c, "")
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "text/vtt; charset=utf-8", []byte(track))
+}
+
+// GetWaveform godoc
+// @Summary		Get audio waveform peaks of the video for the editor timeline
+// @Description	Min/max peak pairs (8-bit) in the audiowaveform JSON layout. zoom indexes zoom_levels (samples per
+// @Description	peak), coarsest first. format=binary returns the audiowaveform .dat file instead.
+// @Tags			videos
+// @Produce		json
+// @Security	BearerAuth
+// @Param		id		path		string	true	"Video ID"
+// @Param		zoom	query		int		false	"Zoom level index (default 0)"
+// @Param		format	query		string	false	"json (default) or binary"
+// @Success	200	{object}	object
+// @Failure	400	{object}	utils.ErrorResponse
+// @Failure	404	{object}	utils.ErrorResponse
+// @Router		/api/v1/videos/{id}/waveform [get]
+func (h *VideoHandler) GetWaveform(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		utils.Unauthorized(c, "")
+		return
+	}
+	v, err := h.videoSvc.GetByID(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		utils.NotFound(c, "Video not found")
+		return
+	}
+	zoom, err := strconv.Atoi(c.DefaultQuery("zoom", "0"))
+	if err != nil {
+		utils.ValidationError(c, []utils.ErrorDetail{{Field: "zoom", Message: "must be an integer"}})
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "binary" {
+		utils.ValidationError(c, []utils.ErrorDetail{{Field: "format", Message: "must be json or binary"}})
+		return
+	}
+	wf, err := h.videoSvc.Waveform(c.Request.Context(), v, zoom)
+	if err != nil {
+		var ve *domain.ValidationError
+		if errors.As(err, &ve) {
+			utils.ValidationError(c, []utils.ErrorDetail{{Field: ve.Field, Message: ve.Message}})
+			return
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			utils.NotFound(c, "Waveform not ready")
+			return
+		}
+		utils.Internal(c, "")
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=3600")
+	if format == "binary" {
+		data, err := wf.MarshalBinary()
+		if err != nil {
+			utils.Internal(c, "")
+			return
+		}
+		c.Data(http.StatusOK, "application/octet-stream", data)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"version":           2,
+		"channels":          1,
+		"sample_rate":       wf.SampleRate,
+		"samples_per_pixel": wf.SamplesPerPixel,
+		"bits":              8,
+		"length":            wf.Length(),
+		"data":              wf.Data,
+		"zoom":              zoom,
+		"zoom_levels":       video.WaveformZoomLevels,
+	})
 }
 
 // GetThumbnail godoc
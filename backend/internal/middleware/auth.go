@@ -98,16 +98,27 @@ func GetUser(c *gin.Context) *domain.User {
 // AuthenticateThumbnailOrBearer validates either a thumbnail token (query "token") or Bearer and sets user ID.
 // Used for GET /videos/:id/thumbnail so <img src="...?token=..."> works without sending Authorization.
 func (m *AuthMiddleware) AuthenticateThumbnailOrBearer() gin.HandlerFunc {
+	return m.thumbnailOrBearer(func(claims *utils.ThumbnailClaims) string { return claims.VideoID })
+}
+
+// AuthenticateClipThumbnailOrBearer is AuthenticateThumbnailOrBearer for clip routes: the token must have
+// been issued for the clip in :id (see utils.IssueClipThumbnailToken).
+func (m *AuthMiddleware) AuthenticateClipThumbnailOrBearer() gin.HandlerFunc {
+	return m.thumbnailOrBearer(func(claims *utils.ThumbnailClaims) string { return claims.ClipID })
+}
+
+// thumbnailOrBearer accepts a thumbnail token whose scope (the video or clip it was issued for) is :id.
+func (m *AuthMiddleware) thumbnailOrBearer(scope func(*utils.ThumbnailClaims) string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		videoID := c.Param("id")
-		if videoID == "" {
-			utils.Unauthorized(c, "Missing video id")
+		id := c.Param("id")
+		if id == "" {
+			utils.Unauthorized(c, "Missing id")
 			c.Abort()
 			return
 		}
 		if tokenStr := c.Query("token"); tokenStr != "" {
 			claims, err := utils.ParseThumbnailToken(tokenStr, m.jwtSecret)
-			if err != nil || claims.VideoID != videoID {
+			if err != nil || scope(claims) != id {
 				utils.Unauthorized(c, "Invalid or expired thumbnail token")
 				c.Abort()
 				return
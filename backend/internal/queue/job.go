@@ -2,7 +2,9 @@ package queue
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
@@ -16,6 +18,10 @@ const (
 	TypeAnalysis        = "analysis"
 	TypeRender          = "render"
 	TypeAutoCut         = "auto_cut"
+	TypeBatchExport     = "batch_export"
+	TypeVideoTranscode  = "video:transcode"
+	TypeVideoWaveform   = "video:waveform"
+	TypeVideoSprites    = "video:sprites"
 )
 
 type VideoMetadataPayload struct {
@@ -49,6 +55,22 @@ type AutoCutPayload struct {
 	VideoID string `json:"video_id"`
 }
 
+type BatchExportPayload struct {
+	JobID string `json:"job_id"`
+}
+
+type VideoTranscodePayload struct {
+	VideoID string `json:"video_id"`
+}
+
+type VideoWaveformPayload struct {
+	VideoID string `json:"video_id"`
+}
+
+type VideoSpritesPayload struct {
+	VideoID string `json:"video_id"`
+}
+
 func NewVideoMetadataTask(videoID uuid.UUID) (*asynq.Task, error) {
 	payload, err := json.Marshal(VideoMetadataPayload{VideoID: videoID.String()})
 	if err != nil {
@@ -147,8 +169,68 @@ func ParseAutoCutPayload(b []byte) (AutoCutPayload, error) {
 	return p, err
 }
 
+func NewBatchExportTask(jobID uuid.UUID) (*asynq.Task, error) {
+	payload, err := json.Marshal(BatchExportPayload{JobID: jobID.String()})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeBatchExport, payload), nil
+}
+
+func ParseBatchExportPayload(b []byte) (BatchExportPayload, error) {
+	var p BatchExportPayload
+	err := json.Unmarshal(b, &p)
+	return p, err
+}
+
+func NewVideoTranscodeTask(videoID uuid.UUID) (*asynq.Task, error) {
+	payload, err := json.Marshal(VideoTranscodePayload{VideoID: videoID.String()})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeVideoTranscode, payload), nil
+}
+
+func ParseVideoTranscodePayload(b []byte) (VideoTranscodePayload, error) {
+	var p VideoTranscodePayload
+	err := json.Unmarshal(b, &p)
+	return p, err
+}
+
+func NewVideoWaveformTask(videoID uuid.UUID) (*asynq.Task, error) {
+	payload, err := json.Marshal(VideoWaveformPayload{VideoID: videoID.String()})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeVideoWaveform, payload), nil
+}
+
+func ParseVideoWaveformPayload(b []byte) (VideoWaveformPayload, error) {
+	var p VideoWaveformPayload
+	err := json.Unmarshal(b, &p)
+	return p, err
+}
+
+func NewVideoSpritesTask(videoID uuid.UUID) (*asynq.Task, error) {
+	payload, err := json.Marshal(VideoSpritesPayload{VideoID: videoID.String()})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeVideoSprites, payload), nil
+}
+
+func ParseVideoSpritesPayload(b []byte) (VideoSpritesPayload, error) {
+	var p VideoSpritesPayload
+	err := json.Unmarshal(b, &p)
+	return p, err
+}
+
+// defaultQueue is the asynq queue every task is enqueued on.
+const defaultQueue = "default"
+
 type QueueClient struct {
-	client *asynq.Client
+	client    *asynq.Client
+	inspector *asynq.Inspector
 }
 
 func NewQueueClient(redisURL string) (*QueueClient, error) {
@@ -157,7 +239,7 @@ func NewQueueClient(redisURL string) (*QueueClient, error) {
 		return nil, fmt.Errorf("parse redis url: %w", err)
 	}
 	client := asynq.NewClient(opt)
-	return &QueueClient{client: client}, nil
+	return &QueueClient{client: client, inspector: asynq.NewInspector(opt)}, nil
 }
 
 func (q *QueueClient) EnqueueVideoMetadata(videoID uuid.UUID) error {
@@ -210,7 +292,8 @@ func (q *QueueClient) EnqueueRender(clipID, jobID uuid.UUID) error {
 	if err != nil {
 		return err
 	}
-	_, err = q.client.Enqueue(task)
+	// The job ID doubles as the task ID so the job can be cancelled through CancelJob.
+	_, err = q.client.Enqueue(task, asynq.TaskID(jobID.String()))
 	return err
 }
 
@@ -223,6 +306,84 @@ func (q *QueueClient) EnqueueAutoCut(videoID uuid.UUID) error {
 	return err
 }
 
+// EnqueueBatchExport enqueues the ZIP export of a finished batch. It is idempotent: the last child
+// renders may finish concurrently and each try to enqueue it.
+func (q *QueueClient) EnqueueBatchExport(jobID uuid.UUID) error {
+	task, err := NewBatchExportTask(jobID)
+	if err != nil {
+		return err
+	}
+	return q.enqueueOnce(task, "export:"+jobID.String())
+}
+
+// EnqueueVideoTranscode enqueues the playback renditions (HLS ladder and editing proxy) of an upload.
+// Long sources take a while to transcode, so the task gets a longer timeout than asynq's default.
+func (q *QueueClient) EnqueueVideoTranscode(videoID uuid.UUID) error {
+	task, err := NewVideoTranscodeTask(videoID)
+	if err != nil {
+		return err
+	}
+	return q.enqueueOnce(task, "transcode:"+videoID.String(), asynq.Timeout(6*time.Hour))
+}
+
+// EnqueueVideoWaveform enqueues the computation of an upload's audio waveform peaks for the editor
+// timeline.
+func (q *QueueClient) EnqueueVideoWaveform(videoID uuid.UUID) error {
+	task, err := NewVideoWaveformTask(videoID)
+	if err != nil {
+		return err
+	}
+	return q.enqueueOnce(task, "waveform:"+videoID.String())
+}
+
+// EnqueueVideoSprites enqueues the timeline thumbnail sprite sheets of an upload. Decoding a long
+// source end to end takes a while, so the task gets a longer timeout than asynq's default.
+func (q *QueueClient) EnqueueVideoSprites(videoID uuid.UUID) error {
+	task, err := NewVideoSpritesTask(videoID)
+	if err != nil {
+		return err
+	}
+	return q.enqueueOnce(task, "sprites:"+videoID.String(), asynq.Timeout(2*time.Hour))
+}
+
+// enqueueOnce enqueues task under id unless a task with that id is still waiting or running. asynq keeps
+// a task that ran out of retries in the archive under its id, which would block the id for good: an
+// archived (or retained completed) task is deleted so the new one can take its place.
+func (q *QueueClient) enqueueOnce(task *asynq.Task, id string, opts ...asynq.Option) error {
+	opts = append(opts, asynq.TaskID(id))
+	_, err := q.client.Enqueue(task, opts...)
+	if !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return err
+	}
+	info, err := q.inspector.GetTaskInfo(defaultQueue, id)
+	switch {
+	case errors.Is(err, asynq.ErrTaskNotFound):
+		// It finished between the two calls.
+	case err != nil:
+		return fmt.Errorf("inspect task %s: %w", id, err)
+	case info.State == asynq.TaskStateArchived || info.State == asynq.TaskStateCompleted:
+		if err := q.inspector.DeleteTask(defaultQueue, id); err != nil && !errors.Is(err, asynq.ErrTaskNotFound) {
+			return fmt.Errorf("delete task %s: %w", id, err)
+		}
+	default:
+		return nil
+	}
+	_, err = q.client.Enqueue(task, opts...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		// Another caller re-enqueued it first.
+		return nil
+	}
+	return err
+}
+
+// CancelJob cancels the context of the task running under jobID on whichever worker holds it (asynq
+// broadcasts the cancellation over Redis pub/sub), which kills its FFmpeg processes. Queued tasks are
+// left in place: workers see the cancelled job when they pick them up and clean up there.
+func (q *QueueClient) CancelJob(jobID uuid.UUID) error {
+	return q.inspector.CancelProcessing(jobID.String())
+}
+
 func (q *QueueClient) Close() error {
+	q.inspector.Close()
 	return q.client.Close()
 }
@@ -18,17 +18,17 @@ func NewClipRepository(pool *pgxpool.Pool) ClipRepository {
 }
 
 func (r *clipRepository) Create(ctx context.Context, c *domain.Clip) error {
-	query := `INSERT INTO clips (id, video_id, user_id, name, start_time, end_time, duration_seconds, aspect_ratio, virality_score, status, storage_path, thumbnail_url, is_ai_suggested, suggestion_reason, view_count, download_count)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
-	_, err := r.pool.Exec(ctx, query, c.ID, c.VideoID, c.UserID, c.Name, c.StartTime, c.EndTime, c.DurationSeconds, c.AspectRatio, c.ViralityScore, c.Status, c.StoragePath, c.ThumbnailURL, c.IsAISuggested, c.SuggestionReason, c.ViewCount, c.DownloadCount)
+	query := `INSERT INTO clips (id, video_id, user_id, name, start_time, end_time, duration_seconds, aspect_ratio, resolution, fit_mode, fit_color, reframe, layout, segments, virality_score, status, storage_path, exports, thumbnail_url, preview_url, is_ai_suggested, suggestion_reason, view_count, download_count)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)`
+	_, err := r.pool.Exec(ctx, query, c.ID, c.VideoID, c.UserID, c.Name, c.StartTime, c.EndTime, c.DurationSeconds, c.AspectRatio, c.Resolution, c.FitMode, c.FitColor, c.Reframe, c.Layout, c.Segments, c.ViralityScore, c.Status, c.StoragePath, c.Exports, c.ThumbnailURL, c.PreviewURL, c.IsAISuggested, c.SuggestionReason, c.ViewCount, c.DownloadCount)
 	return err
 }
 
 func (r *clipRepository) GetByID(ctx context.Context, id string) (*domain.Clip, error) {
-	query := `SELECT id, video_id, user_id, name, start_time, end_time, duration_seconds, aspect_ratio, virality_score, status, storage_path, thumbnail_url, is_ai_suggested, suggestion_reason, view_count, download_count, created_at, updated_at
+	query := `SELECT id, video_id, user_id, name, start_time, end_time, duration_seconds, aspect_ratio, resolution, fit_mode, fit_color, reframe, layout, segments, virality_score, status, storage_path, exports, thumbnail_url, preview_url, is_ai_suggested, suggestion_reason, view_count, download_count, created_at, updated_at
 		FROM clips WHERE id = $1 AND deleted_at IS NULL`
 	var c domain.Clip
-	err := r.pool.QueryRow(ctx, query, id).Scan(&c.ID, &c.VideoID, &c.UserID, &c.Name, &c.StartTime, &c.EndTime, &c.DurationSeconds, &c.AspectRatio, &c.ViralityScore, &c.Status, &c.StoragePath, &c.ThumbnailURL, &c.IsAISuggested, &c.SuggestionReason, &c.ViewCount, &c.DownloadCount, &c.CreatedAt, &c.UpdatedAt)
+	err := r.pool.QueryRow(ctx, query, id).Scan(&c.ID, &c.VideoID, &c.UserID, &c.Name, &c.StartTime, &c.EndTime, &c.DurationSeconds, &c.AspectRatio, &c.Resolution, &c.FitMode, &c.FitColor, &c.Reframe, &c.Layout, &c.Segments, &c.ViralityScore, &c.Status, &c.StoragePath, &c.Exports, &c.ThumbnailURL, &c.PreviewURL, &c.IsAISuggested, &c.SuggestionReason, &c.ViewCount, &c.DownloadCount, &c.CreatedAt, &c.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +66,7 @@ func (r *clipRepository) List(ctx context.Context, userID string, videoID *strin
 	if !allowedSort[sortBy] {
 		sortBy = "created_at"
 	}
-	query := `SELECT id, video_id, user_id, name, start_time, end_time, duration_seconds, aspect_ratio, virality_score, status, storage_path, thumbnail_url, is_ai_suggested, suggestion_reason, view_count, download_count, created_at, updated_at
+	query := `SELECT id, video_id, user_id, name, start_time, end_time, duration_seconds, aspect_ratio, resolution, fit_mode, fit_color, reframe, layout, segments, virality_score, status, storage_path, exports, thumbnail_url, preview_url, is_ai_suggested, suggestion_reason, view_count, download_count, created_at, updated_at
 		FROM clips WHERE user_id = $1 AND deleted_at IS NULL`
 	queryArgs := []interface{}{userID}
 	pos := 2
@@ -90,7 +90,7 @@ func (r *clipRepository) List(ctx context.Context, userID string, videoID *strin
 	var list []*domain.Clip
 	for rows.Next() {
 		var c domain.Clip
-		if err := rows.Scan(&c.ID, &c.VideoID, &c.UserID, &c.Name, &c.StartTime, &c.EndTime, &c.DurationSeconds, &c.AspectRatio, &c.ViralityScore, &c.Status, &c.StoragePath, &c.ThumbnailURL, &c.IsAISuggested, &c.SuggestionReason, &c.ViewCount, &c.DownloadCount, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.VideoID, &c.UserID, &c.Name, &c.StartTime, &c.EndTime, &c.DurationSeconds, &c.AspectRatio, &c.Resolution, &c.FitMode, &c.FitColor, &c.Reframe, &c.Layout, &c.Segments, &c.ViralityScore, &c.Status, &c.StoragePath, &c.Exports, &c.ThumbnailURL, &c.PreviewURL, &c.IsAISuggested, &c.SuggestionReason, &c.ViewCount, &c.DownloadCount, &c.CreatedAt, &c.UpdatedAt); err != nil {
 			return nil, 0, err
 		}
 		list = append(list, &c)
@@ -99,9 +99,9 @@ func (r *clipRepository) List(ctx context.Context, userID string, videoID *strin
 }
 
 func (r *clipRepository) Update(ctx context.Context, c *domain.Clip) error {
-	query := `UPDATE clips SET name = $2, start_time = $3, end_time = $4, duration_seconds = $5, aspect_ratio = $6, virality_score = $7, status = $8, storage_path = $9, thumbnail_url = $10, updated_at = NOW()
+	query := `UPDATE clips SET name = $2, start_time = $3, end_time = $4, duration_seconds = $5, aspect_ratio = $6, virality_score = $7, status = $8, storage_path = $9, thumbnail_url = $10, preview_url = $11, fit_mode = $12, fit_color = $13, resolution = $14, reframe = $15, layout = $16, segments = $17, exports = $18, updated_at = NOW()
 		WHERE id = $1 AND deleted_at IS NULL`
-	_, err := r.pool.Exec(ctx, query, c.ID, c.Name, c.StartTime, c.EndTime, c.DurationSeconds, c.AspectRatio, c.ViralityScore, c.Status, c.StoragePath, c.ThumbnailURL)
+	_, err := r.pool.Exec(ctx, query, c.ID, c.Name, c.StartTime, c.EndTime, c.DurationSeconds, c.AspectRatio, c.ViralityScore, c.Status, c.StoragePath, c.ThumbnailURL, c.PreviewURL, c.FitMode, c.FitColor, c.Resolution, c.Reframe, c.Layout, c.Segments, c.Exports)
 	return err
 }
 
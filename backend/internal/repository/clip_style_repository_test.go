@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"reelcut/internal/domain"
+	"reelcut/pkg/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPool connects to the database in TEST_DATABASE_URL and applies the migrations; tests that need
+// it are skipped when it is not set.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	if err := database.RunMigrations(url); err != nil {
+		t.Fatal(err)
+	}
+	pool, err := database.NewPostgresPool(context.Background(), url, database.PoolConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestClipStyleRepository_AlphaColors(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+	userID, videoID, clipID := uuid.New(), uuid.New(), uuid.New()
+	if _, err := pool.Exec(ctx, `INSERT INTO users (id, email, password_hash) VALUES ($1, $2, 'x')`, userID, userID.String()+"@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID) })
+	if _, err := pool.Exec(ctx, `INSERT INTO videos (id, user_id, original_filename, storage_path) VALUES ($1, $2, 'a.mp4', 'videos/a.mp4')`, videoID, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `INSERT INTO clips (id, video_id, user_id, name, start_time, end_time) VALUES ($1, $2, $3, 'Clip', 0, 10)`, clipID, videoID, userID); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewClipStyleRepository(pool)
+	bg := "#00000080"
+	style := &domain.ClipStyle{ID: uuid.New(), ClipID: clipID, CaptionFont: "Inter", CaptionSize: 48, CaptionColor: "#FFFFFFCC", CaptionBgColor: &bg, CaptionPosition: "bottom", CaptionMaxWords: 3}
+	if err := repo.Create(ctx, style); err != nil {
+		t.Fatalf("create with #RRGGBBAA colours: %v", err)
+	}
+	style.CaptionColor = "#FFD400E6"
+	if err := repo.Update(ctx, style); err != nil {
+		t.Fatalf("update with a #RRGGBBAA colour: %v", err)
+	}
+	got, err := repo.GetByClipID(ctx, clipID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CaptionColor != "#FFD400E6" || got.CaptionBgColor == nil || *got.CaptionBgColor != bg {
+		t.Errorf("stored colours = %s, %v; want #FFD400E6, %s", got.CaptionColor, got.CaptionBgColor, bg)
+	}
+}
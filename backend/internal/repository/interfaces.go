@@ -14,6 +14,8 @@ type UserRepository interface {
 	UpdatePassword(ctx context.Context, userID string, passwordHash string) error
 	SetEmailVerified(ctx context.Context, userID string, verified bool) error
 	DeductCredits(ctx context.Context, userID string, amount int) error
+	// RefundCredits gives back credits deducted for work that could not be started.
+	RefundCredits(ctx context.Context, userID string, amount int) error
 	Delete(ctx context.Context, id string) error
 }
 
@@ -92,7 +94,14 @@ type ProcessingJobRepository interface {
 	GetByID(ctx context.Context, id string) (*domain.ProcessingJob, error)
 	ListByUserID(ctx context.Context, userID string, status *string, limit, offset int) ([]*domain.ProcessingJob, int, error)
 	GetByEntity(ctx context.Context, entityType, entityID string) (*domain.ProcessingJob, error)
+	// GetByEntityAndType is GetByEntity restricted to jobs of jobType, for entities with several kinds of job.
+	GetByEntityAndType(ctx context.Context, entityType, entityID, jobType string) (*domain.ProcessingJob, error)
 	Update(ctx context.Context, j *domain.ProcessingJob) error
+	// UpdateUnlessCancelled is Update for workers: it leaves a job the user cancelled in the meantime
+	// untouched and reports whether j was written.
+	UpdateUnlessCancelled(ctx context.Context, j *domain.ProcessingJob) (bool, error)
+	// UpdateProgress sets progress only while the job is processing, so it never overwrites a cancellation.
+	UpdateProgress(ctx context.Context, id string, progress int) error
 }
 
 type UsageLogRepository interface {
@@ -87,8 +87,34 @@ func (r *processingJobRepository) GetByEntity(ctx context.Context, entityType, e
 	return &j, nil
 }
 
+func (r *processingJobRepository) GetByEntityAndType(ctx context.Context, entityType, entityID, jobType string) (*domain.ProcessingJob, error) {
+	query := `SELECT id, user_id, job_type, entity_type, entity_id, priority, status, progress, error_message, retry_count, max_retries, metadata, started_at, completed_at, created_at, updated_at
+		FROM processing_jobs WHERE entity_type = $1 AND entity_id = $2 AND job_type = $3 ORDER BY created_at DESC LIMIT 1`
+	var j domain.ProcessingJob
+	err := r.pool.QueryRow(ctx, query, entityType, entityID, jobType).Scan(&j.ID, &j.UserID, &j.JobType, &j.EntityType, &j.EntityID, &j.Priority, &j.Status, &j.Progress, &j.ErrorMessage, &j.RetryCount, &j.MaxRetries, &j.Metadata, &j.StartedAt, &j.CompletedAt, &j.CreatedAt, &j.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
 func (r *processingJobRepository) Update(ctx context.Context, j *domain.ProcessingJob) error {
 	query := `UPDATE processing_jobs SET status = $2, progress = $3, error_message = $4, retry_count = $5, metadata = $6, started_at = $7, completed_at = $8, updated_at = NOW() WHERE id = $1`
 	_, err := r.pool.Exec(ctx, query, j.ID, j.Status, j.Progress, j.ErrorMessage, j.RetryCount, j.Metadata, j.StartedAt, j.CompletedAt)
 	return err
 }
+
+func (r *processingJobRepository) UpdateUnlessCancelled(ctx context.Context, j *domain.ProcessingJob) (bool, error) {
+	query := `UPDATE processing_jobs SET status = $2, progress = $3, error_message = $4, retry_count = $5, metadata = $6, started_at = $7, completed_at = $8, updated_at = NOW() WHERE id = $1 AND status <> 'cancelled'`
+	tag, err := r.pool.Exec(ctx, query, j.ID, j.Status, j.Progress, j.ErrorMessage, j.RetryCount, j.Metadata, j.StartedAt, j.CompletedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *processingJobRepository) UpdateProgress(ctx context.Context, id string, progress int) error {
+	query := `UPDATE processing_jobs SET progress = $2, updated_at = NOW() WHERE id = $1 AND status = 'processing'`
+	_, err := r.pool.Exec(ctx, query, id, progress)
+	return err
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"reelcut/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestProcessingJobRepository_UpdateUnlessCancelled(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+	userID := uuid.New()
+	if _, err := pool.Exec(ctx, `INSERT INTO users (id, email, password_hash) VALUES ($1, $2, 'x')`, userID, userID.String()+"@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID) })
+
+	repo := NewProcessingJobRepository(pool)
+	job := &domain.ProcessingJob{ID: uuid.New(), UserID: userID, JobType: "rendering", EntityType: "clip", EntityID: uuid.New(), Status: "processing"}
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	job.Progress = 50
+	if ok, err := repo.UpdateUnlessCancelled(ctx, job); err != nil || !ok {
+		t.Fatalf("processing job: UpdateUnlessCancelled = %v, %v; want written", ok, err)
+	}
+
+	// The user cancels while the worker still holds the job as processing.
+	cancelled := *job
+	cancelled.Status = "cancelled"
+	if err := repo.Update(ctx, &cancelled); err != nil {
+		t.Fatal(err)
+	}
+	job.Status, job.Progress = "completed", 100
+	if ok, err := repo.UpdateUnlessCancelled(ctx, job); err != nil || ok {
+		t.Errorf("cancelled job: UpdateUnlessCancelled = %v, %v; want skipped", ok, err)
+	}
+	if got, err := repo.GetByID(ctx, job.ID.String()); err != nil || got.Status != "cancelled" {
+		t.Errorf("status after a late completion = %v (err %v), want cancelled", got, err)
+	}
+}
@@ -79,6 +79,12 @@ func (r *userRepository) DeductCredits(ctx context.Context, userID string, amoun
 	return nil
 }
 
+func (r *userRepository) RefundCredits(ctx context.Context, userID string, amount int) error {
+	query := `UPDATE users SET credits_remaining = credits_remaining + $2, updated_at = NOW() WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, userID, amount)
+	return err
+}
+
 func (r *userRepository) Delete(ctx context.Context, id string) error {
 	query := `UPDATE users SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1`
 	_, err := r.pool.Exec(ctx, query, id)
@@ -18,19 +18,19 @@ func NewVideoRepository(pool *pgxpool.Pool) VideoRepository {
 }
 
 func (r *videoRepository) Create(ctx context.Context, v *domain.Video) error {
-	query := `INSERT INTO videos (id, project_id, user_id, original_filename, storage_path, thumbnail_url, duration_seconds, width, height, fps, file_size_bytes, codec, bitrate, status, error_message, metadata)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+	query := `INSERT INTO videos (id, project_id, user_id, original_filename, storage_path, thumbnail_url, duration_seconds, width, height, fps, file_size_bytes, codec, bitrate, status, error_message, metadata, hls_path, proxy_path, renditions_status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`
 	_, err := r.pool.Exec(ctx, query,
-		v.ID, v.ProjectID, v.UserID, v.OriginalFilename, v.StoragePath, v.ThumbnailURL, v.DurationSeconds, v.Width, v.Height, v.FPS, v.FileSizeBytes, v.Codec, v.Bitrate, v.Status, v.ErrorMessage, v.Metadata)
+		v.ID, v.ProjectID, v.UserID, v.OriginalFilename, v.StoragePath, v.ThumbnailURL, v.DurationSeconds, v.Width, v.Height, v.FPS, v.FileSizeBytes, v.Codec, v.Bitrate, v.Status, v.ErrorMessage, v.Metadata, v.HLSPath, v.ProxyPath, v.RenditionsStatus)
 	return err
 }
 
 func (r *videoRepository) GetByID(ctx context.Context, id string) (*domain.Video, error) {
-	query := `SELECT id, project_id, user_id, original_filename, storage_path, thumbnail_url, duration_seconds, width, height, fps, file_size_bytes, codec, bitrate, status, error_message, metadata, created_at, updated_at
+	query := `SELECT id, project_id, user_id, original_filename, storage_path, thumbnail_url, duration_seconds, width, height, fps, file_size_bytes, codec, bitrate, status, error_message, metadata, hls_path, proxy_path, renditions_status, created_at, updated_at
 		FROM videos WHERE id = $1 AND deleted_at IS NULL`
 	var v domain.Video
 	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&v.ID, &v.ProjectID, &v.UserID, &v.OriginalFilename, &v.StoragePath, &v.ThumbnailURL, &v.DurationSeconds, &v.Width, &v.Height, &v.FPS, &v.FileSizeBytes, &v.Codec, &v.Bitrate, &v.Status, &v.ErrorMessage, &v.Metadata, &v.CreatedAt, &v.UpdatedAt)
+		&v.ID, &v.ProjectID, &v.UserID, &v.OriginalFilename, &v.StoragePath, &v.ThumbnailURL, &v.DurationSeconds, &v.Width, &v.Height, &v.FPS, &v.FileSizeBytes, &v.Codec, &v.Bitrate, &v.Status, &v.ErrorMessage, &v.Metadata, &v.HLSPath, &v.ProxyPath, &v.RenditionsStatus, &v.CreatedAt, &v.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +68,7 @@ func (r *videoRepository) List(ctx context.Context, userID string, projectID *st
 	if !allowedSort[sortBy] {
 		sortBy = "created_at"
 	}
-	query := `SELECT id, project_id, user_id, original_filename, storage_path, thumbnail_url, duration_seconds, width, height, fps, file_size_bytes, codec, bitrate, status, error_message, metadata, created_at, updated_at
+	query := `SELECT id, project_id, user_id, original_filename, storage_path, thumbnail_url, duration_seconds, width, height, fps, file_size_bytes, codec, bitrate, status, error_message, metadata, hls_path, proxy_path, renditions_status, created_at, updated_at
 		FROM videos WHERE user_id = $1 AND deleted_at IS NULL`
 	queryArgs := []interface{}{userID}
 	pos := 2
@@ -92,7 +92,7 @@ func (r *videoRepository) List(ctx context.Context, userID string, projectID *st
 	var list []*domain.Video
 	for rows.Next() {
 		var v domain.Video
-		if err := rows.Scan(&v.ID, &v.ProjectID, &v.UserID, &v.OriginalFilename, &v.StoragePath, &v.ThumbnailURL, &v.DurationSeconds, &v.Width, &v.Height, &v.FPS, &v.FileSizeBytes, &v.Codec, &v.Bitrate, &v.Status, &v.ErrorMessage, &v.Metadata, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		if err := rows.Scan(&v.ID, &v.ProjectID, &v.UserID, &v.OriginalFilename, &v.StoragePath, &v.ThumbnailURL, &v.DurationSeconds, &v.Width, &v.Height, &v.FPS, &v.FileSizeBytes, &v.Codec, &v.Bitrate, &v.Status, &v.ErrorMessage, &v.Metadata, &v.HLSPath, &v.ProxyPath, &v.RenditionsStatus, &v.CreatedAt, &v.UpdatedAt); err != nil {
 			return nil, 0, err
 		}
 		list = append(list, &v)
@@ -101,9 +101,9 @@ func (r *videoRepository) List(ctx context.Context, userID string, projectID *st
 }
 
 func (r *videoRepository) Update(ctx context.Context, v *domain.Video) error {
-	query := `UPDATE videos SET thumbnail_url = $2, duration_seconds = $3, width = $4, height = $5, fps = $6, file_size_bytes = $7, codec = $8, bitrate = $9, status = $10, error_message = $11, metadata = $12, updated_at = NOW()
+	query := `UPDATE videos SET thumbnail_url = $2, duration_seconds = $3, width = $4, height = $5, fps = $6, file_size_bytes = $7, codec = $8, bitrate = $9, status = $10, error_message = $11, metadata = $12, hls_path = $13, proxy_path = $14, renditions_status = $15, updated_at = NOW()
 		WHERE id = $1 AND deleted_at IS NULL`
-	_, err := r.pool.Exec(ctx, query, v.ID, v.ThumbnailURL, v.DurationSeconds, v.Width, v.Height, v.FPS, v.FileSizeBytes, v.Codec, v.Bitrate, v.Status, v.ErrorMessage, v.Metadata)
+	_, err := r.pool.Exec(ctx, query, v.ID, v.ThumbnailURL, v.DurationSeconds, v.Width, v.Height, v.FPS, v.FileSizeBytes, v.Codec, v.Bitrate, v.Status, v.ErrorMessage, v.Metadata, v.HLSPath, v.ProxyPath, v.RenditionsStatus)
 	return err
 }
 
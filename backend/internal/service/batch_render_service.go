@@ -0,0 +1,558 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/queue"
+	"reelcut/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxBatchClips bounds a single batch render.
+const maxBatchClips = 50
+
+// BatchRenderService renders several clips of a video under one parent job and packages the results
+// as a ZIP (renders, captions, manifest.json).
+type BatchRenderService struct {
+	clipRepo      repository.ClipRepository
+	clipStyleRepo repository.ClipStyleRepository
+	videoRepo     repository.VideoRepository
+	jobRepo       repository.ProcessingJobRepository
+	userRepo      repository.UserRepository
+	usageLogRepo  repository.UsageLogRepository
+	clipSvc       *ClipService
+	storage       *StorageService
+	queue         batchQueue
+	renderingSvc  *RenderingService
+}
+
+// batchQueue is the part of queue.QueueClient the batch service needs.
+type batchQueue interface {
+	EnqueueRender(clipID, jobID uuid.UUID) error
+	EnqueueBatchExport(jobID uuid.UUID) error
+	CancelJob(jobID uuid.UUID) error
+}
+
+func NewBatchRenderService(
+	clipRepo repository.ClipRepository,
+	clipStyleRepo repository.ClipStyleRepository,
+	videoRepo repository.VideoRepository,
+	jobRepo repository.ProcessingJobRepository,
+	userRepo repository.UserRepository,
+	usageLogRepo repository.UsageLogRepository,
+	clipSvc *ClipService,
+	storage *StorageService,
+	queue *queue.QueueClient,
+	renderingSvc *RenderingService,
+) *BatchRenderService {
+	return &BatchRenderService{
+		clipRepo:      clipRepo,
+		clipStyleRepo: clipStyleRepo,
+		videoRepo:     videoRepo,
+		jobRepo:       jobRepo,
+		userRepo:      userRepo,
+		usageLogRepo:  usageLogRepo,
+		clipSvc:       clipSvc,
+		storage:       storage,
+		queue:         queue,
+		renderingSvc:  renderingSvc,
+	}
+}
+
+// Start renders clipIDs of videoID (all of the video's clips when empty) under a new "batch_render"
+// parent job. One credit is charged per clip that has to be rendered, up front and all-or-nothing;
+// clips whose render is cached complete immediately. With presets, each clip is rendered once per
+// export preset instead, for one credit per output that is not cached.
+func (s *BatchRenderService) Start(ctx context.Context, userID, videoID string, clipIDs, presets []string) (*domain.ProcessingJob, error) {
+	v, err := s.videoRepo.GetByID(ctx, videoID)
+	if err != nil || v == nil || v.UserID.String() != userID {
+		return nil, domain.ErrNotFound
+	}
+	if presets, err = validatePresets(presets); err != nil {
+		return nil, err
+	}
+	var clips []*domain.Clip
+	if len(clipIDs) == 0 {
+		clips, _, err = s.clipRepo.List(ctx, userID, &videoID, nil, maxBatchClips+1, 0, "created_at", "asc")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		seen := map[string]bool{}
+		for _, id := range clipIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			c, err := s.clipRepo.GetByID(ctx, id)
+			if err != nil || c == nil || c.UserID.String() != userID || c.VideoID != v.ID {
+				return nil, domain.ErrNotFound
+			}
+			clips = append(clips, c)
+		}
+	}
+	if len(clips) == 0 {
+		return nil, &domain.ValidationError{Field: "clip_ids", Message: "no clips to render"}
+	}
+	if len(clips) > maxBatchClips {
+		return nil, &domain.ValidationError{Field: "clip_ids", Message: fmt.Sprintf("at most %d clips per batch", maxBatchClips)}
+	}
+
+	if len(presets) > 0 {
+		if err := checkPresetResolutions(ctx, s.userRepo, userID, presets); err != nil {
+			return nil, err
+		}
+		for _, c := range clips {
+			if err := checkPresetDurations(ctx, s.renderingSvc, c.ID.String(), presets); err != nil {
+				return nil, fmt.Errorf("clip %q: %w", c.Name, err)
+			}
+		}
+	} else {
+		for _, c := range clips {
+			if err := checkResolution(ctx, s.userRepo, userID, c.Resolution); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Outputs with an identical render already in storage are reused at no charge.
+	cached := make([]bool, len(clips))
+	pending := make([][]string, len(clips))
+	misses := 0
+	for i, c := range clips {
+		if len(presets) == 0 {
+			if cached[i], err = s.renderingSvc.ReuseCached(ctx, c.ID.String()); err != nil {
+				return nil, err
+			}
+			if !cached[i] {
+				misses++
+			}
+			continue
+		}
+		if pending[i], err = s.renderingSvc.ReuseCachedExports(ctx, c.ID.String(), presets); err != nil {
+			return nil, err
+		}
+		cached[i], misses = len(pending[i]) == 0, misses+len(pending[i])
+		// Reload: cached exports were just recorded on the clip.
+		if clips[i], err = s.clipRepo.GetByID(ctx, c.ID.String()); err != nil || clips[i] == nil {
+			return nil, domain.ErrNotFound
+		}
+	}
+	if misses > 0 {
+		if err := s.userRepo.DeductCredits(ctx, userID, misses); err != nil {
+			return nil, domain.ErrInsufficientCredits
+		}
+	}
+	parent, err := s.startJobs(ctx, v, clips, cached, pending, presets)
+	if err != nil {
+		if misses > 0 {
+			// None of the batch runs: give the credits back.
+			if rerr := s.userRepo.RefundCredits(context.WithoutCancel(ctx), userID, misses); rerr != nil {
+				slog.Error("batch render: credit refund failed", "user_id", userID, "credits", misses, "err", rerr)
+			}
+		}
+		return nil, err
+	}
+	if misses > 0 {
+		if err := s.usageLogRepo.Create(ctx, &domain.UsageLog{ID: uuid.New(), UserID: v.UserID, Action: "render", CreditsUsed: misses}); err != nil {
+			slog.Warn("batch render: usage log failed", "job_id", parent.ID, "err", err)
+		}
+	}
+	return parent, nil
+}
+
+// startJobs creates the parent job and a child render per clip, completing the cached ones and queueing
+// the rest. If it fails part way, the parent is failed and the children already queued are cancelled,
+// so nothing of the batch runs.
+func (s *BatchRenderService) startJobs(ctx context.Context, v *domain.Video, clips []*domain.Clip, cached []bool, pending [][]string, presets []string) (*domain.ProcessingJob, error) {
+	videoID := v.ID.String()
+	now := time.Now()
+	parent := &domain.ProcessingJob{
+		ID:         uuid.New(),
+		UserID:     v.UserID,
+		JobType:    "batch_render",
+		EntityType: "video",
+		EntityID:   v.ID,
+		Status:     "processing",
+		StartedAt:  &now,
+	}
+	meta := domain.BatchRenderMetadata{VideoID: videoID, Presets: presets}
+	children := make([]*domain.ProcessingJob, len(clips))
+	for i, c := range clips {
+		childMeta, _ := json.Marshal(domain.RenderJobMetadata{ParentJobID: parent.ID.String(), Presets: pending[i]})
+		children[i] = &domain.ProcessingJob{
+			ID:         uuid.New(),
+			UserID:     v.UserID,
+			JobType:    "rendering",
+			EntityType: "clip",
+			EntityID:   c.ID,
+			Status:     "pending",
+			Metadata:   childMeta,
+		}
+		meta.ClipIDs = append(meta.ClipIDs, c.ID.String())
+		meta.ChildJobIDs = append(meta.ChildJobIDs, children[i].ID.String())
+	}
+	parent.Metadata, _ = json.Marshal(meta)
+	if err := s.jobRepo.Create(ctx, parent); err != nil {
+		return nil, err
+	}
+	if err := s.startChildren(ctx, parent, clips, children, cached); err != nil {
+		bg := context.WithoutCancel(ctx)
+		msg := err.Error()
+		parent.Status, parent.ErrorMessage = "failed", &msg
+		if uerr := s.jobRepo.Update(bg, parent); uerr != nil {
+			slog.Warn("batch render: failing parent job", "job_id", parent.ID, "err", uerr)
+		}
+		if cerr := s.CancelChildren(bg, parent.ID.String()); cerr != nil {
+			slog.Warn("batch render: cancelling child renders", "job_id", parent.ID, "err", cerr)
+		}
+		return nil, err
+	}
+	return parent, nil
+}
+
+// startChildren creates the child jobs of parent and queues those without a cached render.
+func (s *BatchRenderService) startChildren(ctx context.Context, parent *domain.ProcessingJob, clips []*domain.Clip, children []*domain.ProcessingJob, cached []bool) error {
+	now := time.Now()
+	misses := 0
+	for i, c := range clips {
+		if cached[i] {
+			children[i].Status, children[i].Progress = "completed", 100
+			children[i].StartedAt, children[i].CompletedAt = &now, &now
+			if err := s.jobRepo.Create(ctx, children[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		misses++
+		if err := s.jobRepo.Create(ctx, children[i]); err != nil {
+			return err
+		}
+		if err := s.queue.EnqueueRender(c.ID, children[i].ID); err != nil {
+			return err
+		}
+		c.Status = "rendering"
+		if err := s.clipRepo.Update(ctx, c); err != nil {
+			return err
+		}
+	}
+	if misses == 0 {
+		return s.queue.EnqueueBatchExport(parent.ID)
+	}
+	return nil
+}
+
+// ChildFinished is called by the rendering worker when a render that belongs to a batch reaches a
+// final state. It rolls child progress up into the parent and enqueues the ZIP export once every
+// child is done.
+func (s *BatchRenderService) ChildFinished(ctx context.Context, child *domain.ProcessingJob) error {
+	parent, meta, err := s.batchOf(ctx, child)
+	if parent == nil || err != nil {
+		return err
+	}
+	progress, done := s.childProgress(ctx, meta)
+	if err := s.jobRepo.UpdateProgress(ctx, parent.ID.String(), progress); err != nil || !done {
+		return err
+	}
+	return s.queue.EnqueueBatchExport(parent.ID)
+}
+
+// ChildProgressed is called by the rendering worker as a render that belongs to a batch makes
+// progress, so the parent moves with its children rather than only as each one finishes.
+func (s *BatchRenderService) ChildProgressed(ctx context.Context, child *domain.ProcessingJob) error {
+	parent, meta, err := s.batchOf(ctx, child)
+	if parent == nil || err != nil {
+		return err
+	}
+	progress, _ := s.childProgress(ctx, meta)
+	return s.jobRepo.UpdateProgress(ctx, parent.ID.String(), progress)
+}
+
+// batchOf returns the processing batch child belongs to, or nil when it has none (or it is over).
+func (s *BatchRenderService) batchOf(ctx context.Context, child *domain.ProcessingJob) (*domain.ProcessingJob, *domain.BatchRenderMetadata, error) {
+	var childMeta domain.RenderJobMetadata
+	if len(child.Metadata) == 0 || json.Unmarshal(child.Metadata, &childMeta) != nil || childMeta.ParentJobID == "" {
+		return nil, nil, nil
+	}
+	parent, meta, err := s.getBatch(ctx, childMeta.ParentJobID)
+	if err != nil || parent.Status != "processing" {
+		return nil, nil, err
+	}
+	return parent, meta, nil
+}
+
+// childProgress is the parent's progress from its children's: their average over the first 90%, the
+// last 10% being reserved for packaging the ZIP. Finished children count as done whatever their
+// outcome; done reports whether all of them are.
+func (s *BatchRenderService) childProgress(ctx context.Context, meta *domain.BatchRenderMetadata) (progress int, done bool) {
+	sum, finished, total := 0, 0, len(meta.ChildJobIDs)
+	if total == 0 {
+		return 90, true
+	}
+	for _, id := range meta.ChildJobIDs {
+		j, err := s.jobRepo.GetByID(ctx, id)
+		if err != nil || j == nil {
+			continue
+		}
+		if domain.JobFinished(j.Status) {
+			sum += 100
+			finished++
+		} else {
+			sum += min(max(j.Progress, 0), 100)
+		}
+	}
+	return sum * 90 / (total * 100), finished == total
+}
+
+// Export packages the finished batch as a ZIP in storage and completes the parent job.
+func (s *BatchRenderService) Export(ctx context.Context, parentJobID string) (*domain.ProcessingJob, error) {
+	parent, meta, err := s.getBatch(ctx, parentJobID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Status != "processing" {
+		return parent, nil
+	}
+	tmpDir := filepath.Join(os.TempDir(), "reelcut", "export", parentJobID)
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	zipPath := filepath.Join(tmpDir, "clips.zip")
+	rendered, err := s.writeZip(ctx, zipPath, parentJobID, meta)
+	if err != nil {
+		return nil, err
+	}
+	completed := time.Now()
+	parent.CompletedAt = &completed
+	parent.Progress = 100
+	if rendered == 0 {
+		msg := "no clip rendered successfully"
+		parent.Status = "failed"
+		parent.ErrorMessage = &msg
+		return parent, s.jobRepo.Update(ctx, parent)
+	}
+	key := filepath.ToSlash(filepath.Join("exports", parentJobID, "clips.zip"))
+	if err := uploadFile(ctx, s.storage, zipPath, key, "application/zip"); err != nil {
+		return nil, fmt.Errorf("upload export: %w", err)
+	}
+	meta.DownloadKey = key
+	parent.Metadata, _ = json.Marshal(meta)
+	parent.Status = "completed"
+	return parent, s.jobRepo.Update(ctx, parent)
+}
+
+// DownloadURL returns a presigned URL for a finished batch's ZIP.
+func (s *BatchRenderService) DownloadURL(ctx context.Context, jobID, userID string) (string, error) {
+	parent, meta, err := s.getBatch(ctx, jobID)
+	if err != nil || parent.UserID.String() != userID {
+		return "", domain.ErrNotFound
+	}
+	if meta.DownloadKey == "" {
+		return "", domain.ErrNotFound
+	}
+	return s.storage.GeneratePresignedGet(ctx, meta.DownloadKey, time.Hour)
+}
+
+// CancelChildren cancels the unfinished renders of the batch parentJobID after the parent was
+// cancelled: their jobs are marked cancelled and running renders are stopped. Queued renders stay in
+// the queue; the rendering worker skips them when it picks them up and puts their clips back.
+func (s *BatchRenderService) CancelChildren(ctx context.Context, parentJobID string) error {
+	_, meta, err := s.getBatch(ctx, parentJobID)
+	if err != nil {
+		return err
+	}
+	for _, id := range meta.ChildJobIDs {
+		j, err := s.jobRepo.GetByID(ctx, id)
+		if err != nil || j == nil || domain.JobFinished(j.Status) {
+			continue
+		}
+		j.Status = "cancelled"
+		if err := s.jobRepo.Update(ctx, j); err != nil {
+			return err
+		}
+		if err := s.queue.CancelJob(j.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *BatchRenderService) getBatch(ctx context.Context, jobID string) (*domain.ProcessingJob, *domain.BatchRenderMetadata, error) {
+	j, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil || j == nil || j.JobType != "batch_render" {
+		return nil, nil, domain.ErrNotFound
+	}
+	var meta domain.BatchRenderMetadata
+	if err := json.Unmarshal(j.Metadata, &meta); err != nil {
+		return nil, nil, fmt.Errorf("batch metadata: %w", err)
+	}
+	return j, &meta, nil
+}
+
+// batchManifest is manifest.json inside the export ZIP.
+type batchManifest struct {
+	JobID       string              `json:"job_id"`
+	VideoID     string              `json:"video_id"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	Clips       []batchManifestClip `json:"clips"`
+}
+
+type batchManifestClip struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Status        string            `json:"status"`
+	StartTime     float64           `json:"start_time"`
+	EndTime       float64           `json:"end_time"`
+	Duration      float64           `json:"duration_seconds"`
+	AspectRatio   string            `json:"aspect_ratio"`
+	ViralityScore *float64          `json:"virality_score,omitempty"`
+	Video         string            `json:"video,omitempty"`
+	Exports       map[string]string `json:"exports,omitempty"` // export preset -> file in the ZIP
+	CaptionsSRT   string            `json:"captions_srt,omitempty"`
+	CaptionsVTT   string            `json:"captions_vtt,omitempty"`
+	Error         string            `json:"error,omitempty"`
+}
+
+// writeZip writes every child's render and captions plus manifest.json to zipPath and returns how
+// many renders were included. Failed or cancelled clips are listed in the manifest only.
+func (s *BatchRenderService) writeZip(ctx context.Context, zipPath, parentJobID string, meta *domain.BatchRenderMetadata) (int, error) {
+	f, err := os.Create(zipPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+
+	manifest := batchManifest{JobID: parentJobID, VideoID: meta.VideoID, GeneratedAt: time.Now().UTC()}
+	rendered := 0
+	for i, jobID := range meta.ChildJobIDs {
+		if i >= len(meta.ClipIDs) {
+			break
+		}
+		job, _ := s.jobRepo.GetByID(ctx, jobID)
+		c, err := s.clipRepo.GetByID(ctx, meta.ClipIDs[i])
+		if err != nil || c == nil {
+			continue
+		}
+		// The manifest reports the rendered length: segments joined, transitions overlapped and jump
+		// cuts taken out.
+		duration := c.EndTime - c.StartTime
+		if edits, err := s.renderingSvc.EditList(ctx, c.ID.String()); err == nil {
+			duration = edits.OutputDuration
+		}
+		entry := batchManifestClip{
+			ID:            c.ID.String(),
+			Name:          c.Name,
+			StartTime:     c.StartTime,
+			EndTime:       c.EndTime,
+			Duration:      duration,
+			AspectRatio:   c.AspectRatio,
+			ViralityScore: c.ViralityScore,
+			Status:        "failed",
+		}
+		if job != nil {
+			entry.Status = job.Status
+			if job.ErrorMessage != nil {
+				entry.Error = *job.ErrorMessage
+			}
+		}
+		base := fmt.Sprintf("%02d-%s", i+1, slugify(c.Name))
+		if entry.Status == "completed" && len(meta.Presets) == 0 && c.StoragePath != nil && *c.StoragePath != "" {
+			entry.Video = base + ".mp4"
+			if err := s.addStorageObject(ctx, zw, entry.Video, *c.StoragePath); err != nil {
+				return 0, err
+			}
+			rendered++
+		}
+		if entry.Status == "completed" && len(meta.Presets) > 0 {
+			for _, preset := range meta.Presets {
+				key, ok := c.Exports[preset]
+				if !ok {
+					continue
+				}
+				if entry.Exports == nil {
+					entry.Exports = map[string]string{}
+				}
+				entry.Exports[preset] = base + "-" + preset + ".mp4"
+				if err := s.addStorageObject(ctx, zw, entry.Exports[preset], key); err != nil {
+					return 0, err
+				}
+			}
+			if len(entry.Exports) > 0 {
+				rendered++
+			}
+		}
+		// Captions are timed on the rendered clip: across its segments and around its jump cuts.
+		c.Style, _ = s.clipStyleRepo.GetByClipID(ctx, c.ID.String())
+		if blocks, err := s.clipSvc.captionBlocks(ctx, c, true); err == nil {
+			entry.CaptionsSRT, entry.CaptionsVTT = base+".srt", base+".vtt"
+			if err := addZipFile(zw, entry.CaptionsSRT, []byte(ToSRT(blocks))); err != nil {
+				return 0, err
+			}
+			if err := addZipFile(zw, entry.CaptionsVTT, []byte(ToVTT(blocks))); err != nil {
+				return 0, err
+			}
+		}
+		manifest.Clips = append(manifest.Clips, entry)
+	}
+	data, _ := json.MarshalIndent(manifest, "", "  ")
+	if err := addZipFile(zw, "manifest.json", data); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	return rendered, nil
+}
+
+func (s *BatchRenderService) addStorageObject(ctx context.Context, zw *zip.Writer, name, key string) error {
+	rc, err := s.storage.Download(ctx, key)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", key, err)
+	}
+	defer rc.Close()
+	// MP4 is already compressed; store it to save CPU.
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, rc)
+	return err
+}
+
+func addZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+var slugUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify turns a clip name into a short file-name-safe slug.
+func slugify(name string) string {
+	s := strings.Trim(slugUnsafe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(s) > 40 {
+		s = strings.TrimRight(s[:40], "-")
+	}
+	if s == "" {
+		return "clip"
+	}
+	return s
+}
@@ -0,0 +1,519 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"reelcut/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// fakeS3 is an in-memory S3 bucket ("test") serving path-style GET and HEAD requests.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	body, ok := f.objects[strings.TrimPrefix(r.URL.Path, "/test/")]
+	f.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("ETag", fmt.Sprintf("%q", body))
+	w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+	if r.Method == http.MethodGet {
+		io.WriteString(w, body)
+	}
+}
+
+func (f *fakeS3) put(key, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[key] = body
+}
+
+type memClipRepo struct{ clips map[string]*domain.Clip }
+
+func (m *memClipRepo) Create(ctx context.Context, c *domain.Clip) error {
+	m.clips[c.ID.String()] = c
+	return nil
+}
+func (m *memClipRepo) GetByID(ctx context.Context, id string) (*domain.Clip, error) {
+	c, ok := m.clips[id]
+	if !ok {
+		return nil, nil
+	}
+	cp := *c
+	return &cp, nil
+}
+func (m *memClipRepo) List(ctx context.Context, userID string, videoID *string, status *string, limit, offset int, sortBy, sortOrder string) ([]*domain.Clip, int, error) {
+	var out []*domain.Clip
+	for _, c := range m.clips {
+		if videoID == nil || c.VideoID.String() == *videoID {
+			cp := *c
+			out = append(out, &cp)
+		}
+	}
+	return out, len(out), nil
+}
+func (m *memClipRepo) Update(ctx context.Context, c *domain.Clip) error {
+	cp := *c
+	m.clips[c.ID.String()] = &cp
+	return nil
+}
+func (m *memClipRepo) Delete(ctx context.Context, id string) error {
+	delete(m.clips, id)
+	return nil
+}
+
+type memStyleRepo struct{ styles map[string]*domain.ClipStyle }
+
+func (m *memStyleRepo) Create(ctx context.Context, s *domain.ClipStyle) error {
+	m.styles[s.ClipID.String()] = s
+	return nil
+}
+func (m *memStyleRepo) GetByClipID(ctx context.Context, clipID string) (*domain.ClipStyle, error) {
+	return m.styles[clipID], nil
+}
+func (m *memStyleRepo) Update(ctx context.Context, s *domain.ClipStyle) error {
+	m.styles[s.ClipID.String()] = s
+	return nil
+}
+
+type memVideoRepo struct{ videos map[string]*domain.Video }
+
+func (m *memVideoRepo) Create(ctx context.Context, v *domain.Video) error {
+	m.videos[v.ID.String()] = v
+	return nil
+}
+func (m *memVideoRepo) GetByID(ctx context.Context, id string) (*domain.Video, error) {
+	return m.videos[id], nil
+}
+func (m *memVideoRepo) List(ctx context.Context, userID string, projectID *string, status *string, limit, offset int, sortBy, sortOrder string) ([]*domain.Video, int, error) {
+	return nil, 0, nil
+}
+func (m *memVideoRepo) Update(ctx context.Context, v *domain.Video) error { return nil }
+func (m *memVideoRepo) Delete(ctx context.Context, id string) error       { return nil }
+
+type memJobRepo struct {
+	jobs map[string]*domain.ProcessingJob
+}
+
+func (m *memJobRepo) Create(ctx context.Context, j *domain.ProcessingJob) error {
+	cp := *j
+	m.jobs[j.ID.String()] = &cp
+	return nil
+}
+func (m *memJobRepo) GetByID(ctx context.Context, id string) (*domain.ProcessingJob, error) {
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	cp := *j
+	return &cp, nil
+}
+func (m *memJobRepo) ListByUserID(ctx context.Context, userID string, status *string, limit, offset int) ([]*domain.ProcessingJob, int, error) {
+	return nil, 0, nil
+}
+func (m *memJobRepo) GetByEntity(ctx context.Context, entityType, entityID string) (*domain.ProcessingJob, error) {
+	return nil, nil
+}
+func (m *memJobRepo) GetByEntityAndType(ctx context.Context, entityType, entityID, jobType string) (*domain.ProcessingJob, error) {
+	return nil, nil
+}
+func (m *memJobRepo) Update(ctx context.Context, j *domain.ProcessingJob) error {
+	cp := *j
+	m.jobs[j.ID.String()] = &cp
+	return nil
+}
+func (m *memJobRepo) UpdateUnlessCancelled(ctx context.Context, j *domain.ProcessingJob) (bool, error) {
+	if cur, ok := m.jobs[j.ID.String()]; ok && cur.Status == "cancelled" {
+		return false, nil
+	}
+	return true, m.Update(ctx, j)
+}
+func (m *memJobRepo) UpdateProgress(ctx context.Context, id string, progress int) error {
+	if j, ok := m.jobs[id]; ok && j.Status == "processing" {
+		j.Progress = progress
+	}
+	return nil
+}
+
+// memUserRepo holds a single user and its credits.
+type memUserRepo struct{ user *domain.User }
+
+func (m *memUserRepo) Create(ctx context.Context, u *domain.User) error { return nil }
+func (m *memUserRepo) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	return m.user, nil
+}
+func (m *memUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return m.user, nil
+}
+func (m *memUserRepo) Update(ctx context.Context, u *domain.User) error { return nil }
+func (m *memUserRepo) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
+	return nil
+}
+func (m *memUserRepo) SetEmailVerified(ctx context.Context, userID string, verified bool) error {
+	return nil
+}
+func (m *memUserRepo) DeductCredits(ctx context.Context, userID string, amount int) error {
+	if m.user.CreditsRemaining < amount {
+		return errors.New("insufficient credits")
+	}
+	m.user.CreditsRemaining -= amount
+	return nil
+}
+func (m *memUserRepo) RefundCredits(ctx context.Context, userID string, amount int) error {
+	m.user.CreditsRemaining += amount
+	return nil
+}
+func (m *memUserRepo) Delete(ctx context.Context, id string) error { return nil }
+
+type memUsageLogRepo struct{}
+
+func (memUsageLogRepo) Create(ctx context.Context, u *domain.UsageLog) error { return nil }
+func (memUsageLogRepo) ListByUserID(ctx context.Context, userID string, limit, offset int) ([]*domain.UsageLog, int, error) {
+	return nil, 0, nil
+}
+
+type noAnalysisRepo struct{}
+
+func (noAnalysisRepo) GetByVideoID(ctx context.Context, videoID string) (*domain.VideoAnalysis, error) {
+	return nil, nil
+}
+func (noAnalysisRepo) Upsert(ctx context.Context, a *domain.VideoAnalysis) error { return nil }
+
+// memTranscriptRepo serves one transcription per video; segments carry their words.
+type memTranscriptRepo struct {
+	byVideo map[string]*domain.Transcription
+}
+
+func (m *memTranscriptRepo) Create(ctx context.Context, t *domain.Transcription) error { return nil }
+func (m *memTranscriptRepo) GetByID(ctx context.Context, id string) (*domain.Transcription, error) {
+	for _, t := range m.byVideo {
+		if t.ID.String() == id {
+			return &domain.Transcription{ID: t.ID, VideoID: t.VideoID, Language: t.Language}, nil
+		}
+	}
+	return nil, nil
+}
+func (m *memTranscriptRepo) GetByVideoID(ctx context.Context, videoID string) (*domain.Transcription, error) {
+	return m.byVideo[videoID], nil
+}
+func (m *memTranscriptRepo) Update(ctx context.Context, t *domain.Transcription) error { return nil }
+func (m *memTranscriptRepo) CreateWithSegments(ctx context.Context, t *domain.Transcription, segments []*domain.TranscriptSegment) error {
+	return nil
+}
+func (m *memTranscriptRepo) GetByTranscriptionID(ctx context.Context, transcriptionID string) ([]*domain.TranscriptSegment, error) {
+	var out []*domain.TranscriptSegment
+	for _, t := range m.byVideo {
+		if t.ID.String() == transcriptionID {
+			for _, seg := range t.Segments {
+				out = append(out, &seg)
+			}
+		}
+	}
+	return out, nil
+}
+func (m *memTranscriptRepo) CreateBatch(ctx context.Context, segments []*domain.TranscriptSegment) error {
+	return nil
+}
+
+// segmentRepo adapts memTranscriptRepo to TranscriptSegmentRepository.
+type segmentRepo struct{ *memTranscriptRepo }
+
+func (r segmentRepo) Update(ctx context.Context, s *domain.TranscriptSegment) error { return nil }
+
+type noWordRepo struct{}
+
+func (noWordRepo) GetBySegmentID(ctx context.Context, segmentID string) ([]*domain.TranscriptWord, error) {
+	return nil, nil
+}
+func (noWordRepo) CreateBatch(ctx context.Context, words []*domain.TranscriptWord) error { return nil }
+
+// fakeBatchQueue records what the batch service enqueues and cancels. With maxRenders set, renders
+// past that many fail to enqueue.
+type fakeBatchQueue struct {
+	renders, exports, cancelled []uuid.UUID
+	maxRenders                  int
+}
+
+func (q *fakeBatchQueue) EnqueueRender(clipID, jobID uuid.UUID) error {
+	if q.maxRenders > 0 && len(q.renders) >= q.maxRenders {
+		return errors.New("redis: connection refused")
+	}
+	q.renders = append(q.renders, jobID)
+	return nil
+}
+func (q *fakeBatchQueue) EnqueueBatchExport(jobID uuid.UUID) error {
+	q.exports = append(q.exports, jobID)
+	return nil
+}
+func (q *fakeBatchQueue) CancelJob(jobID uuid.UUID) error {
+	q.cancelled = append(q.cancelled, jobID)
+	return nil
+}
+
+// batchFixture is a BatchRenderService over in-memory repositories, a fake queue and a fake S3 bucket
+// holding the source video of one user.
+type batchFixture struct {
+	svc         *BatchRenderService
+	clips       *memClipRepo
+	styles      *memStyleRepo
+	jobs        *memJobRepo
+	users       *memUserRepo
+	transcripts *memTranscriptRepo
+	queue       *fakeBatchQueue
+	s3          *fakeS3
+	video       *domain.Video
+}
+
+func newBatchFixture(t *testing.T, credits int) *batchFixture {
+	t.Helper()
+	s3 := &fakeS3{objects: map[string]string{"videos/source.mp4": "source"}}
+	srv := httptest.NewServer(s3)
+	t.Cleanup(srv.Close)
+	storage, err := NewStorageService(S3Config{Endpoint: srv.URL, Region: "us-east-1", Bucket: "test", AccessKeyID: "key", SecretAccessKey: "secret", UsePathStyle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dur := 120.0
+	f := &batchFixture{
+		clips:       &memClipRepo{clips: map[string]*domain.Clip{}},
+		styles:      &memStyleRepo{styles: map[string]*domain.ClipStyle{}},
+		jobs:        &memJobRepo{jobs: map[string]*domain.ProcessingJob{}},
+		users:       &memUserRepo{user: &domain.User{ID: uuid.MustParse(testUserID), SubscriptionTier: "pro", CreditsRemaining: credits}},
+		transcripts: &memTranscriptRepo{byVideo: map[string]*domain.Transcription{}},
+		queue:       &fakeBatchQueue{},
+		s3:          s3,
+		video:       &domain.Video{ID: uuid.New(), UserID: uuid.MustParse(testUserID), StoragePath: "videos/source.mp4", DurationSeconds: &dur},
+	}
+	videos := &memVideoRepo{videos: map[string]*domain.Video{f.video.ID.String(): f.video}}
+	transcriptionSvc := NewTranscriptionService(f.transcripts, segmentRepo{f.transcripts}, noWordRepo{}, videos, nil)
+	renderingSvc := NewRenderingService(f.clips, f.styles, videos, noAnalysisRepo{}, transcriptionSvc, storage, nil, "", nil)
+	clipSvc := NewClipService(f.clips, f.styles, videos, storage, transcriptionSvc, f.jobs, nil, nil, f.users, memUsageLogRepo{}, renderingSvc)
+	f.svc = NewBatchRenderService(f.clips, f.styles, videos, f.jobs, f.users, memUsageLogRepo{}, clipSvc, storage, nil, renderingSvc)
+	f.svc.queue = f.queue
+	return f
+}
+
+func (f *batchFixture) addClip(name string, start, end float64) *domain.Clip {
+	c := &domain.Clip{ID: uuid.New(), VideoID: f.video.ID, UserID: f.video.UserID, Name: name, StartTime: start, EndTime: end, AspectRatio: "9:16", Resolution: "1080p", Status: "draft"}
+	f.clips.clips[c.ID.String()] = c
+	return c
+}
+
+func TestBatchRenderService_Start(t *testing.T) {
+	ctx := context.Background()
+	f := newBatchFixture(t, 5)
+	fresh := f.addClip("Fresh", 10, 20)
+	cached := f.addClip("Cached", 30, 40)
+	// An identical render of the second clip is already in storage.
+	p, err := f.svc.renderingSvc.plan(ctx, cached.ID.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.s3.put(p.outputKey(), "render")
+
+	parent, err := f.svc.Start(ctx, testUserID, f.video.ID.String(), []string{fresh.ID.String(), cached.ID.String()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent.JobType != "batch_render" || parent.Status != "processing" {
+		t.Errorf("parent = %s/%s, want a processing batch_render", parent.JobType, parent.Status)
+	}
+	if f.users.user.CreditsRemaining != 4 {
+		t.Errorf("credits = %d, want 4 (one charged, the cached render free)", f.users.user.CreditsRemaining)
+	}
+	var meta domain.BatchRenderMetadata
+	if err := json.Unmarshal(parent.Metadata, &meta); err != nil || len(meta.ChildJobIDs) != 2 {
+		t.Fatalf("metadata = %s, err %v", parent.Metadata, err)
+	}
+	if len(f.queue.renders) != 1 || f.queue.renders[0].String() != meta.ChildJobIDs[0] || len(f.queue.exports) != 0 {
+		t.Errorf("queued renders %v, exports %v; want only the first child rendered", f.queue.renders, f.queue.exports)
+	}
+	if j := f.jobs.jobs[meta.ChildJobIDs[1]]; j.Status != "completed" {
+		t.Errorf("cached child status = %s, want completed", j.Status)
+	}
+	if c := f.clips.clips[fresh.ID.String()]; c.Status != "rendering" {
+		t.Errorf("fresh clip status = %s, want rendering", c.Status)
+	}
+	if c := f.clips.clips[cached.ID.String()]; c.StoragePath == nil || *c.StoragePath != p.outputKey() {
+		t.Errorf("cached clip output = %v, want %s", c.StoragePath, p.outputKey())
+	}
+
+	poor := newBatchFixture(t, 0)
+	c := poor.addClip("Fresh", 10, 20)
+	if _, err := poor.svc.Start(ctx, testUserID, poor.video.ID.String(), []string{c.ID.String()}, nil); !errors.Is(err, domain.ErrInsufficientCredits) {
+		t.Errorf("no credits: err = %v, want ErrInsufficientCredits", err)
+	}
+	if len(poor.jobs.jobs) != 0 || len(poor.queue.renders) != 0 {
+		t.Error("no credits: jobs were created or queued")
+	}
+}
+
+func TestBatchRenderService_StartRefundsOnFailure(t *testing.T) {
+	ctx := context.Background()
+	f := newBatchFixture(t, 5)
+	f.queue.maxRenders = 1
+	a, b := f.addClip("A", 10, 20), f.addClip("B", 30, 40)
+
+	if _, err := f.svc.Start(ctx, testUserID, f.video.ID.String(), []string{a.ID.String(), b.ID.String()}, nil); err == nil {
+		t.Fatal("Start succeeded with the queue down")
+	}
+	if f.users.user.CreditsRemaining != 5 {
+		t.Errorf("credits = %d, want 5 (refunded)", f.users.user.CreditsRemaining)
+	}
+	for _, j := range f.jobs.jobs {
+		switch {
+		case j.JobType == "batch_render" && j.Status != "failed":
+			t.Errorf("parent status = %s, want failed", j.Status)
+		case j.JobType == "rendering" && j.Status != "cancelled":
+			t.Errorf("child %s status = %s, want cancelled", j.ID, j.Status)
+		}
+	}
+	if len(f.queue.renders) != 1 || len(f.queue.cancelled) != 2 {
+		t.Errorf("queued %d, cancelled %d; want the queued child and the unqueued one cancelled", len(f.queue.renders), len(f.queue.cancelled))
+	}
+}
+
+func TestBatchRenderService_ChildProgress(t *testing.T) {
+	ctx := context.Background()
+	f := newBatchFixture(t, 0)
+	parent := &domain.ProcessingJob{ID: uuid.New(), JobType: "batch_render", Status: "processing"}
+	childMeta, _ := json.Marshal(domain.RenderJobMetadata{ParentJobID: parent.ID.String()})
+	child := &domain.ProcessingJob{ID: uuid.New(), JobType: "rendering", Status: "processing", Progress: 50, Metadata: childMeta}
+	f.jobs.Create(ctx, child)
+	meta, _ := json.Marshal(domain.BatchRenderMetadata{ChildJobIDs: []string{child.ID.String()}})
+	parent.Metadata = meta
+	f.jobs.Create(ctx, parent)
+
+	// A single-clip batch moves with its only render.
+	if err := f.svc.ChildProgressed(ctx, child); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.jobs.jobs[parent.ID.String()].Progress; got != 45 {
+		t.Errorf("parent progress with the child at 50%% = %d, want 45", got)
+	}
+	if len(f.queue.exports) != 0 {
+		t.Error("export queued before the child finished")
+	}
+
+	child.Status, child.Progress = "completed", 100
+	f.jobs.Update(ctx, child)
+	if err := f.svc.ChildFinished(ctx, child); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.jobs.jobs[parent.ID.String()].Progress; got != 90 || len(f.queue.exports) != 1 {
+		t.Errorf("after the child finished: progress %d, %d exports queued; want 90 and the export", got, len(f.queue.exports))
+	}
+}
+
+func TestBatchRenderService_CancelChildren(t *testing.T) {
+	ctx := context.Background()
+	f := newBatchFixture(t, 0)
+	statuses := []string{"completed", "processing", "pending", "failed"}
+	meta := domain.BatchRenderMetadata{VideoID: f.video.ID.String()}
+	for _, status := range statuses {
+		child := &domain.ProcessingJob{ID: uuid.New(), JobType: "rendering", Status: status}
+		f.jobs.Create(ctx, child)
+		meta.ChildJobIDs = append(meta.ChildJobIDs, child.ID.String())
+	}
+	parent := &domain.ProcessingJob{ID: uuid.New(), JobType: "batch_render", Status: "cancelled"}
+	parent.Metadata, _ = json.Marshal(meta)
+	f.jobs.Create(ctx, parent)
+
+	if err := f.svc.CancelChildren(ctx, parent.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"completed", "cancelled", "cancelled", "failed"}
+	for i, id := range meta.ChildJobIDs {
+		if got := f.jobs.jobs[id].Status; got != want[i] {
+			t.Errorf("child %d (%s): status = %s, want %s", i, statuses[i], got, want[i])
+		}
+	}
+	if len(f.queue.cancelled) != 2 || f.queue.cancelled[0].String() != meta.ChildJobIDs[1] || f.queue.cancelled[1].String() != meta.ChildJobIDs[2] {
+		t.Errorf("cancelled tasks %v, want the processing and pending children", f.queue.cancelled)
+	}
+
+	if err := f.svc.CancelChildren(ctx, meta.ChildJobIDs[0]); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("not a batch: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestBatchRenderService_WriteZip(t *testing.T) {
+	ctx := context.Background()
+	f := newBatchFixture(t, 0)
+	c := f.addClip("Big Reveal!", 10, 16)
+	render := "renders/big/output.mp4"
+	c.StoragePath = &render
+	f.s3.put(render, "mp4 bytes")
+	f.styles.styles[c.ID.String()] = &domain.ClipStyle{ClipID: c.ID, RemoveSilences: true, CaptionMaxWords: 1}
+	tr := &domain.Transcription{ID: uuid.New(), VideoID: f.video.ID, Segments: []domain.TranscriptSegment{{ID: uuid.New(), StartTime: 10, EndTime: 16, Text: "before after", Words: []domain.TranscriptWord{
+		{Word: "before", StartTime: 10, EndTime: 11},
+		{Word: "after", StartTime: 15, EndTime: 16},
+	}}}}
+	f.transcripts.byVideo[f.video.ID.String()] = tr
+	child := &domain.ProcessingJob{ID: uuid.New(), JobType: "rendering", Status: "completed"}
+	f.jobs.Create(ctx, child)
+	meta := &domain.BatchRenderMetadata{VideoID: f.video.ID.String(), ClipIDs: []string{c.ID.String()}, ChildJobIDs: []string{child.ID.String()}}
+
+	zipPath := filepath.Join(t.TempDir(), "batch.zip")
+	rendered, err := f.svc.writeZip(ctx, zipPath, uuid.NewString(), meta)
+	if err != nil || rendered != 1 {
+		t.Fatalf("writeZip = %d, %v; want 1 render", rendered, err)
+	}
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	files := map[string]string{}
+	for _, zf := range zr.File {
+		rc, err := zf.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[zf.Name] = string(data)
+	}
+	if files["01-big-reveal.mp4"] != "mp4 bytes" {
+		t.Errorf("render missing from the ZIP: files %v", keysOf(files))
+	}
+	var manifest batchManifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil || len(manifest.Clips) != 1 {
+		t.Fatalf("manifest = %s, err %v", files["manifest.json"], err)
+	}
+	// The 4s pause is cut down to the padding on each side of it.
+	if d, want := manifest.Clips[0].Duration, 2+2*silencePadSec; d < want-1e-6 || d > want+1e-6 {
+		t.Errorf("manifest duration = %v, want the jump-cut length %v", d, want)
+	}
+	// Captions are retimed with the cut: "after" follows "before" instead of starting 5s in.
+	srt := files["01-big-reveal.srt"]
+	if !strings.Contains(srt, "after") || strings.Contains(srt, "00:00:05,000") || !strings.Contains(files["01-big-reveal.vtt"], "WEBVTT") {
+		t.Errorf("captions not timed on the rendered clip:\n%s", srt)
+	}
+}
+
+func keysOf(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+// captionRefHeight is the frame short side that ClipStyle.CaptionSize is expressed against.
+// Sizes scale with the short side so 9:16, 1:1 and 16:9 outputs keep the same relative text size.
+const captionRefHeight = 1080.0
+
+// Caption positions accepted in ClipStyle.CaptionPosition.
+const (
+	CaptionPositionTop    = "top"
+	CaptionPositionCenter = "center"
+	CaptionPositionBottom = "bottom"
+)
+
+// Caption animations accepted in ClipStyle.CaptionAnimation.
+const (
+	CaptionAnimationNone  = "none"
+	CaptionAnimationFade  = "fade"
+	CaptionAnimationPop   = "pop"
+	CaptionAnimationSlide = "slide-up"
+	// Word-level modes: need CaptionBlock.Words and follow the speaker word by word.
+	CaptionAnimationKaraoke   = "karaoke"   // colour sweeps across each word as it is spoken
+	CaptionAnimationHighlight = "highlight" // the active word switches to the highlight colour
+	CaptionAnimationWordPop   = "word-pop"  // the active word is highlighted and scaled up
+)
+
+// captionHighlightColor is the accent (#FFD400) used for the active word, in override-tag form (&HBBGGRR).
+const captionHighlightColor = "&H00D4FF"
+
+// captionWordPopScale is the ScaleX/ScaleY percentage applied to the active word in word-pop mode.
+const captionWordPopScale = 125
+
+// ToASS renders caption blocks as an Advanced SubStation script for a width x height output.
+// Every caption field of style is mapped onto the Default style: font, size, colour, background box,
+// position (alignment + margins) and entry animation. Block times must be relative to the output.
+func ToASS(blocks []CaptionBlock, style *domain.ClipStyle, width, height int) string {
+	return toASS(blocks, style, width, height, video.SafeArea{})
+}
+
+// toASS is ToASS keeping captions clear of the edges a platform covers with its interface: the
+// margins are widened wherever safe reaches further in than the default layout.
+func toASS(blocks []CaptionBlock, style *domain.ClipStyle, width, height int, safe video.SafeArea) string {
+	if style == nil {
+		style = &domain.ClipStyle{CaptionFont: "Inter", CaptionSize: 48, CaptionColor: "#FFFFFF", CaptionPosition: CaptionPositionBottom}
+	}
+	short := width
+	if height < short {
+		short = height
+	}
+	scale := float64(short) / captionRefHeight
+	size := style.CaptionSize
+	if size <= 0 {
+		size = 48
+	}
+	fontSize := int(float64(size)*scale + 0.5)
+	font := assFontName(style.CaptionFont)
+	if font == "" {
+		font = "Inter"
+	}
+
+	primary := assColor(style.CaptionColor, "&H00FFFFFF")
+	outline := "&H00000000"
+	back := "&H80000000"
+	borderStyle, outlineW, shadowW := 1, max(1, fontSize/16), 0
+	if style.CaptionBgColor != nil && *style.CaptionBgColor != "" {
+		// Opaque box: libass draws the box with the outline colour; back colour is its shadow.
+		borderStyle, outlineW, shadowW = 3, max(1, fontSize/6), 0
+		outline = assColor(*style.CaptionBgColor, "&H80000000")
+		back = outline
+	}
+	alignment, marginV := captionLayout(style.CaptionPosition, height)
+	switch alignment {
+	case 8:
+		marginV = max(marginV, int(safe.Top*float64(height)))
+	case 2:
+		marginV = max(marginV, int(safe.Bottom*float64(height)))
+	}
+	marginL := max(width*8/100, int(safe.Left*float64(width)))
+	marginR := max(width*8/100, int(safe.Right*float64(width)))
+
+	var b strings.Builder
+	b.WriteString("[Script Info]\n")
+	b.WriteString("ScriptType: v4.00+\n")
+	b.WriteString(fmt.Sprintf("PlayResX: %d\nPlayResY: %d\n", width, height))
+	b.WriteString("WrapStyle: 0\nScaledBorderAndShadow: yes\n\n")
+	b.WriteString("[V4+ Styles]\n")
+	b.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	b.WriteString(fmt.Sprintf("Style: Default,%s,%d,%s,%s,%s,%s,-1,0,0,0,100,100,0,0,%d,%d,%d,%d,%d,%d,%d,1\n\n",
+		font, fontSize, primary, primary, outline, back, borderStyle, outlineW, shadowW, alignment, marginL, marginR, marginV))
+	b.WriteString("[Events]\n")
+	b.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	anim := captionAnimationTag(style, width, height, alignment, marginV)
+	mode := ""
+	if style.CaptionAnimation != nil {
+		mode = *style.CaptionAnimation
+	}
+	for _, blk := range blocks {
+		if blk.EndTime <= blk.StartTime {
+			continue
+		}
+		if len(blk.Words) > 0 && writeWordEvents(&b, blk, mode, primary) {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,Default,,0,0,0,,%s%s\n",
+			formatASSTime(blk.StartTime), formatASSTime(blk.EndTime), anim, escapeASSText(blk.Text)))
+	}
+	return b.String()
+}
+
+// writeWordEvents writes the events for a word-level caption mode. It returns false when mode is not
+// word-level so the caller falls back to one plain event per block.
+func writeWordEvents(b *strings.Builder, blk CaptionBlock, mode, primary string) bool {
+	switch mode {
+	case CaptionAnimationKaraoke:
+		// \kf sweeps from SecondaryColour to PrimaryColour over the word's duration.
+		var text strings.Builder
+		text.WriteString(fmt.Sprintf(`{\1c%s&\2c&H%s&}`, captionHighlightColor, primary[4:]))
+		cursor := blk.StartTime
+		for i, w := range blk.Words {
+			if gap := w.StartTime - cursor; gap > 0 {
+				text.WriteString(fmt.Sprintf(`{\k%d}`, centiseconds(gap)))
+			}
+			if i > 0 {
+				text.WriteString(" ")
+			}
+			text.WriteString(fmt.Sprintf(`{\kf%d}%s`, centiseconds(w.EndTime-max(w.StartTime, cursor)), escapeASSText(w.Text)))
+			cursor = max(w.EndTime, cursor)
+		}
+		b.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,Default,,0,0,0,,%s\n",
+			formatASSTime(blk.StartTime), formatASSTime(blk.EndTime), text.String()))
+		return true
+	case CaptionAnimationHighlight, CaptionAnimationWordPop:
+		// One event per word: the whole line stays on screen and only the active word is restyled.
+		on := fmt.Sprintf(`{\c%s&}`, captionHighlightColor)
+		if mode == CaptionAnimationWordPop {
+			on = fmt.Sprintf(`{\c%s&\fscx%d\fscy%d}`, captionHighlightColor, captionWordPopScale, captionWordPopScale)
+		}
+		for i := range blk.Words {
+			start := blk.Words[i].StartTime
+			if i == 0 {
+				start = blk.StartTime
+			}
+			end := blk.EndTime
+			if i+1 < len(blk.Words) {
+				end = blk.Words[i+1].StartTime
+			}
+			if end <= start {
+				continue
+			}
+			var text strings.Builder
+			for j, w := range blk.Words {
+				if j > 0 {
+					text.WriteString(" ")
+				}
+				if j == i {
+					text.WriteString(on + escapeASSText(w.Text) + `{\r}`)
+				} else {
+					text.WriteString(escapeASSText(w.Text))
+				}
+			}
+			b.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,Default,,0,0,0,,%s\n",
+				formatASSTime(start), formatASSTime(end), text.String()))
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// centiseconds converts seconds to the whole centiseconds used by ASS karaoke tags.
+func centiseconds(sec float64) int {
+	if sec <= 0 {
+		return 0
+	}
+	return int(sec*100 + 0.5)
+}
+
+// captionAnimations are the accepted ClipStyle.CaptionAnimation values; empty means none.
+var captionAnimations = map[string]bool{
+	"": true, CaptionAnimationNone: true, CaptionAnimationFade: true, CaptionAnimationPop: true, CaptionAnimationSlide: true,
+	CaptionAnimationKaraoke: true, CaptionAnimationHighlight: true, CaptionAnimationWordPop: true,
+}
+
+// captionColorRe matches the "#RRGGBB" and "#RRGGBBAA" colours assColor understands.
+var captionColorRe = regexp.MustCompile(`^#[0-9A-Fa-f]{6}([0-9A-Fa-f]{2})?$`)
+
+// assFontName makes a font name safe for the Style line: commas would shift the following fields and
+// line breaks would start new script lines, so both (and every other control character) become spaces.
+func assFontName(s string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r == ',' || unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s))
+}
+
+// captionLayout maps a caption position onto an ASS numpad alignment and vertical margin.
+// Bottom captions sit above the platform UI overlays that cover the lower part of vertical video.
+func captionLayout(position string, height int) (alignment, marginV int) {
+	switch position {
+	case CaptionPositionTop:
+		return 8, height * 10 / 100
+	case CaptionPositionCenter, "middle":
+		return 5, 0
+	default:
+		return 2, height * 15 / 100
+	}
+}
+
+// captionAnimationTag returns the override tags that animate each caption's entry.
+func captionAnimationTag(style *domain.ClipStyle, width, height, alignment, marginV int) string {
+	if style.CaptionAnimation == nil {
+		return ""
+	}
+	switch *style.CaptionAnimation {
+	case CaptionAnimationFade:
+		return `{\fad(120,120)}`
+	case CaptionAnimationPop:
+		return `{\fscx80\fscy80\t(0,120,\fscx100\fscy100)}`
+	case CaptionAnimationSlide:
+		x := width / 2
+		var y int
+		switch alignment {
+		case 8:
+			y = marginV
+		case 5:
+			y = height / 2
+		default:
+			y = height - marginV
+		}
+		return fmt.Sprintf(`{\move(%d,%d,%d,%d,0,150)\fad(100,0)}`, x, y+height/40, x, y)
+	default:
+		return ""
+	}
+}
+
+// assColor converts "#RRGGBB" or "#RRGGBBAA" to ASS "&HAABBGGRR" (ASS alpha is inverted: 00 is opaque).
+func assColor(hex, fallback string) string {
+	h := strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(h) != 6 && len(h) != 8 {
+		return fallback
+	}
+	var r, g, bl, a uint8
+	a = 0xFF
+	if _, err := fmt.Sscanf(h[:6], "%02x%02x%02x", &r, &g, &bl); err != nil {
+		return fallback
+	}
+	if len(h) == 8 {
+		if _, err := fmt.Sscanf(h[6:], "%02x", &a); err != nil {
+			return fallback
+		}
+	}
+	return fmt.Sprintf("&H%02X%02X%02X%02X", 0xFF-a, bl, g, r)
+}
+
+// escapeASSText neutralises override braces and line breaks in caption text.
+func escapeASSText(s string) string {
+	return strings.NewReplacer("{", "(", "}", ")", "\\", "/", "\r\n", `\N`, "\n", `\N`).Replace(s)
+}
+
+// formatASSTime formats seconds as H:MM:SS.cc.
+func formatASSTime(sec float64) string {
+	if sec < 0 {
+		sec = 0
+	}
+	cs := int(sec*100 + 0.5)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, (cs/6000)%60, (cs/100)%60, cs%100)
+}
+
+// ShiftBlocks returns blocks with offset added to every time, dropping anything that ends before 0.
+func ShiftBlocks(blocks []CaptionBlock, offset float64) []CaptionBlock {
+	out := make([]CaptionBlock, 0, len(blocks))
+	for _, blk := range blocks {
+		blk.StartTime += offset
+		blk.EndTime += offset
+		if blk.EndTime <= 0 {
+			continue
+		}
+		if blk.StartTime < 0 {
+			blk.StartTime = 0
+		}
+		if len(blk.Words) > 0 {
+			words := make([]CaptionWord, len(blk.Words))
+			for i, w := range blk.Words {
+				w.StartTime = math.Max(0, w.StartTime+offset)
+				w.EndTime = math.Max(0, w.EndTime+offset)
+				words[i] = w
+			}
+			blk.Words = words
+		}
+		out = append(out, blk)
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+func TestAssColor(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"#FFFFFF", "&H00FFFFFF"},
+		{"#FF8000", "&H000080FF"},
+		{"#00000080", "&H7F000000"},
+		{"red", "&H00FFFFFF"},
+	}
+	for _, tt := range tests {
+		if got := assColor(tt.in, "&H00FFFFFF"); got != tt.want {
+			t.Errorf("assColor(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestToASS_StyleAndScaling(t *testing.T) {
+	bg := "#000000CC"
+	anim := CaptionAnimationFade
+	style := &domain.ClipStyle{
+		CaptionFont:      "Montserrat",
+		CaptionSize:      48,
+		CaptionColor:     "#FFFF00",
+		CaptionBgColor:   &bg,
+		CaptionPosition:  CaptionPositionTop,
+		CaptionAnimation: &anim,
+	}
+	blocks := []CaptionBlock{{StartTime: 1.25, EndTime: 2.5, Text: "hello {world}"}}
+
+	vertical := ToASS(blocks, style, 1080, 1920)
+	for _, want := range []string{
+		"PlayResX: 1080\nPlayResY: 1920",
+		"Style: Default,Montserrat,48,&H0000FFFF,&H0000FFFF,&H33000000,&H33000000,-1,0,0,0,100,100,0,0,3,",
+		",8,86,86,192,1",
+		`Dialogue: 0,0:00:01.25,0:00:02.50,Default,,0,0,0,,{\fad(120,120)}hello (world)`,
+	} {
+		if !strings.Contains(vertical, want) {
+			t.Errorf("9:16 script missing %q:\n%s", want, vertical)
+		}
+	}
+
+	// 16:9 at 1280x720: font scales with the short side.
+	landscape := ToASS(blocks, style, 1280, 720)
+	if !strings.Contains(landscape, "Style: Default,Montserrat,32,") {
+		t.Errorf("720p script should scale font to 32:\n%s", landscape)
+	}
+}
+
+func TestToASS_FontName(t *testing.T) {
+	style := &domain.ClipStyle{CaptionFont: "Inter,Bold\n[Events]\r\nDialogue: 0\x00", CaptionSize: 48}
+	script := ToASS(nil, style, 1080, 1920)
+	if !strings.Contains(script, "Style: Default,Inter Bold [Events]  Dialogue: 0,48,") {
+		t.Errorf("font name not sanitised:\n%s", script)
+	}
+	if strings.Count(script, "[Events]\n") != 1 {
+		t.Errorf("font name injected script lines:\n%s", script)
+	}
+}
+
+func TestToASS_SafeArea(t *testing.T) {
+	blocks := []CaptionBlock{{StartTime: 0, EndTime: 1, Text: "hi"}}
+	style := &domain.ClipStyle{CaptionSize: 48, CaptionColor: "#FFFFFF", CaptionPosition: CaptionPositionBottom}
+	safe := video.SafeArea{Top: 0.14, Bottom: 0.35, Left: 0.06, Right: 0.15}
+
+	// Bottom captions rise above the covered 35%; the right margin clears the side buttons, the left
+	// keeps the wider default.
+	if got := toASS(blocks, style, 1080, 1920, safe); !strings.Contains(got, ",2,86,162,672,1") {
+		t.Errorf("bottom script margins should be 86,162,672:\n%s", got)
+	}
+	// Within the default margins nothing moves.
+	if got, want := toASS(blocks, style, 1080, 1920, video.SafeArea{Bottom: 0.1}), ToASS(blocks, style, 1080, 1920); got != want {
+		t.Errorf("small safe area changed the script:\n%s\nwant\n%s", got, want)
+	}
+	style.CaptionPosition = CaptionPositionTop
+	if got := toASS(blocks, style, 1080, 1920, safe); !strings.Contains(got, ",8,86,162,268,1") {
+		t.Errorf("top script margins should be 86,162,268:\n%s", got)
+	}
+}
+
+func TestShiftBlocks(t *testing.T) {
+	got := ShiftBlocks([]CaptionBlock{
+		{StartTime: 9, EndTime: 10, Text: "before"},
+		{StartTime: 9.5, EndTime: 11, Text: "straddles"},
+		{StartTime: 12, EndTime: 13, Text: "inside"},
+	}, -10)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(got))
+	}
+	if got[0].StartTime != 0 || got[0].EndTime != 1 || got[1].StartTime != 2 {
+		t.Errorf("unexpected shifted times: %+v", got)
+	}
+}
+
+func TestBlocksFromSegments_WordTimings(t *testing.T) {
+	segments := []domain.TranscriptSegment{{
+		StartTime: 10, EndTime: 16, Text: "one two three four",
+		Words: []domain.TranscriptWord{
+			{Word: "one", StartTime: 10.0, EndTime: 10.4},
+			{Word: "two", StartTime: 10.5, EndTime: 10.9},
+			{Word: "three", StartTime: 12.5, EndTime: 13.0}, // long pause starts a new block
+			{Word: "four", StartTime: 13.1, EndTime: 13.6},
+		},
+	}}
+	got := BlocksFromSegments(segments, &domain.ClipStyle{CaptionMaxWords: 3}, 0, 0)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 blocks, got %+v", got)
+	}
+	if got[0].Text != "one two" || got[0].StartTime != 10.0 || got[0].EndTime != 10.9 {
+		t.Errorf("unexpected first block: %+v", got[0])
+	}
+	if got[1].Text != "three four" || got[1].StartTime != 12.5 || len(got[1].Words) != 2 {
+		t.Errorf("unexpected second block: %+v", got[1])
+	}
+
+	// Without word data the segment is spread evenly and still carries per-word times.
+	segments[0].Words = nil
+	even := BlocksFromSegments(segments, &domain.ClipStyle{CaptionMaxWords: 2}, 0, 0)
+	if len(even) != 2 || even[1].StartTime != 13 || len(even[1].Words) != 2 || even[1].Words[1].StartTime != 14.5 {
+		t.Errorf("unexpected evenly spread blocks: %+v", even)
+	}
+}
+
+func TestToASS_WordModes(t *testing.T) {
+	blocks := []CaptionBlock{{StartTime: 1, EndTime: 2, Text: "hi there", Words: []CaptionWord{
+		{Text: "hi", StartTime: 1, EndTime: 1.3},
+		{Text: "there", StartTime: 1.5, EndTime: 2},
+	}}}
+	mode := CaptionAnimationKaraoke
+	style := &domain.ClipStyle{CaptionSize: 48, CaptionColor: "#FFFFFF", CaptionAnimation: &mode}
+	karaoke := ToASS(blocks, style, 1080, 1920)
+	if want := `Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\1c&H00D4FF&\2c&HFFFFFF&}{\kf30}hi{\k20} {\kf50}there`; !strings.Contains(karaoke, want) {
+		t.Errorf("karaoke script missing %q:\n%s", want, karaoke)
+	}
+
+	mode = CaptionAnimationWordPop
+	pop := ToASS(blocks, style, 1080, 1920)
+	for _, want := range []string{
+		`Dialogue: 0,0:00:01.00,0:00:01.50,Default,,0,0,0,,{\c&H00D4FF&\fscx125\fscy125}hi{\r} there`,
+		`Dialogue: 0,0:00:01.50,0:00:02.00,Default,,0,0,0,,hi {\c&H00D4FF&\fscx125\fscy125}there{\r}`,
+	} {
+		if !strings.Contains(pop, want) {
+			t.Errorf("word-pop script missing %q:\n%s", want, pop)
+		}
+	}
+}
@@ -9,16 +9,30 @@ import (
 
 // CaptionBlock is one caption line with time range.
 type CaptionBlock struct {
+	StartTime float64       `json:"start_time"`
+	EndTime   float64       `json:"end_time"`
+	Text      string        `json:"text"`
+	Words     []CaptionWord `json:"words,omitempty"`
+}
+
+// CaptionWord is one word of a caption block with its own timing (used for karaoke/highlight).
+type CaptionWord struct {
+	Text      string  `json:"text"`
 	StartTime float64 `json:"start_time"`
 	EndTime   float64 `json:"end_time"`
-	Text      string  `json:"text"`
 }
 
-const maxCaptionDurationSec = 7.0
+const (
+	maxCaptionDurationSec = 7.0
+	// captionPauseSec starts a new caption when the gap between two words is longer than this.
+	captionPauseSec = 1.0
+)
 
 // BlocksFromSegments builds caption blocks from transcript segments and style.
 // clipStart/clipEnd optionally filter to a time range (use 0,0 for no filter).
 // Uses CaptionMaxWords to split long segments; respects max duration per caption.
+// Real per-word timings are used when a segment has Words; otherwise the segment's duration
+// is spread evenly across its words.
 func BlocksFromSegments(segments []domain.TranscriptSegment, style *domain.ClipStyle, clipStart, clipEnd float64) []CaptionBlock {
 	if style == nil {
 		style = &domain.ClipStyle{CaptionMaxWords: 3}
@@ -32,6 +46,10 @@ func BlocksFromSegments(segments []domain.TranscriptSegment, style *domain.ClipS
 		if clipEnd > 0 && (seg.EndTime < clipStart || seg.StartTime > clipEnd) {
 			continue
 		}
+		if len(seg.Words) > 0 {
+			blocks = append(blocks, blocksFromWords(seg.Words, maxWords, clipStart, clipEnd)...)
+			continue
+		}
 		words := strings.Fields(seg.Text)
 		if len(words) == 0 {
 			continue
@@ -61,12 +79,88 @@ func BlocksFromSegments(segments []domain.TranscriptSegment, style *domain.ClipS
 			if endTime-startTime > maxCaptionDurationSec && len(chunk) > 1 {
 				endTime = startTime + maxCaptionDurationSec
 			}
-			blocks = append(blocks, CaptionBlock{StartTime: startTime, EndTime: endTime, Text: text})
+			cw := make([]CaptionWord, 0, len(chunk))
+			for j, w := range chunk {
+				ws := seg.StartTime + float64(i+j)*wordDuration
+				cw = append(cw, CaptionWord{Text: w, StartTime: ws, EndTime: ws + wordDuration})
+			}
+			blocks = append(blocks, CaptionBlock{StartTime: startTime, EndTime: endTime, Text: text, Words: clampWords(cw, startTime, endTime)})
+		}
+	}
+	return blocks
+}
+
+// blocksFromWords groups timed words into blocks of at most maxWords, breaking early on long pauses
+// or when a block would exceed maxCaptionDurationSec. Words outside [clipStart, clipEnd] are dropped.
+func blocksFromWords(words []domain.TranscriptWord, maxWords int, clipStart, clipEnd float64) []CaptionBlock {
+	var blocks []CaptionBlock
+	var cur []CaptionWord
+	flush := func() {
+		if len(cur) == 0 {
+			return
+		}
+		texts := make([]string, len(cur))
+		for i, w := range cur {
+			texts[i] = w.Text
+		}
+		blocks = append(blocks, CaptionBlock{
+			StartTime: cur[0].StartTime,
+			EndTime:   cur[len(cur)-1].EndTime,
+			Text:      strings.Join(texts, " "),
+			Words:     cur,
+		})
+		cur = nil
+	}
+	for _, w := range words {
+		text := strings.TrimSpace(w.Word)
+		if text == "" {
+			continue
+		}
+		start, end := w.StartTime, w.EndTime
+		if end < start {
+			end = start
+		}
+		if clipEnd > 0 {
+			mid := (start + end) / 2
+			if mid < clipStart || mid > clipEnd {
+				continue
+			}
+			if start < clipStart {
+				start = clipStart
+			}
+			if end > clipEnd {
+				end = clipEnd
+			}
+		}
+		if n := len(cur); n > 0 {
+			if n >= maxWords || start-cur[n-1].EndTime > captionPauseSec || end-cur[0].StartTime > maxCaptionDurationSec {
+				flush()
+			}
 		}
+		cur = append(cur, CaptionWord{Text: text, StartTime: start, EndTime: end})
 	}
+	flush()
 	return blocks
 }
 
+// clampWords limits word times to [start, end], dropping words that fall entirely outside.
+func clampWords(words []CaptionWord, start, end float64) []CaptionWord {
+	out := words[:0]
+	for _, w := range words {
+		if w.EndTime <= start || w.StartTime >= end {
+			continue
+		}
+		if w.StartTime < start {
+			w.StartTime = start
+		}
+		if w.EndTime > end {
+			w.EndTime = end
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
 // ToSRT returns SRT-formatted string (sequence, time range, text).
 func ToSRT(blocks []CaptionBlock) string {
 	var b strings.Builder
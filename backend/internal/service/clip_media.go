@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+const (
+	// previewMaxSec is the longest animated preview; it is taken from the middle of the clip.
+	previewMaxSec = 3.0
+	previewWidth  = 320
+	previewFPS    = 12
+
+	clipThumbnailName = "thumbnail.jpg"
+	clipPreviewName   = "preview.webp"
+)
+
+// GenerateClipCovers builds a sharp still thumbnail and a looping animated WebP preview from the
+// clip video at localPath, uploads both next to storageKey (the clip's video) and records their keys
+// on c. c is not persisted; callers save it together with their own changes.
+func GenerateClipCovers(ctx context.Context, storage *StorageService, c *domain.Clip, localPath, storageKey string, duration float64) error {
+	dir := filepath.Dir(localPath)
+	keyDir := path.Dir(filepath.ToSlash(storageKey))
+
+	thumbPath := filepath.Join(dir, c.ID.String()+"_thumbnail.jpg")
+	if err := video.SharpestFrame(ctx, localPath, thumbPath, duration); err != nil {
+		return err
+	}
+	defer os.Remove(thumbPath)
+	thumbKey := path.Join(keyDir, clipThumbnailName)
+	if err := uploadFile(ctx, storage, thumbPath, thumbKey, "image/jpeg"); err != nil {
+		return fmt.Errorf("upload thumbnail: %w", err)
+	}
+	c.ThumbnailURL = &thumbKey
+
+	previewDur := duration
+	if previewDur > previewMaxSec {
+		previewDur = previewMaxSec
+	}
+	previewPath := filepath.Join(dir, c.ID.String()+"_preview.webp")
+	opts := video.PreviewOptions{Start: (duration - previewDur) / 2, Duration: previewDur, Width: previewWidth, FPS: previewFPS}
+	if err := video.AnimatedPreview(ctx, localPath, previewPath, opts); err != nil {
+		return err
+	}
+	defer os.Remove(previewPath)
+	previewKey := path.Join(keyDir, clipPreviewName)
+	if err := uploadFile(ctx, storage, previewPath, previewKey, "image/webp"); err != nil {
+		return fmt.Errorf("upload preview: %w", err)
+	}
+	c.PreviewURL = &previewKey
+	return nil
+}
+
+// attachClipCovers records on c the covers previously generated next to storageKey, keeping c's
+// current values for any that are missing.
+func attachClipCovers(ctx context.Context, storage *StorageService, c *domain.Clip, storageKey string) {
+	keyDir := path.Dir(storageKey)
+	if key := path.Join(keyDir, clipThumbnailName); objectExists(ctx, storage, key) {
+		c.ThumbnailURL = &key
+	}
+	if key := path.Join(keyDir, clipPreviewName); objectExists(ctx, storage, key) {
+		c.PreviewURL = &key
+	}
+}
+
+func objectExists(ctx context.Context, storage *StorageService, key string) bool {
+	_, err := storage.Head(ctx, key)
+	return err == nil
+}
+
+func uploadFile(ctx context.Context, storage *StorageService, localPath, key, contentType string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return storage.Upload(ctx, key, f, contentType)
+}
@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"reelcut/internal/domain"
 	"reelcut/internal/queue"
 	"reelcut/internal/repository"
+	"reelcut/internal/video"
 
 	"github.com/google/uuid"
 )
@@ -15,39 +18,45 @@ type ClipService struct {
 	clipRepo         repository.ClipRepository
 	clipStyleRepo    repository.ClipStyleRepository
 	videoRepo        repository.VideoRepository
+	storage          *StorageService
 	transcriptionSvc *TranscriptionService
 	jobRepo          repository.ProcessingJobRepository
 	queue            *queue.QueueClient
 	templateRepo     repository.TemplateRepository
 	userRepo         repository.UserRepository
 	usageLogRepo     repository.UsageLogRepository
+	renderingSvc     *RenderingService
 }
 
 func NewClipService(
 	clipRepo repository.ClipRepository,
 	clipStyleRepo repository.ClipStyleRepository,
 	videoRepo repository.VideoRepository,
+	storage *StorageService,
 	transcriptionSvc *TranscriptionService,
 	jobRepo repository.ProcessingJobRepository,
 	queue *queue.QueueClient,
 	templateRepo repository.TemplateRepository,
 	userRepo repository.UserRepository,
 	usageLogRepo repository.UsageLogRepository,
+	renderingSvc *RenderingService,
 ) *ClipService {
 	return &ClipService{
 		clipRepo:         clipRepo,
 		clipStyleRepo:    clipStyleRepo,
 		videoRepo:        videoRepo,
+		storage:          storage,
 		transcriptionSvc: transcriptionSvc,
 		jobRepo:          jobRepo,
 		queue:            queue,
 		templateRepo:     templateRepo,
 		userRepo:         userRepo,
 		usageLogRepo:     usageLogRepo,
+		renderingSvc:     renderingSvc,
 	}
 }
 
-func (s *ClipService) Create(ctx context.Context, userID uuid.UUID, videoID, name string, startTime, endTime float64, aspectRatio string, viralityScore *float64, isAISuggested bool) (*domain.Clip, error) {
+func (s *ClipService) Create(ctx context.Context, userID uuid.UUID, videoID, name string, startTime, endTime float64, aspectRatio, resolution string, viralityScore *float64, isAISuggested bool) (*domain.Clip, error) {
 	vid, err := uuid.Parse(videoID)
 	if err != nil {
 		return nil, domain.ErrValidation
@@ -61,7 +70,16 @@ func (s *ClipService) Create(ctx context.Context, userID uuid.UUID, videoID, nam
 		return nil, domain.ErrValidation
 	}
 	if aspectRatio == "" {
-		aspectRatio = "9:16"
+		aspectRatio = video.DefaultAspectRatio
+	}
+	if resolution == "" {
+		resolution = video.DefaultResolution
+	}
+	if err := validateFormat(aspectRatio, resolution); err != nil {
+		return nil, err
+	}
+	if err := checkResolution(ctx, s.userRepo, userID.String(), resolution); err != nil {
+		return nil, err
 	}
 	c := &domain.Clip{
 		ID:              uuid.New(),
@@ -72,6 +90,8 @@ func (s *ClipService) Create(ctx context.Context, userID uuid.UUID, videoID, nam
 		EndTime:         endTime,
 		DurationSeconds: &dur,
 		AspectRatio:     aspectRatio,
+		Resolution:      resolution,
+		FitMode:         video.FitCrop,
 		ViralityScore:   viralityScore,
 		Status:          "draft",
 		IsAISuggested:   isAISuggested,
@@ -92,6 +112,7 @@ func (s *ClipService) Create(ctx context.Context, userID uuid.UUID, videoID, nam
 		BrandWatermarkOpacity: 0.8,
 		BackgroundMusicVolume: 0.3,
 		OriginalAudioVolume:   1,
+		SilenceMinDuration:    defaultSilenceMinDuration,
 	}
 	if err := s.clipStyleRepo.Create(ctx, style); err != nil {
 		return nil, err
@@ -121,9 +142,67 @@ func (s *ClipService) List(ctx context.Context, userID string, videoID *string,
 }
 
 func (s *ClipService) Update(ctx context.Context, c *domain.Clip) error {
+	if c.Resolution == "" {
+		c.Resolution = video.DefaultResolution
+	}
+	if err := validateFormat(c.AspectRatio, c.Resolution); err != nil {
+		return err
+	}
+	if err := validateFit(c); err != nil {
+		return err
+	}
+	if c.Layout != nil {
+		if err := normalizeLayout(c.Layout); err != nil {
+			return err
+		}
+	}
+	if len(c.Segments) > 0 {
+		// The clip's own range is its first segment, so an edited range is checked like one.
+		c.Segments[0].VideoID, c.Segments[0].StartTime, c.Segments[0].EndTime = c.VideoID, c.StartTime, c.EndTime
+		v, err := s.videoRepo.GetByID(ctx, c.VideoID.String())
+		if err != nil || v == nil {
+			return domain.ErrNotFound
+		}
+		if err := validateSpan("segments[0]", c.Segments[0], v); err != nil {
+			return err
+		}
+		dur := spansDuration(c.Segments)
+		c.DurationSeconds = &dur
+	}
+	// Only a change of resolution is checked against the plan, so clips made before a downgrade stay editable.
+	if prev, err := s.clipRepo.GetByID(ctx, c.ID.String()); err == nil && prev != nil && prev.Resolution != c.Resolution {
+		if err := checkResolution(ctx, s.userRepo, c.UserID.String(), c.Resolution); err != nil {
+			return err
+		}
+	}
 	return s.clipRepo.Update(ctx, c)
 }
 
+// validateFormat checks an aspect ratio (any W:H, see video.ParseAspectRatio) and resolution tier.
+func validateFormat(aspectRatio, resolution string) error {
+	if _, _, err := video.ParseAspectRatio(aspectRatio); err != nil {
+		return &domain.ValidationError{Field: "aspect_ratio", Message: "must be W:H with whole numbers no more than 3:1 either way, e.g. 9:16 or 4:5"}
+	}
+	if _, ok := video.Resolutions[resolution]; !ok {
+		return &domain.ValidationError{Field: "resolution", Message: "must be 720p, 1080p, 1440p or 4k"}
+	}
+	return nil
+}
+
+// validateFit checks the clip's fit mode and colour, defaulting an empty mode to crop.
+func validateFit(c *domain.Clip) error {
+	if c.FitMode == "" {
+		c.FitMode = video.FitCrop
+	}
+	if !video.FitModes[c.FitMode] {
+		return &domain.ValidationError{Field: "fit_mode", Message: "must be crop, blur or color"}
+	}
+	if c.FitColor != nil && !video.ValidFitColor(*c.FitColor) {
+		return &domain.ValidationError{Field: "fit_color", Message: "must be a #RRGGBB colour"}
+	}
+	return nil
+}
+
 func (s *ClipService) Delete(ctx context.Context, clipID, userID string) error {
 	c, err := s.clipRepo.GetByID(ctx, clipID)
 	if err != nil || c == nil || c.UserID.String() != userID {
@@ -138,7 +217,54 @@ func (s *ClipService) Duplicate(ctx context.Context, clipID, userID string) (*do
 		return nil, err
 	}
 	newName := c.Name + " (copy)"
-	return s.Create(ctx, c.UserID, c.VideoID.String(), newName, c.StartTime, c.EndTime, c.AspectRatio, c.ViralityScore, false)
+	dup, err := s.Create(ctx, c.UserID, c.VideoID.String(), newName, c.StartTime, c.EndTime, c.AspectRatio, c.Resolution, c.ViralityScore, false)
+	if err != nil {
+		return nil, err
+	}
+	if c.FitMode != dup.FitMode || c.FitColor != nil || len(c.Reframe) > 0 || c.Layout != nil || len(c.Segments) > 0 {
+		dup.FitMode, dup.FitColor, dup.Reframe, dup.Layout = c.FitMode, c.FitColor, c.Reframe, c.Layout
+		dup.Segments, dup.DurationSeconds = c.Segments, c.DurationSeconds
+		if err := s.clipRepo.Update(ctx, dup); err != nil {
+			return nil, err
+		}
+	}
+	return dup, nil
+}
+
+// GetReframe returns the clip's reframe keyframes, sorted by time, and the speaker-following keyframes
+// renders use when there are none (see RenderingService.AutoReframe).
+func (s *ClipService) GetReframe(ctx context.Context, clipID, userID string) (keys, auto domain.Reframe, err error) {
+	c, err := s.clipRepo.GetByID(ctx, clipID)
+	if err != nil || c == nil || c.UserID.String() != userID {
+		return nil, nil, domain.ErrNotFound
+	}
+	keys, auto = c.Reframe, s.renderingSvc.AutoReframe(ctx, c)
+	if keys == nil {
+		keys = domain.Reframe{}
+	}
+	if auto == nil {
+		auto = domain.Reframe{}
+	}
+	return keys, auto, nil
+}
+
+// UpdateReframe replaces the clip's reframe keyframes; an empty list restores the centre crop.
+func (s *ClipService) UpdateReframe(ctx context.Context, clipID, userID string, keys domain.Reframe) (domain.Reframe, error) {
+	c, err := s.clipRepo.GetByID(ctx, clipID)
+	if err != nil || c == nil || c.UserID.String() != userID {
+		return nil, domain.ErrNotFound
+	}
+	if keys, err = normalizeReframe(keys, spansDuration(clipSpans(c))); err != nil {
+		return nil, err
+	}
+	c.Reframe = keys
+	if len(keys) == 0 {
+		c.Reframe = nil
+	}
+	if err := s.clipRepo.Update(ctx, c); err != nil {
+		return nil, err
+	}
+	return keys, nil
 }
 
 func (s *ClipService) GetStyle(ctx context.Context, clipID, userID string) (*domain.ClipStyle, error) {
@@ -149,11 +275,27 @@ func (s *ClipService) GetStyle(ctx context.Context, clipID, userID string) (*dom
 	return s.clipStyleRepo.GetByClipID(ctx, clipID)
 }
 
-func (s *ClipService) UpdateStyle(ctx context.Context, clipID, userID string, updates *domain.ClipStyle) error {
+// StyleUpdate is a partial clip style update: only the fields that are set change. Switches that
+// default to off are pointers, so leaving them out of an update keeps their current value.
+type StyleUpdate struct {
+	domain.ClipStyle
+	RemoveSilences *bool `json:"remove_silences"`
+	RemoveFillers  *bool `json:"remove_fillers"`
+}
+
+func (s *ClipService) UpdateStyle(ctx context.Context, clipID, userID string, updates *StyleUpdate) error {
 	style, err := s.GetStyle(ctx, clipID, userID)
 	if err != nil {
 		return err
 	}
+	if err := applyStyleUpdate(style, updates, userID); err != nil {
+		return err
+	}
+	return s.clipStyleRepo.Update(ctx, style)
+}
+
+// applyStyleUpdate validates updates to userID's style and merges them into it.
+func applyStyleUpdate(style *domain.ClipStyle, updates *StyleUpdate, userID string) error {
 	if updates.CaptionEnabled != style.CaptionEnabled {
 		style.CaptionEnabled = updates.CaptionEnabled
 	}
@@ -164,18 +306,106 @@ func (s *ClipService) UpdateStyle(ctx context.Context, clipID, userID string, up
 		style.CaptionSize = updates.CaptionSize
 	}
 	if updates.CaptionColor != "" {
+		if !captionColorRe.MatchString(updates.CaptionColor) {
+			return &domain.ValidationError{Field: "caption_color", Message: "must be #RRGGBB or #RRGGBBAA"}
+		}
 		style.CaptionColor = updates.CaptionColor
 	}
 	if updates.CaptionPosition != "" {
+		switch updates.CaptionPosition {
+		case CaptionPositionTop, CaptionPositionCenter, CaptionPositionBottom:
+		default:
+			return &domain.ValidationError{Field: "caption_position", Message: "must be top, center or bottom"}
+		}
 		style.CaptionPosition = updates.CaptionPosition
 	}
+	if updates.CaptionBgColor != nil {
+		// An empty colour removes the background box.
+		if *updates.CaptionBgColor != "" && !captionColorRe.MatchString(*updates.CaptionBgColor) {
+			return &domain.ValidationError{Field: "caption_bg_color", Message: "must be #RRGGBB or #RRGGBBAA"}
+		}
+		style.CaptionBgColor = updates.CaptionBgColor
+	}
+	if updates.CaptionAnimation != nil {
+		if !captionAnimations[*updates.CaptionAnimation] {
+			return &domain.ValidationError{Field: "caption_animation", Message: "must be none, fade, pop, slide-up, karaoke, highlight or word-pop"}
+		}
+		style.CaptionAnimation = updates.CaptionAnimation
+	}
+	if updates.BrandLogoURL != nil {
+		// An empty key removes the logo.
+		style.BrandLogoURL = nil
+		if *updates.BrandLogoURL != "" {
+			if err := validateStyleAsset(userID, styleAssetLogo, *updates.BrandLogoURL); err != nil {
+				return err
+			}
+			style.BrandLogoURL = updates.BrandLogoURL
+		}
+	}
+	if updates.BrandLogoPosition != nil {
+		if !video.OverlayPositions[*updates.BrandLogoPosition] {
+			return &domain.ValidationError{Field: "brand_logo_position", Message: "must be top-left, top-right, bottom-left, bottom-right or center"}
+		}
+		style.BrandLogoPosition = updates.BrandLogoPosition
+	}
+	if updates.BrandLogoScale != 0 {
+		if updates.BrandLogoScale < 0 || updates.BrandLogoScale > maxLogoScale {
+			return &domain.ValidationError{Field: "brand_logo_scale", Message: "must be greater than 0 and at most 2"}
+		}
+		style.BrandLogoScale = updates.BrandLogoScale
+	}
+	if updates.BrandWatermarkOpacity > 0 {
+		if updates.BrandWatermarkOpacity > 1 {
+			return &domain.ValidationError{Field: "brand_watermark_opacity", Message: "must be between 0 and 1"}
+		}
+		style.BrandWatermarkOpacity = updates.BrandWatermarkOpacity
+	}
+	if updates.BackgroundMusicURL != nil {
+		// An empty key removes the music.
+		style.BackgroundMusicURL = nil
+		if *updates.BackgroundMusicURL != "" {
+			if err := validateStyleAsset(userID, styleAssetMusic, *updates.BackgroundMusicURL); err != nil {
+				return err
+			}
+			style.BackgroundMusicURL = updates.BackgroundMusicURL
+		}
+	}
+	if updates.TransitionEffect != nil {
+		if err := validateTransition(*updates.TransitionEffect); err != nil {
+			return err
+		}
+		style.TransitionEffect = updates.TransitionEffect
+		if *updates.TransitionEffect == "" || *updates.TransitionEffect == "none" {
+			style.TransitionEffect = nil
+		}
+	}
 	if updates.BackgroundMusicVolume >= 0 {
 		style.BackgroundMusicVolume = updates.BackgroundMusicVolume
 	}
 	if updates.OriginalAudioVolume >= 0 {
 		style.OriginalAudioVolume = updates.OriginalAudioVolume
 	}
-	return s.clipStyleRepo.Update(ctx, style)
+	if updates.RemoveSilences != nil {
+		style.RemoveSilences = *updates.RemoveSilences
+	}
+	if updates.RemoveFillers != nil {
+		style.RemoveFillers = *updates.RemoveFillers
+	}
+	if updates.SilenceMinDuration > 0 {
+		if updates.SilenceMinDuration < minSilenceDuration || updates.SilenceMinDuration > maxSilenceDuration {
+			return &domain.ValidationError{Field: "silence_min_duration", Message: fmt.Sprintf("must be between %g and %g seconds", minSilenceDuration, maxSilenceDuration)}
+		}
+		style.SilenceMinDuration = updates.SilenceMinDuration
+	}
+	return nil
+}
+
+// EditList previews the ranges a render of the clip will cut (silences, filler words, stutters).
+func (s *ClipService) EditList(ctx context.Context, clipID, userID string) (*EditList, error) {
+	if _, err := s.GetByID(ctx, clipID, userID); err != nil {
+		return nil, err
+	}
+	return s.renderingSvc.EditList(ctx, clipID)
 }
 
 func (s *ClipService) GetCaptionsSRT(ctx context.Context, clipID, userID string) (string, error) {
@@ -183,11 +413,10 @@ func (s *ClipService) GetCaptionsSRT(ctx context.Context, clipID, userID string)
 	if err != nil {
 		return "", err
 	}
-	t, err := s.transcriptionSvc.GetByVideoID(ctx, c.VideoID.String())
-	if err != nil || t == nil {
-		return "", domain.ErrNotFound
+	blocks, err := s.captionBlocks(ctx, c, false)
+	if err != nil {
+		return "", err
 	}
-	blocks := BlocksFromSegments(t.Segments, c.Style, c.StartTime, c.EndTime)
 	return ToSRT(blocks), nil
 }
 
@@ -196,24 +425,68 @@ func (s *ClipService) GetCaptionsVTT(ctx context.Context, clipID, userID string)
 	if err != nil {
 		return "", err
 	}
-	t, err := s.transcriptionSvc.GetByVideoID(ctx, c.VideoID.String())
-	if err != nil || t == nil {
-		return "", domain.ErrNotFound
+	blocks, err := s.captionBlocks(ctx, c, false)
+	if err != nil {
+		return "", err
 	}
-	blocks := BlocksFromSegments(t.Segments, c.Style, c.StartTime, c.EndTime)
 	return ToVTT(blocks), nil
 }
 
-func (s *ClipService) StartRender(ctx context.Context, clipID, userID string) (jobID string, err error) {
+// captionBlocks returns the clip's captions. A single-range clip with nothing cut keeps source timing
+// unless clipTimeline is set; otherwise captions are timed on the rendered clip: retimed around its
+// jump cuts (see cutSpan) and, for a multi-segment clip, stitched across the segments.
+func (s *ClipService) captionBlocks(ctx context.Context, c *domain.Clip, clipTimeline bool) ([]CaptionBlock, error) {
+	spans := clipSpans(c)
+	var parts [][]CaptionBlock
+	var lengths []float64
+	found := false
+	for _, sp := range spans {
+		t, err := s.transcriptionSvc.GetByVideoID(ctx, sp.VideoID.String())
+		if err != nil {
+			t = nil
+		}
+		cut := cutSpan(t, c.Style, sp.StartTime, sp.EndTime, s.renderingSvc.fillers)
+		var blocks []CaptionBlock
+		if t != nil {
+			found = true
+			blocks = BlocksFromSegments(t.Segments, c.Style, sp.StartTime, sp.EndTime)
+			if len(spans) == 1 && cut.keep == nil && !clipTimeline {
+				return blocks, nil
+			}
+			blocks = ShiftBlocks(blocks, -sp.StartTime)
+			if cut.keep != nil {
+				blocks = RetimeBlocks(blocks, cut.keep)
+			}
+		}
+		parts, lengths = append(parts, blocks), append(lengths, cut.length)
+	}
+	if !found {
+		return nil, domain.ErrNotFound
+	}
+	return stitchBlocks(parts, lengths, clipTransition(c.Style)), nil
+}
+
+// StartRender queues a render of the clip for one credit. When an identical render (same source,
+// range, style, captions and assets) already exists in storage, the clip is pointed at it instead:
+// no credit is charged and the returned job is already completed (cached is true).
+// With presets, the job renders one output per export preset instead of the clip's own, for one
+// credit each that is not cached, and records them in the clip's exports.
+func (s *ClipService) StartRender(ctx context.Context, clipID, userID string, presets []string) (jobID string, cached bool, err error) {
 	c, err := s.clipRepo.GetByID(ctx, clipID)
 	if err != nil || c == nil || c.UserID.String() != userID {
-		return "", domain.ErrNotFound
+		return "", false, domain.ErrNotFound
 	}
-	if err := s.userRepo.DeductCredits(ctx, userID, 1); err != nil {
-		return "", domain.ErrInsufficientCredits
+	if presets, err = validatePresets(presets); err != nil {
+		return "", false, err
+	}
+	if len(presets) == 0 {
+		err = checkResolution(ctx, s.userRepo, userID, c.Resolution)
+	} else if err = checkPresetResolutions(ctx, s.userRepo, userID, presets); err == nil {
+		err = checkPresetDurations(ctx, s.renderingSvc, clipID, presets)
+	}
+	if err != nil {
+		return "", false, err
 	}
-	usageLog := &domain.UsageLog{ID: uuid.New(), UserID: c.UserID, Action: "render", CreditsUsed: 1}
-	_ = s.usageLogRepo.Create(ctx, usageLog)
 	job := &domain.ProcessingJob{
 		ID:         uuid.New(),
 		UserID:     c.UserID,
@@ -223,17 +496,47 @@ func (s *ClipService) StartRender(ctx context.Context, clipID, userID string) (j
 		Status:     "pending",
 		Progress:   0,
 	}
+	credits := 1
+	if len(presets) == 0 {
+		if cached, err = s.renderingSvc.ReuseCached(ctx, clipID); err != nil {
+			return "", false, err
+		}
+	} else {
+		pending, err := s.renderingSvc.ReuseCachedExports(ctx, clipID, presets)
+		if err != nil {
+			return "", false, err
+		}
+		credits, cached = len(pending), len(pending) == 0
+		job.Metadata, _ = json.Marshal(domain.RenderJobMetadata{Presets: pending})
+		// Reload: cached exports were just recorded on the clip.
+		if c, err = s.clipRepo.GetByID(ctx, clipID); err != nil || c == nil {
+			return "", false, domain.ErrNotFound
+		}
+	}
+	if cached {
+		now := time.Now()
+		job.Status, job.Progress, job.StartedAt, job.CompletedAt = "completed", 100, &now, &now
+		if err := s.jobRepo.Create(ctx, job); err != nil {
+			return "", false, err
+		}
+		return job.ID.String(), true, nil
+	}
+	if err := s.userRepo.DeductCredits(ctx, userID, credits); err != nil {
+		return "", false, domain.ErrInsufficientCredits
+	}
+	usageLog := &domain.UsageLog{ID: uuid.New(), UserID: c.UserID, Action: "render", CreditsUsed: credits}
+	_ = s.usageLogRepo.Create(ctx, usageLog)
 	if err := s.jobRepo.Create(ctx, job); err != nil {
-		return "", err
+		return "", false, err
 	}
 	if err := s.queue.EnqueueRender(c.ID, job.ID); err != nil {
-		return "", err
+		return "", false, err
 	}
 	c.Status = "rendering"
 	if err := s.clipRepo.Update(ctx, c); err != nil {
-		return "", err
+		return "", false, err
 	}
-	return job.ID.String(), nil
+	return job.ID.String(), false, nil
 }
 
 func (s *ClipService) CancelRender(ctx context.Context, clipID, userID string) error {
@@ -249,11 +552,16 @@ func (s *ClipService) CancelRender(ctx context.Context, clipID, userID string) e
 		return nil
 	}
 	job.Status = "cancelled"
-	return s.jobRepo.Update(ctx, job)
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return err
+	}
+	// The worker restores the clip status once FFmpeg has stopped (or when it dequeues the task).
+	return s.queue.CancelJob(job.ID)
 }
 
 func (s *ClipService) ApplyTemplate(ctx context.Context, clipID, userID, templateID string) error {
-	if _, err := s.GetByID(ctx, clipID, userID); err != nil {
+	c, err := s.GetByID(ctx, clipID, userID)
+	if err != nil {
 		return err
 	}
 	tpl, err := s.templateRepo.GetByID(ctx, templateID)
@@ -281,6 +589,47 @@ func (s *ClipService) ApplyTemplate(ctx context.Context, clipID, userID, templat
 		if v, ok := cfg["caption_position"].(string); ok && v != "" {
 			style.CaptionPosition = v
 		}
+		if v, ok := cfg["caption_bg_color"].(string); ok && v != "" {
+			style.CaptionBgColor = &v
+		}
+		if v, ok := cfg["caption_animation"].(string); ok && v != "" {
+			style.CaptionAnimation = &v
+		}
+		if v, ok := cfg["remove_silences"].(bool); ok {
+			style.RemoveSilences = v
+		}
+		if v, ok := cfg["remove_fillers"].(bool); ok {
+			style.RemoveFillers = v
+		}
+		if v, ok := cfg["silence_min_duration"].(float64); ok && v >= minSilenceDuration && v <= maxSilenceDuration {
+			style.SilenceMinDuration = v
+		}
+		clipChanged := false
+		if v, ok := cfg["fit_mode"].(string); ok && video.FitModes[v] {
+			c.FitMode, clipChanged = v, true
+		}
+		if v, ok := cfg["fit_color"].(string); ok && video.ValidFitColor(v) {
+			c.FitColor, clipChanged = &v, true
+		}
+		// Keyframes saved with a template (e.g. a fixed off-centre framing) replace the clip's when they
+		// fit its duration; otherwise the clip keeps its own.
+		if v, ok := cfg["reframe"]; ok {
+			var keys domain.Reframe
+			if raw, err := json.Marshal(v); err == nil && json.Unmarshal(raw, &keys) == nil {
+				if keys, err = normalizeReframe(keys, spansDuration(clipSpans(c))); err == nil && len(keys) > 0 {
+					c.Reframe, clipChanged = keys, true
+				}
+			}
+		}
+		// An invalid layout (saved before layouts were validated) is ignored like other bad values.
+		if l, err := layoutFromConfig(cfg); err == nil && l != nil {
+			c.Layout, clipChanged = l, true
+		}
+		if clipChanged {
+			if err := s.clipRepo.Update(ctx, c); err != nil {
+				return err
+			}
+		}
 	}
 	if err := s.clipStyleRepo.Update(ctx, style); err != nil {
 		return err
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"reelcut/internal/domain"
+)
+
+const testUserID = "0b8f3c1e-0000-4000-8000-000000000001"
+
+func TestApplyStyleUpdate_RemoveSilences(t *testing.T) {
+	style := &domain.ClipStyle{CaptionColor: "#FFFFFF", RemoveSilences: true, SilenceMinDuration: defaultSilenceMinDuration}
+	var partial StyleUpdate
+	if err := json.Unmarshal([]byte(`{"caption_color": "#FFFF00"}`), &partial); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyStyleUpdate(style, &partial, testUserID); err != nil {
+		t.Fatal(err)
+	}
+	if !style.RemoveSilences || style.CaptionColor != "#FFFF00" {
+		t.Errorf("partial update: remove_silences = %v, caption_color = %s; want true, #FFFF00", style.RemoveSilences, style.CaptionColor)
+	}
+
+	var off StyleUpdate
+	if err := json.Unmarshal([]byte(`{"remove_silences": false}`), &off); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyStyleUpdate(style, &off, testUserID); err != nil {
+		t.Fatal(err)
+	}
+	if style.RemoveSilences {
+		t.Error("remove_silences: false did not turn silence removal off")
+	}
+}
+
+func TestApplyStyleUpdate_RemoveFillers(t *testing.T) {
+	style := &domain.ClipStyle{CaptionSize: 48, RemoveFillers: true}
+	var partial StyleUpdate
+	if err := json.Unmarshal([]byte(`{"caption_size": 60}`), &partial); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyStyleUpdate(style, &partial, testUserID); err != nil {
+		t.Fatal(err)
+	}
+	if !style.RemoveFillers || style.CaptionSize != 60 {
+		t.Errorf("partial update: remove_fillers = %v, caption_size = %d; want true, 60", style.RemoveFillers, style.CaptionSize)
+	}
+
+	var off StyleUpdate
+	if err := json.Unmarshal([]byte(`{"remove_fillers": false}`), &off); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyStyleUpdate(style, &off, testUserID); err != nil {
+		t.Fatal(err)
+	}
+	if style.RemoveFillers {
+		t.Error("remove_fillers: false did not turn filler removal off")
+	}
+}
+
+func TestApplyStyleUpdate_BrandLogo(t *testing.T) {
+	own := "assets/" + testUserID + "/logo/3f0c.png"
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{own, false},
+		{"http://169.254.169.254/latest/meta-data/", true},
+		{"https://example.com/logo.png", true},
+		{"assets/6a1e0000-0000-4000-8000-000000000002/logo/3f0c.png", true},
+		{"assets/" + testUserID + "/logo/../../other/logo.png", true},
+		{"videos/" + testUserID + "/a.mp4", true},
+	}
+	for _, tt := range tests {
+		style := &domain.ClipStyle{}
+		key := tt.key
+		err := applyStyleUpdate(style, &StyleUpdate{ClipStyle: domain.ClipStyle{BrandLogoURL: &key}}, testUserID)
+		var ve *domain.ValidationError
+		if tt.wantErr != errors.As(err, &ve) {
+			t.Errorf("brand_logo_url %q: err = %v, want error %v", tt.key, err, tt.wantErr)
+		}
+		if tt.wantErr && style.BrandLogoURL != nil {
+			t.Errorf("brand_logo_url %q was stored", tt.key)
+		}
+	}
+
+	style := &domain.ClipStyle{BrandLogoURL: &own}
+	empty := ""
+	if err := applyStyleUpdate(style, &StyleUpdate{ClipStyle: domain.ClipStyle{BrandLogoURL: &empty}}, testUserID); err != nil || style.BrandLogoURL != nil {
+		t.Errorf("empty brand_logo_url: err = %v, logo = %v; want the logo removed", err, style.BrandLogoURL)
+	}
+}
+
+func TestApplyStyleUpdate_BackgroundMusic(t *testing.T) {
+	for key, wantErr := range map[string]bool{
+		"assets/" + testUserID + "/music/9a1d.mp3": false,
+		"assets/" + testUserID + "/logo/3f0c.png":  true,
+		"http://minio:9000/minio/admin/v3/info":    true,
+	} {
+		style := &domain.ClipStyle{}
+		err := applyStyleUpdate(style, &StyleUpdate{ClipStyle: domain.ClipStyle{BackgroundMusicURL: &key}}, testUserID)
+		var ve *domain.ValidationError
+		if wantErr != errors.As(err, &ve) || (wantErr && style.BackgroundMusicURL != nil) {
+			t.Errorf("background_music_url %q: err = %v, stored %v", key, err, style.BackgroundMusicURL)
+		}
+	}
+}
+
+func TestApplyStyleUpdate_CaptionValidation(t *testing.T) {
+	bad := []struct{ field, body string }{
+		{"caption_color", `{"caption_color": "red"}`},
+		{"caption_color", `{"caption_color": "#FFF"}`},
+		{"caption_bg_color", `{"caption_bg_color": "#00000Z"}`},
+		{"caption_position", `{"caption_position": "left"}`},
+		{"caption_animation", `{"caption_animation": "spin"}`},
+		{"brand_logo_scale", `{"brand_logo_scale": 2.5}`},
+		{"brand_logo_scale", `{"brand_logo_scale": -1}`},
+	}
+	for _, tt := range bad {
+		var u StyleUpdate
+		if err := json.Unmarshal([]byte(tt.body), &u); err != nil {
+			t.Fatal(err)
+		}
+		var ve *domain.ValidationError
+		if err := applyStyleUpdate(&domain.ClipStyle{}, &u, testUserID); !errors.As(err, &ve) || ve.Field != tt.field {
+			t.Errorf("%s: err = %v, want a %s validation error", tt.body, err, tt.field)
+		}
+	}
+
+	style := &domain.ClipStyle{}
+	var u StyleUpdate
+	body := `{"caption_color": "#ffcc00", "caption_bg_color": "#000000CC", "caption_position": "center", "caption_animation": "word-pop", "brand_logo_scale": 1.5}`
+	if err := json.Unmarshal([]byte(body), &u); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyStyleUpdate(style, &u, testUserID); err != nil {
+		t.Fatalf("valid caption style: %v", err)
+	}
+	if style.CaptionColor != "#ffcc00" || *style.CaptionBgColor != "#000000CC" || style.CaptionPosition != "center" || *style.CaptionAnimation != "word-pop" || style.BrandLogoScale != 1.5 {
+		t.Errorf("style = %+v, want the update applied", style)
+	}
+}
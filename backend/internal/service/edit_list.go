@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+const (
+	// defaultSilenceMinDuration is the shortest pause (seconds) cut by ClipStyle.RemoveSilences.
+	defaultSilenceMinDuration = 0.6
+	minSilenceDuration        = 0.2
+	maxSilenceDuration        = 5.0
+	// silencePadSec of every pause is kept next to speech so cuts do not clip word onsets and tails.
+	silencePadSec = 0.12
+	// silenceNoiseDB is the level below which silencedetect counts audio as silent.
+	silenceNoiseDB = -35.0
+)
+
+// Reasons a range is cut from a clip.
+const (
+	EditReasonSilence = "silence"
+	EditReasonFiller  = "filler"
+	EditReasonStutter = "stutter"
+)
+
+// EditRange is one span cut out of a clip at render time, relative to the clip start.
+type EditRange struct {
+	Start  float64 `json:"start"`
+	End    float64 `json:"end"`
+	Reason string  `json:"reason"`
+	Text   string  `json:"text,omitempty"` // the words removed, for fillers and stutters
+}
+
+// EditList is the jump cuts a render of a clip will make.
+type EditList struct {
+	Removed        []EditRange `json:"removed"`
+	Duration       float64     `json:"duration"`        // clip length before cuts
+	OutputDuration float64     `json:"output_duration"` // rendered length
+	// DetectSilences is set when the clip has no transcript: pauses are then found with silencedetect
+	// during the render and are not part of Removed.
+	DetectSilences bool `json:"detect_silences,omitempty"`
+}
+
+// EditList previews the ranges a render of the clip will cut, from its current style and transcripts.
+// Ranges of a multi-segment clip are timed on its segments laid end to end.
+func (s *RenderingService) EditList(ctx context.Context, clipID string) (*EditList, error) {
+	c, err := s.clipRepo.GetByID(ctx, clipID)
+	if err != nil || c == nil {
+		return nil, domain.ErrNotFound
+	}
+	style, _ := s.clipStyleRepo.GetByClipID(ctx, clipID)
+	spans := clipSpans(c)
+	out := &EditList{Removed: []EditRange{}}
+	transcripts := map[string]*domain.Transcription{}
+	var lengths []float64
+	for _, sp := range spans {
+		var t *domain.Transcription
+		if style != nil && (style.RemoveSilences || style.RemoveFillers) {
+			var ok bool
+			if t, ok = transcripts[sp.VideoID.String()]; !ok {
+				t, _ = s.transcriptionSvc.GetByVideoID(ctx, sp.VideoID.String())
+				transcripts[sp.VideoID.String()] = t
+			}
+		}
+		cut := cutSpan(t, style, sp.StartTime, sp.EndTime, s.fillers)
+		for _, e := range cut.edits {
+			e.Start, e.End = e.Start+out.Duration, e.End+out.Duration
+			out.Removed = append(out.Removed, e)
+		}
+		out.DetectSilences = out.DetectSilences || cut.probeSilence && len(spans) == 1
+		out.Duration += sp.EndTime - sp.StartTime
+		lengths = append(lengths, cut.length)
+	}
+	_, out.OutputDuration = video.JoinOffsets(lengths, clipTransition(style))
+	return out, nil
+}
+
+// spanCut is the jump cut a style makes on one source span of a clip.
+type spanCut struct {
+	edits        []EditRange       // ranges cut, relative to the span start
+	keep         []video.TimeRange // what is left of the span; nil when nothing is cut
+	length       float64           // span length after cuts
+	probeSilence bool              // pauses have to be found with silencedetect (no transcript)
+}
+
+// cutSpan works out the jump cut style makes on the source range [start, end] of transcript t. Renders,
+// the edit list and caption exports all go through it, so they agree on what is kept.
+func cutSpan(t *domain.Transcription, style *domain.ClipStyle, start, end float64, fillers FillerDictionary) spanCut {
+	edits, probe := clipEdits(t, style, start, end, fillers)
+	cut := spanCut{edits: edits, length: end - start, probeSilence: probe}
+	if cut.keep = jumpCutKeep(cut.length, editRanges(edits)); cut.keep != nil {
+		cut.length = video.KeptDuration(cut.keep)
+	}
+	return cut
+}
+
+// clipEdits returns the cuts style asks for on the source range [start, end], relative to start and
+// sorted by start: transcript pauses, fillers and stutters. detectSilences reports that silence removal
+// is on but there is no transcript to find pauses in, so the render has to detect them from the audio.
+func clipEdits(t *domain.Transcription, style *domain.ClipStyle, start, end float64, fillers FillerDictionary) (edits []EditRange, detectSilences bool) {
+	if style == nil {
+		return nil, false
+	}
+	hasTranscript := t != nil && len(t.Segments) > 0
+	if style.RemoveSilences {
+		if !hasTranscript {
+			detectSilences = true
+		} else {
+			for _, r := range SilenceCuts(t.Segments, start, end, silenceMinDuration(style)) {
+				edits = append(edits, EditRange{Start: r.Start, End: r.End, Reason: EditReasonSilence})
+			}
+		}
+	}
+	if style.RemoveFillers && hasTranscript {
+		edits = append(edits, FillerCuts(t.Segments, t.Language, fillers, start, end)...)
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Start < edits[j].Start })
+	return edits, detectSilences
+}
+
+// editRanges returns the time ranges of edits.
+func editRanges(edits []EditRange) []video.TimeRange {
+	var out []video.TimeRange
+	for _, e := range edits {
+		out = append(out, video.TimeRange{Start: e.Start, End: e.End})
+	}
+	return out
+}
+
+// silenceMinDuration returns the style's pause threshold, or the default when unset.
+func silenceMinDuration(style *domain.ClipStyle) float64 {
+	if style == nil || style.SilenceMinDuration <= 0 {
+		return defaultSilenceMinDuration
+	}
+	return style.SilenceMinDuration
+}
+
+// SilenceCuts returns the pauses of at least minDuration between words of the transcript over
+// [clipStart, clipEnd], relative to clipStart and padded (see padCuts). Dead air before the first and
+// after the last word counts too. It returns nil when nothing is said in the range.
+func SilenceCuts(segments []domain.TranscriptSegment, clipStart, clipEnd, minDuration float64) []video.TimeRange {
+	speech := speechRanges(segments, clipStart, clipEnd, minDuration)
+	if len(speech) == 0 {
+		return nil
+	}
+	dur := clipEnd - clipStart
+	var gaps []video.TimeRange
+	prev := 0.0
+	for _, r := range speech {
+		if r.Start-prev >= minDuration {
+			gaps = append(gaps, video.TimeRange{Start: prev, End: r.Start})
+		}
+		prev = r.End
+	}
+	if dur-prev >= minDuration {
+		gaps = append(gaps, video.TimeRange{Start: prev, End: dur})
+	}
+	return padCuts(gaps, dur)
+}
+
+// padCuts shrinks each pause in gaps by silencePadSec where it borders speech, so a little breathing
+// room stays around every cut. Edges at 0 and duration are cut flush.
+func padCuts(gaps []video.TimeRange, duration float64) []video.TimeRange {
+	var out []video.TimeRange
+	for _, g := range gaps {
+		if g.Start > 0 {
+			g.Start += silencePadSec
+		}
+		if g.End < duration {
+			g.End -= silencePadSec
+		}
+		if g.End > g.Start {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// jumpCutKeep returns the ranges of a clip of duration seconds left after removing cuts, or nil when
+// there is nothing to cut (or nothing would be left).
+func jumpCutKeep(duration float64, cuts []video.TimeRange) []video.TimeRange {
+	if len(cuts) == 0 {
+		return nil
+	}
+	keep := video.KeepRanges(duration, cuts)
+	if len(keep) == 0 {
+		return nil
+	}
+	return keep
+}
+
+// RetimeBlocks maps caption blocks (times relative to the clip) onto the output of a jump cut keeping
+// keep. Words that fall in a removed range are dropped along with blocks left empty.
+func RetimeBlocks(blocks []CaptionBlock, keep []video.TimeRange) []CaptionBlock {
+	out := make([]CaptionBlock, 0, len(blocks))
+	for _, blk := range blocks {
+		if len(blk.Words) > 0 {
+			words := make([]CaptionWord, 0, len(blk.Words))
+			texts := make([]string, 0, len(blk.Words))
+			for _, w := range blk.Words {
+				start, end := video.RemapTime(keep, w.StartTime), video.RemapTime(keep, w.EndTime)
+				if end <= start {
+					continue
+				}
+				w.StartTime, w.EndTime = start, end
+				words = append(words, w)
+				texts = append(texts, w.Text)
+			}
+			if len(words) == 0 {
+				continue
+			}
+			if len(words) < len(blk.Words) {
+				blk.Text = strings.Join(texts, " ")
+			}
+			blk.Words = words
+		}
+		blk.StartTime, blk.EndTime = video.RemapTime(keep, blk.StartTime), video.RemapTime(keep, blk.EndTime)
+		if blk.EndTime <= blk.StartTime {
+			continue
+		}
+		out = append(out, blk)
+	}
+	return out
+}
+
+// remapRanges maps ranges onto the output of a jump cut keeping keep, dropping ranges that were cut.
+func remapRanges(keep []video.TimeRange, ranges []video.TimeRange) []video.TimeRange {
+	var out []video.TimeRange
+	for _, r := range ranges {
+		r.Start, r.End = video.RemapTime(keep, r.Start), video.RemapTime(keep, r.End)
+		if r.End > r.Start {
+			out = append(out, r)
+		}
+	}
+	return out
+}
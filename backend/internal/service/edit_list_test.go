@@ -0,0 +1,73 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+func TestSilenceCuts(t *testing.T) {
+	seg := domain.TranscriptSegment{StartTime: 11, EndTime: 19, Words: []domain.TranscriptWord{
+		{Word: "so", StartTime: 11, EndTime: 11.4},
+		{Word: "here's", StartTime: 11.5, EndTime: 12},
+		{Word: "the", StartTime: 14, EndTime: 14.2},
+		{Word: "thing", StartTime: 14.3, EndTime: 15},
+	}}
+	// Clip [10, 18]: dead air before "so", a 2s pause and trailing silence; the 0.1s gaps stay.
+	got := SilenceCuts([]domain.TranscriptSegment{seg}, 10, 18, 0.6)
+	want := []video.TimeRange{{Start: 0, End: 1 - silencePadSec}, {Start: 2 + silencePadSec, End: 4 - silencePadSec}, {Start: 5 + silencePadSec, End: 8}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SilenceCuts = %v, want %v", got, want)
+	}
+	if got := SilenceCuts(nil, 10, 18, 0.6); got != nil {
+		t.Errorf("no transcript: got %v, want nil", got)
+	}
+}
+
+func TestRetimeBlocks(t *testing.T) {
+	keep := []video.TimeRange{{Start: 0, End: 2}, {Start: 4, End: 6}}
+	blocks := []CaptionBlock{
+		{StartTime: 1, EndTime: 5, Text: "one two three", Words: []CaptionWord{
+			{Text: "one", StartTime: 1, EndTime: 1.5},
+			{Text: "two", StartTime: 2.5, EndTime: 3.5}, // inside the cut
+			{Text: "three", StartTime: 4.5, EndTime: 5},
+		}},
+		{StartTime: 2.2, EndTime: 3.8, Text: "gone"},
+		{StartTime: 5, EndTime: 6, Text: "tail"},
+	}
+	got := RetimeBlocks(blocks, keep)
+	want := []CaptionBlock{
+		{StartTime: 1, EndTime: 3, Text: "one three", Words: []CaptionWord{
+			{Text: "one", StartTime: 1, EndTime: 1.5},
+			{Text: "three", StartTime: 2.5, EndTime: 3},
+		}},
+		{StartTime: 3, EndTime: 4, Text: "tail"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RetimeBlocks =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCutSpan(t *testing.T) {
+	tr := &domain.Transcription{Segments: []domain.TranscriptSegment{{StartTime: 10, EndTime: 16, Words: []domain.TranscriptWord{
+		{Word: "before", StartTime: 10, EndTime: 11},
+		{Word: "after", StartTime: 15, EndTime: 16},
+	}}}}
+	style := &domain.ClipStyle{RemoveSilences: true, CaptionMaxWords: 1}
+	cut := cutSpan(tr, style, 10, 16, nil)
+	wantKeep := []video.TimeRange{{Start: 0, End: 1 + silencePadSec}, {Start: 5 - silencePadSec, End: 6}}
+	if !reflect.DeepEqual(cut.keep, wantKeep) {
+		t.Fatalf("keep = %v, want %v", cut.keep, wantKeep)
+	}
+	// Exported captions follow the cut: "after" starts where the render resumes.
+	blocks := RetimeBlocks(ShiftBlocks(BlocksFromSegments(tr.Segments, style, 10, 16), -10), cut.keep)
+	if len(blocks) != 2 || blocks[1].Text != "after" || blocks[1].EndTime > cut.length+1e-9 {
+		t.Errorf("retimed captions = %+v, want both words within %.2fs", blocks, cut.length)
+	}
+
+	if cut := cutSpan(tr, &domain.ClipStyle{}, 10, 16, nil); cut.keep != nil || cut.length != 6 {
+		t.Errorf("no cuts: keep = %v, length = %v; want nil, 6", cut.keep, cut.length)
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/repository"
+	"reelcut/internal/video"
+)
+
+// maxResolutionByTier caps the output resolution each subscription tier may render, as the short side
+// of the frame in pixels. Tiers not listed get the free cap.
+var maxResolutionByTier = map[string]int{
+	"free":       1080,
+	"pro":        2160,
+	"enterprise": 2160,
+}
+
+// resolutionAllowed reports whether a user on tier may render at resolution (an empty resolution is
+// DefaultResolution).
+func resolutionAllowed(tier, resolution string) bool {
+	if resolution == "" {
+		resolution = video.DefaultResolution
+	}
+	limit, ok := maxResolutionByTier[tier]
+	if !ok {
+		limit = maxResolutionByTier["free"]
+	}
+	return video.Resolutions[resolution] <= limit
+}
+
+// checkResolution returns an error wrapping domain.ErrForbidden when the user's plan does not include
+// resolution.
+func checkResolution(ctx context.Context, users repository.UserRepository, userID, resolution string) error {
+	u, err := users.GetByID(ctx, userID)
+	if err != nil || u == nil {
+		return domain.ErrNotFound
+	}
+	if !resolutionAllowed(u.SubscriptionTier, resolution) {
+		return fmt.Errorf("%w: %s output is not included in the %s plan", domain.ErrForbidden, resolution, u.SubscriptionTier)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+
+	"reelcut/internal/video"
+)
+
+func TestResolutionAllowed(t *testing.T) {
+	tests := []struct {
+		tier, res string
+		want      bool
+	}{
+		{"free", "", true},
+		{"free", video.Resolution720p, true},
+		{"free", video.Resolution1080p, true},
+		{"free", video.Resolution1440p, false},
+		{"free", video.Resolution4K, false},
+		{"pro", video.Resolution4K, true},
+		{"enterprise", video.Resolution4K, true},
+		{"unknown", video.Resolution4K, false},
+		{"unknown", video.Resolution1080p, true},
+	}
+	for _, tt := range tests {
+		if got := resolutionAllowed(tt.tier, tt.res); got != tt.want {
+			t.Errorf("resolutionAllowed(%q, %q) = %v, want %v", tt.tier, tt.res, got, tt.want)
+		}
+	}
+}
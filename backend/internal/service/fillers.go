@@ -0,0 +1,155 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"reelcut/internal/domain"
+)
+
+// FillerDictionary maps a transcript language code ("en", "es", ...) to the filler words and phrases
+// cut by ClipStyle.RemoveFillers. Phrases are matched case-insensitively on whole words, ignoring
+// punctuation.
+type FillerDictionary map[string][]string
+
+// DefaultFillerWords is used for languages the configured dictionary does not cover.
+var DefaultFillerWords = FillerDictionary{
+	"en": {"um", "umm", "uh", "uhh", "uhm", "erm", "er", "ah", "hmm", "like", "you know", "i mean"},
+	"es": {"eh", "em", "este", "o sea"},
+	"fr": {"euh", "heu", "bah", "ben"},
+	"de": {"äh", "ähm", "öh", "hm"},
+	"pt": {"hã", "ahn", "né", "tipo"},
+}
+
+// LoadFillerDictionary returns DefaultFillerWords with the languages in the JSON file at path (an
+// object of language code to word list) replacing the defaults. An empty path loads the defaults.
+func LoadFillerDictionary(path string) (FillerDictionary, error) {
+	dict := FillerDictionary{}
+	for lang, words := range DefaultFillerWords {
+		dict[lang] = words
+	}
+	if path == "" {
+		return dict, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var custom FillerDictionary
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for lang, words := range custom {
+		dict[strings.ToLower(lang)] = words
+	}
+	return dict, nil
+}
+
+// phrases returns the tokenised entries for lang, longest first so "you know" wins over "you". Region
+// subtags are ignored ("en-US" uses "en").
+func (d FillerDictionary) phrases(lang string) [][]string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	words, ok := d[lang]
+	if !ok {
+		base, _, _ := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-")
+		words = d[base]
+	}
+	var out [][]string
+	for _, w := range words {
+		var tokens []string
+		for _, f := range strings.Fields(w) {
+			if t := normalizeWord(f); t != "" {
+				tokens = append(tokens, t)
+			}
+		}
+		if len(tokens) > 0 {
+			out = append(out, tokens)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i]) > len(out[j]) })
+	return out
+}
+
+// normalizeWord lowercases w and strips surrounding punctuation.
+func normalizeWord(w string) string {
+	return strings.ToLower(strings.TrimFunc(w, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}))
+}
+
+// FillerCuts returns the filler phrases from dict and the stutters (a word repeated, or started and
+// restarted as in "th- the") spoken in [clipStart, clipEnd], relative to clipStart. Only segments with
+// word timings are considered. Of a stutter, the last repetition is kept.
+func FillerCuts(segments []domain.TranscriptSegment, lang string, dict FillerDictionary, clipStart, clipEnd float64) []EditRange {
+	var words []domain.TranscriptWord
+	for _, seg := range segments {
+		for _, w := range seg.Words {
+			if mid := (w.StartTime + w.EndTime) / 2; mid >= clipStart && mid <= clipEnd && strings.TrimSpace(w.Word) != "" {
+				words = append(words, w)
+			}
+		}
+	}
+	norm := make([]string, len(words))
+	for i, w := range words {
+		norm[i] = normalizeWord(w.Word)
+	}
+	cut := func(from, to int, reason string) EditRange {
+		texts := make([]string, 0, to-from)
+		for _, w := range words[from:to] {
+			texts = append(texts, strings.TrimSpace(w.Word))
+		}
+		return EditRange{
+			Start:  max(words[from].StartTime, clipStart) - clipStart,
+			End:    min(words[to-1].EndTime, clipEnd) - clipStart,
+			Reason: reason,
+			Text:   strings.Join(texts, " "),
+		}
+	}
+	phrases := dict.phrases(lang)
+	var out []EditRange
+	for i := 0; i < len(words); {
+		if n := matchPhrase(norm[i:], phrases); n > 0 {
+			out = append(out, cut(i, i+n, EditReasonFiller))
+			i += n
+			continue
+		}
+		if i+1 < len(words) && norm[i] != "" && isStutter(words[i].Word, norm[i], norm[i+1]) {
+			out = append(out, cut(i, i+1, EditReasonStutter))
+		}
+		i++
+	}
+	return out
+}
+
+// matchPhrase returns the length of the first phrase that words starts with, or 0.
+func matchPhrase(words []string, phrases [][]string) int {
+	for _, p := range phrases {
+		if len(p) > len(words) {
+			continue
+		}
+		match := true
+		for j, t := range p {
+			if words[j] != t {
+				match = false
+				break
+			}
+		}
+		if match {
+			return len(p)
+		}
+	}
+	return 0
+}
+
+// isStutter reports whether a word (raw and normalised) is a false start of the word after it: the
+// same word again, or a fragment cut off with a hyphen that the next word completes.
+func isStutter(raw, word, next string) bool {
+	if word == next {
+		return true
+	}
+	return strings.HasSuffix(strings.TrimSpace(raw), "-") && strings.HasPrefix(next, word)
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"reelcut/internal/domain"
+)
+
+func TestFillerCuts(t *testing.T) {
+	words := func(ws ...string) []domain.TranscriptWord {
+		out := make([]domain.TranscriptWord, len(ws))
+		for i, w := range ws {
+			out[i] = domain.TranscriptWord{Word: w, StartTime: 10 + float64(i), EndTime: 10.5 + float64(i)}
+		}
+		return out
+	}
+	segs := []domain.TranscriptSegment{{Words: words("So,", "um", "I", "I", "think", "you", "know,", "th-", "that's", "it.")}}
+
+	got := FillerCuts(segs, "en-US", DefaultFillerWords, 10, 20)
+	want := []EditRange{
+		{Start: 1, End: 1.5, Reason: EditReasonFiller, Text: "um"},
+		{Start: 2, End: 2.5, Reason: EditReasonStutter, Text: "I"},
+		{Start: 5, End: 6.5, Reason: EditReasonFiller, Text: "you know,"},
+		{Start: 7, End: 7.5, Reason: EditReasonStutter, Text: "th-"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FillerCuts =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Unknown languages only lose stutters.
+	if got := FillerCuts(segs, "xx", DefaultFillerWords, 10, 20); len(got) != 2 {
+		t.Errorf("unknown language: %+v", got)
+	}
+}
+
+func TestLoadFillerDictionary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fillers.json")
+	if err := os.WriteFile(path, []byte(`{"EN": ["basically"], "it": ["cioè"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dict, err := LoadFillerDictionary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dict["en"], []string{"basically"}) || len(dict["it"]) != 1 || len(dict["fr"]) == 0 {
+		t.Errorf("dictionary = %v", dict)
+	}
+	if len(DefaultFillerWords["en"]) < 2 {
+		t.Error("defaults modified")
+	}
+}
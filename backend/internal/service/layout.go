@@ -0,0 +1,101 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+// normalizeLayout validates a clip layout, filling in the preset's default regions when none are given
+// and, for picture-in-picture, the default inset corner and width.
+func normalizeLayout(l *domain.Layout) error {
+	if !video.LayoutPresets[l.Preset] {
+		return &domain.ValidationError{Field: "layout.preset", Message: "must be split, side_by_side or pip"}
+	}
+	if len(l.Regions) == 0 {
+		for _, r := range video.DefaultLayoutRegions(l.Preset) {
+			l.Regions = append(l.Regions, domain.LayoutRegion{X: r.X, Y: r.Y, W: r.W, H: r.H})
+		}
+	}
+	if len(l.Regions) != video.LayoutRegionCount {
+		return &domain.ValidationError{Field: "layout.regions", Message: fmt.Sprintf("must have %d regions", video.LayoutRegionCount)}
+	}
+	for i, r := range l.Regions {
+		switch {
+		case r.X < 0 || r.X >= 1 || r.Y < 0 || r.Y >= 1:
+			return &domain.ValidationError{Field: fmt.Sprintf("layout.regions[%d]", i), Message: "x and y must be at least 0 and less than 1"}
+		case r.W <= 0 || r.H <= 0 || r.X+r.W > 1.0001 || r.Y+r.H > 1.0001:
+			return &domain.ValidationError{Field: fmt.Sprintf("layout.regions[%d]", i), Message: "w and h must be positive and keep the region inside the frame"}
+		}
+	}
+	if l.Preset != video.LayoutPiP {
+		l.PiPPosition, l.PiPScale = "", 0
+		return nil
+	}
+	if l.PiPPosition == "" {
+		l.PiPPosition = video.DefaultPiPPosition
+	}
+	if !video.OverlayPositions[l.PiPPosition] {
+		return &domain.ValidationError{Field: "layout.pip_position", Message: "must be top-left, top-right, bottom-left, bottom-right or center"}
+	}
+	if l.PiPScale == 0 {
+		l.PiPScale = video.DefaultPiPScale
+	}
+	if l.PiPScale < video.MinPiPScale || l.PiPScale > video.MaxPiPScale {
+		return &domain.ValidationError{Field: "layout.pip_scale", Message: fmt.Sprintf("must be between %g and %g", video.MinPiPScale, video.MaxPiPScale)}
+	}
+	return nil
+}
+
+// layoutFromConfig reads the "layout" key of a template's style config. It returns nil when the key is
+// absent or null.
+func layoutFromConfig(cfg map[string]interface{}) (*domain.Layout, error) {
+	v, ok := cfg["layout"]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var l domain.Layout
+	if err := json.Unmarshal(raw, &l); err != nil {
+		return nil, &domain.ValidationError{Field: "layout", Message: "must be an object with preset and regions"}
+	}
+	if err := normalizeLayout(&l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// validateStyleConfig checks the parts of a template's style config that are validated on save: for now
+// its layout.
+func validateStyleConfig(styleConfig json.RawMessage) error {
+	if len(styleConfig) == 0 {
+		return nil
+	}
+	var cfg map[string]interface{}
+	if err := json.Unmarshal(styleConfig, &cfg); err != nil {
+		return &domain.ValidationError{Field: "style_config", Message: "must be a JSON object"}
+	}
+	if _, err := layoutFromConfig(cfg); err != nil {
+		var ve *domain.ValidationError
+		if errors.As(err, &ve) {
+			return &domain.ValidationError{Field: "style_config." + ve.Field, Message: ve.Message}
+		}
+		return err
+	}
+	return nil
+}
+
+// clipLayout converts a clip's layout for the render.
+func clipLayout(l *domain.Layout) video.LayoutOptions {
+	opts := video.LayoutOptions{Preset: l.Preset, PiPPosition: l.PiPPosition, PiPScale: l.PiPScale}
+	for _, r := range l.Regions {
+		opts.Regions = append(opts.Regions, video.LayoutRegion{X: r.X, Y: r.Y, W: r.W, H: r.H})
+	}
+	return opts
+}
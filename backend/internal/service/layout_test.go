@@ -0,0 +1,69 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+func TestNormalizeLayout(t *testing.T) {
+	l := &domain.Layout{Preset: video.LayoutPiP}
+	if err := normalizeLayout(l); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Regions) != 2 || l.Regions[0] != (domain.LayoutRegion{W: 1, H: 1}) {
+		t.Errorf("default pip regions = %+v", l.Regions)
+	}
+	if l.PiPPosition != video.DefaultPiPPosition || l.PiPScale != video.DefaultPiPScale {
+		t.Errorf("pip defaults = %s %g, want %s %g", l.PiPPosition, l.PiPScale, video.DefaultPiPPosition, video.DefaultPiPScale)
+	}
+
+	l = &domain.Layout{Preset: video.LayoutSplit, PiPPosition: "top-left", PiPScale: 0.5,
+		Regions: []domain.LayoutRegion{{X: 0, Y: 0, W: 0.5, H: 1}, {X: 0.5, Y: 0, W: 0.5, H: 1}}}
+	if err := normalizeLayout(l); err != nil {
+		t.Fatal(err)
+	}
+	if l.PiPPosition != "" || l.PiPScale != 0 {
+		t.Errorf("split kept pip options %s %g", l.PiPPosition, l.PiPScale)
+	}
+
+	half := domain.LayoutRegion{X: 0, Y: 0, W: 0.5, H: 1}
+	bad := map[string]*domain.Layout{
+		"layout.preset":       {Preset: "grid"},
+		"layout.regions":      {Preset: video.LayoutSplit, Regions: []domain.LayoutRegion{half}},
+		"layout.regions[1]":   {Preset: video.LayoutSplit, Regions: []domain.LayoutRegion{half, {X: 0.6, Y: 0, W: 0.5, H: 1}}},
+		"layout.regions[0]":   {Preset: video.LayoutSideBySide, Regions: []domain.LayoutRegion{{X: -0.1, Y: 0, W: 0.5, H: 1}, half}},
+		"layout.pip_position": {Preset: video.LayoutPiP, PiPPosition: "middle"},
+		"layout.pip_scale":    {Preset: video.LayoutPiP, PiPScale: 0.9},
+	}
+	for field, l := range bad {
+		err := normalizeLayout(l)
+		var ve *domain.ValidationError
+		if !errors.As(err, &ve) || ve.Field != field {
+			t.Errorf("normalizeLayout(%+v) error = %v, want validation error on %s", l, err, field)
+		}
+	}
+}
+
+func TestValidateStyleConfig(t *testing.T) {
+	for _, cfg := range []string{``, `{}`, `{"caption_font":"Inter","layout":null}`, `{"layout":{"preset":"split"}}`} {
+		if err := validateStyleConfig(json.RawMessage(cfg)); err != nil {
+			t.Errorf("validateStyleConfig(%s) = %v", cfg, err)
+		}
+	}
+	bad := map[string]string{
+		`[]`:                 "style_config",
+		`{"layout":"split"}`: "style_config.layout",
+		`{"layout":{"preset":"pip","pip_scale":1}}`: "style_config.layout.pip_scale",
+	}
+	for cfg, field := range bad {
+		err := validateStyleConfig(json.RawMessage(cfg))
+		var ve *domain.ValidationError
+		if !errors.As(err, &ve) || ve.Field != field {
+			t.Errorf("validateStyleConfig(%s) error = %v, want validation error on %s", cfg, err, field)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/repository"
+	"reelcut/internal/video"
+)
+
+// presetError is the validation error for a name that is not one of video.Presets.
+func presetError() error {
+	return &domain.ValidationError{Field: "presets", Message: "must be one or more of " + strings.Join(video.PresetNames(), ", ")}
+}
+
+// validatePresets checks that every name is an export preset and returns them without duplicates, in
+// request order.
+func validatePresets(names []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := video.Presets[name]; !ok {
+			return nil, presetError()
+		}
+		if !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
+
+// checkPresetResolutions checks that the user's plan allows the resolution of every preset.
+func checkPresetResolutions(ctx context.Context, userRepo repository.UserRepository, userID string, presets []string) error {
+	for _, name := range presets {
+		if err := checkResolution(ctx, userRepo, userID, video.Presets[name].Resolution); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPresetDurations checks that the clip, as rendered (after jump cuts and transitions), is no longer
+// than any of the presets allows. Platforms reject longer uploads, and cutting the render short would
+// drop the end of the clip.
+func checkPresetDurations(ctx context.Context, renderingSvc *RenderingService, clipID string, presets []string) error {
+	if len(presets) == 0 {
+		return nil
+	}
+	edits, err := renderingSvc.EditList(ctx, clipID)
+	if err != nil {
+		return err
+	}
+	for _, name := range presets {
+		if p := video.Presets[name]; !p.Fits(edits.OutputDuration) {
+			return &domain.ValidationError{Field: "presets", Message: fmt.Sprintf("%s allows at most %gs; the clip is %.1fs", p.Label, p.MaxDuration, edits.OutputDuration)}
+		}
+	}
+	return nil
+}
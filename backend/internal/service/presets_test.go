@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"reelcut/internal/domain"
+)
+
+func TestValidatePresets(t *testing.T) {
+	got, err := validatePresets([]string{"reels", "tiktok", "reels"})
+	if err != nil || !slices.Equal(got, []string{"reels", "tiktok"}) {
+		t.Errorf("validatePresets = %v, %v; want [reels tiktok]", got, err)
+	}
+	if got, err := validatePresets(nil); err != nil || len(got) != 0 {
+		t.Errorf("validatePresets(nil) = %v, %v; want none", got, err)
+	}
+	_, err = validatePresets([]string{"tiktok", "vine"})
+	var ve *domain.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "presets" {
+		t.Errorf("unknown preset: err = %v, want a presets validation error", err)
+	}
+}
+
+func TestCheckPresetDurations(t *testing.T) {
+	ctx := context.Background()
+	f := newBatchFixture(t, 0)
+	*f.video.DurationSeconds = 300
+	c := f.addClip("Long", 0, 200)
+	renderingSvc := f.svc.renderingSvc
+
+	if err := checkPresetDurations(ctx, renderingSvc, c.ID.String(), []string{"tiktok", "linkedin"}); err != nil {
+		t.Errorf("200s clip for tiktok and linkedin: err = %v, want none", err)
+	}
+	err := checkPresetDurations(ctx, renderingSvc, c.ID.String(), []string{"tiktok", "reels"})
+	var ve *domain.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "presets" {
+		t.Errorf("200s clip for reels: err = %v, want a presets validation error", err)
+	}
+	if _, err := f.svc.Start(ctx, testUserID, f.video.ID.String(), []string{c.ID.String()}, []string{"shorts"}); !errors.As(err, &ve) {
+		t.Errorf("batch of a 200s clip for shorts: err = %v, want a validation error", err)
+	}
+	if len(f.jobs.jobs) != 0 {
+		t.Error("rejected batch created jobs")
+	}
+}
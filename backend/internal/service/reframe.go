@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+// maxReframeKeyframes bounds a clip's keyframes; each adds a level to the crop expressions.
+const maxReframeKeyframes = 64
+
+// normalizeReframe validates keyframes for a clip of duration seconds and returns them sorted by time.
+// A zero zoom is taken as 1.
+func normalizeReframe(keys domain.Reframe, duration float64) (domain.Reframe, error) {
+	if len(keys) > maxReframeKeyframes {
+		return nil, &domain.ValidationError{Field: "keyframes", Message: fmt.Sprintf("at most %d keyframes", maxReframeKeyframes)}
+	}
+	out := make(domain.Reframe, 0, len(keys))
+	for i, k := range keys {
+		if k.Zoom == 0 {
+			k.Zoom = 1
+		}
+		switch {
+		case k.Time < 0 || k.Time > duration:
+			return nil, &domain.ValidationError{Field: fmt.Sprintf("keyframes[%d].time", i), Message: fmt.Sprintf("must be between 0 and the clip duration (%.3f)", duration)}
+		case k.X < 0 || k.X > 1:
+			return nil, &domain.ValidationError{Field: fmt.Sprintf("keyframes[%d].x", i), Message: "must be between 0 and 1"}
+		case k.Y < 0 || k.Y > 1:
+			return nil, &domain.ValidationError{Field: fmt.Sprintf("keyframes[%d].y", i), Message: "must be between 0 and 1"}
+		case k.Zoom < 1 || k.Zoom > video.MaxReframeZoom:
+			return nil, &domain.ValidationError{Field: fmt.Sprintf("keyframes[%d].zoom", i), Message: fmt.Sprintf("must be between 1 and %g", video.MaxReframeZoom)}
+		}
+		out = append(out, k)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time < out[j].Time })
+	for i := 1; i < len(out); i++ {
+		if out[i].Time == out[i-1].Time {
+			return nil, &domain.ValidationError{Field: "keyframes", Message: fmt.Sprintf("two keyframes at %.3fs", out[i].Time)}
+		}
+	}
+	return out, nil
+}
+
+// reframeKeys converts a clip's keyframes for the render, mapping their times onto the output of a jump
+// cut keeping keep (nil when nothing is cut).
+func reframeKeys(keys domain.Reframe, keep []video.TimeRange) []video.ReframeKey {
+	out := make([]video.ReframeKey, 0, len(keys))
+	for _, k := range keys {
+		t := k.Time
+		if keep != nil {
+			t = video.RemapTime(keep, t)
+		}
+		out = append(out, video.ReframeKey{Time: t, X: k.X, Y: k.Y, Zoom: k.Zoom})
+	}
+	return out
+}
+
+// reframeSource describes the source of v for video.Reframe.
+func reframeSource(v *domain.Video) video.ReframeSource {
+	var src video.ReframeSource
+	if v.Width != nil && v.Height != nil {
+		src.Width, src.Height = *v.Width, *v.Height
+	}
+	if v.FPS != nil {
+		src.FPS = *v.FPS
+	}
+	return src
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+func TestNormalizeReframe(t *testing.T) {
+	got, err := normalizeReframe(domain.Reframe{
+		{Time: 4, X: 0.7, Y: 0.5, Zoom: 1.5},
+		{Time: 0, X: 0.3, Y: 0.5},
+	}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := domain.Reframe{{Time: 0, X: 0.3, Y: 0.5, Zoom: 1}, {Time: 4, X: 0.7, Y: 0.5, Zoom: 1.5}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("normalizeReframe = %+v, want %+v", got, want)
+	}
+
+	bad := map[string]domain.Reframe{
+		"keyframes[0].time": {{Time: 11, X: 0.5, Y: 0.5}},
+		"keyframes[0].x":    {{Time: 1, X: 1.2, Y: 0.5}},
+		"keyframes[1].y":    {{Time: 1, X: 0.5, Y: 0.5}, {Time: 2, X: 0.5, Y: -0.1}},
+		"keyframes[0].zoom": {{Time: 1, X: 0.5, Y: 0.5, Zoom: 0.5}},
+		"keyframes":         {{Time: 1, X: 0.5, Y: 0.5}, {Time: 1, X: 0.2, Y: 0.5}},
+	}
+	for field, keys := range bad {
+		_, err := normalizeReframe(keys, 10)
+		var ve *domain.ValidationError
+		if !errors.As(err, &ve) || ve.Field != field {
+			t.Errorf("normalizeReframe(%+v) error = %v, want validation error on %s", keys, err, field)
+		}
+	}
+}
+
+func TestReframeKeys_Retimed(t *testing.T) {
+	keep := []video.TimeRange{{Start: 0, End: 2}, {Start: 5, End: 10}}
+	got := reframeKeys(domain.Reframe{{Time: 1, X: 0.2, Y: 0.5, Zoom: 1}, {Time: 6, X: 0.8, Y: 0.5, Zoom: 2}}, keep)
+	want := []video.ReframeKey{{Time: 1, X: 0.2, Y: 0.5, Zoom: 1}, {Time: 3, X: 0.8, Y: 0.5, Zoom: 2}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("reframeKeys = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+
+	"github.com/google/uuid"
+)
+
+// renderCacheVersion is mixed into every render key. Bump it whenever the pipeline produces different
+// output for the same inputs (encoder settings, filter defaults, caption layout) so old renders are
+// not reused.
+const renderCacheVersion = 1
+
+// renderPlan is everything a render depends on apart from the media bytes themselves.
+type renderPlan struct {
+	clip          *domain.Clip
+	style         *domain.ClipStyle
+	video         *domain.Video // source of the first segment
+	width, height int
+	fit           *video.FitOptions // nil for the default crop
+	layout        *domain.Layout    // multi-region layout; when set, fit and reframe are not used
+	segments      []renderSegment   // source ranges in output order; one for single-range clips
+	transition    string            // xfade transition between segments; empty hard-cuts
+	captions      string            // ASS script; empty when captions are off or there is no transcript
+	speech        []video.TimeRange // music ducking ranges, in output time
+	preset        *video.Preset     // platform export preset; nil for the clip's own output
+	key           string            // content hash of the inputs; empty when they cannot be fingerprinted
+}
+
+// renderSegment is one source range of a render with its jump cuts and crop keyframes.
+type renderSegment struct {
+	video        *domain.Video
+	start, end   float64
+	reframe      domain.Reframe    // crop keyframes, manual or following the speaker; nil centre-crops
+	cuts         []video.TimeRange // ranges jump-cut out of the segment, relative to its start
+	keep         []video.TimeRange // what is left of the segment after cuts; nil renders it whole
+	probeSilence bool              // find pauses with silencedetect at render time (no transcript)
+}
+
+// renderKeyInput is hashed to form the render key. Fields are only ever added, never reordered, and
+// any change in meaning goes with a renderCacheVersion bump.
+type renderKeyInput struct {
+	Version     int               `json:"v"`
+	Source      string            `json:"source"`
+	Start       float64           `json:"start"`
+	End         float64           `json:"end"`
+	AspectRatio string            `json:"aspect_ratio"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	CutMode     video.CutMode     `json:"cut_mode"`
+	Style       *domain.ClipStyle `json:"style,omitempty"`
+	Captions    string            `json:"captions,omitempty"`
+	Speech      []video.TimeRange `json:"speech,omitempty"`
+	Logo        string            `json:"logo,omitempty"`
+	Music       string            `json:"music,omitempty"`
+	Cuts        []video.TimeRange `json:"cuts,omitempty"`
+	Silences    bool              `json:"detect_silences,omitempty"`
+	Fit         *video.FitOptions `json:"fit,omitempty"`
+	Reframe     domain.Reframe    `json:"reframe,omitempty"`
+	Layout      *domain.Layout    `json:"layout,omitempty"`
+	Segments    []segmentKey      `json:"segments,omitempty"`
+	Preset      *video.Preset     `json:"preset,omitempty"`
+}
+
+// segmentKey is the render key input of each segment of a multi-segment clip.
+type segmentKey struct {
+	Source  string            `json:"source"`
+	Start   float64           `json:"start"`
+	End     float64           `json:"end"`
+	Cuts    []video.TimeRange `json:"cuts,omitempty"`
+	Reframe domain.Reframe    `json:"reframe,omitempty"`
+}
+
+// plan loads the clip, its style and source videos and derives the jump cuts, caption script, ducking
+// ranges and render key. Captions and ducking ranges are retimed to the jump-cut output and, for
+// multi-segment clips, stitched across the joined segments. With a preset, the output takes the
+// preset's aspect ratio (when it has one) and resolution tier, framed the same way as the clip, and
+// captions keep clear of its safe area.
+func (s *RenderingService) plan(ctx context.Context, clipID string, preset *video.Preset) (*renderPlan, error) {
+	c, err := s.clipRepo.GetByID(ctx, clipID)
+	if err != nil || c == nil {
+		return nil, domain.ErrNotFound
+	}
+	style, _ := s.clipStyleRepo.GetByClipID(ctx, clipID)
+	v, err := s.videoRepo.GetByID(ctx, c.VideoID.String())
+	if err != nil || v == nil {
+		return nil, domain.ErrNotFound
+	}
+	p := &renderPlan{clip: c, style: style, video: v, preset: preset}
+	aspectRatio, resolution := c.AspectRatio, c.Resolution
+	var safe video.SafeArea
+	if preset != nil {
+		resolution, safe = preset.Resolution, preset.SafeArea
+		if preset.AspectRatio != "" {
+			aspectRatio = preset.AspectRatio
+		}
+	}
+	p.width, p.height = video.OutputSizeAt(aspectRatio, resolution)
+	p.layout = c.Layout
+	if p.layout == nil {
+		p.fit = clipFit(c)
+	}
+	p.transition = clipTransition(style)
+
+	hasMusic := style != nil && style.BackgroundMusicURL != nil && *style.BackgroundMusicURL != ""
+	needTranscript := style != nil && (style.CaptionEnabled || hasMusic || style.RemoveSilences || style.RemoveFillers)
+	spans := clipSpans(c)
+	multi := len(spans) > 1
+	videos := map[uuid.UUID]*domain.Video{c.VideoID: v}
+	transcripts := map[uuid.UUID]*domain.Transcription{}
+	var blocks [][]CaptionBlock
+	var speech [][]video.TimeRange
+	var lengths []float64
+	hasTranscript := false
+	base := 0.0
+	for _, sp := range spans {
+		sv, ok := videos[sp.VideoID]
+		if !ok {
+			if sv, err = s.videoRepo.GetByID(ctx, sp.VideoID.String()); err != nil || sv == nil {
+				return nil, domain.ErrNotFound
+			}
+			videos[sp.VideoID] = sv
+		}
+		dur := sp.EndTime - sp.StartTime
+		seg := renderSegment{video: sv, start: sp.StartTime, end: sp.EndTime}
+		if p.layout == nil && p.fit == nil {
+			seg.reframe = c.Reframe
+			if multi {
+				seg.reframe = sliceReframe(c.Reframe, base, base+dur)
+			}
+			if len(seg.reframe) == 0 {
+				seg.reframe = s.spanReframe(ctx, sp)
+			}
+		}
+		var segBlocks []CaptionBlock
+		var segSpeech []video.TimeRange
+		if needTranscript {
+			t, ok := transcripts[sp.VideoID]
+			if !ok {
+				t, _ = s.transcriptionSvc.GetByVideoID(ctx, sp.VideoID.String())
+				transcripts[sp.VideoID] = t
+			}
+			cut := cutSpan(t, style, sp.StartTime, sp.EndTime, s.fillers)
+			// Multi-segment clips only cut pauses found in transcripts: finding them during the render
+			// would move the later segments after their captions were timed.
+			seg.cuts, seg.keep, seg.probeSilence = editRanges(cut.edits), cut.keep, cut.probeSilence && !multi
+			if t != nil {
+				hasTranscript = true
+				if style.CaptionEnabled {
+					segBlocks = ShiftBlocks(BlocksFromSegments(t.Segments, style, sp.StartTime, sp.EndTime), -sp.StartTime)
+					if seg.keep != nil {
+						segBlocks = RetimeBlocks(segBlocks, seg.keep)
+					}
+				}
+				if hasMusic {
+					segSpeech = SpeechRanges(t.Segments, sp.StartTime, sp.EndTime)
+					if seg.keep != nil {
+						segSpeech = remapRanges(seg.keep, segSpeech)
+					}
+				}
+			}
+		}
+		length := dur
+		if seg.keep != nil {
+			length = video.KeptDuration(seg.keep)
+		}
+		p.segments = append(p.segments, seg)
+		blocks, speech, lengths = append(blocks, segBlocks), append(speech, segSpeech), append(lengths, length)
+		base += dur
+	}
+	if hasTranscript {
+		if style.CaptionEnabled {
+			all := blocks[0]
+			if multi {
+				all = stitchBlocks(blocks, lengths, p.transition)
+			}
+			p.captions = toASS(all, style, p.width, p.height, safe)
+		}
+		if hasMusic {
+			p.speech = speech[0]
+			if multi {
+				p.speech = stitchRanges(speech, lengths, p.transition)
+			}
+		}
+	}
+	p.key = s.renderKey(ctx, p)
+	return p, nil
+}
+
+// clipFit returns the fit options for c, or nil when it is cropped (the default, which keeps render keys
+// from before fit modes unchanged).
+func clipFit(c *domain.Clip) *video.FitOptions {
+	if c.FitMode == "" || c.FitMode == video.FitCrop {
+		return nil
+	}
+	opts := &video.FitOptions{Mode: c.FitMode, Color: video.DefaultFitColor}
+	if c.FitColor != nil && *c.FitColor != "" {
+		opts.Color = *c.FitColor
+	}
+	return opts
+}
+
+// renderKey hashes every input that affects the rendered bytes: the source object, time range, output
+// size, cut mode, layout, fit or reframe keyframes, style, jump cuts, the caption script actually burned in,
+// the referenced logo and music, every segment of a multi-segment clip and the export preset.
+// It returns "" when an input cannot be fingerprinted, which disables the cache for that render.
+func (s *RenderingService) renderKey(ctx context.Context, p *renderPlan) string {
+	source, err := s.assetFingerprint(ctx, p.video.StoragePath)
+	if err != nil {
+		return ""
+	}
+	in := renderKeyInput{
+		Version:     renderCacheVersion,
+		Source:      source,
+		Start:       p.clip.StartTime,
+		End:         p.clip.EndTime,
+		AspectRatio: p.clip.AspectRatio,
+		Width:       p.width,
+		Height:      p.height,
+		CutMode:     s.cutMode,
+		Captions:    p.captions,
+		Speech:      p.speech,
+		Cuts:        p.segments[0].cuts,
+		Silences:    p.segments[0].probeSilence,
+		Fit:         p.fit,
+	}
+	in.Reframe, in.Layout, in.Preset = p.segments[0].reframe, p.layout, p.preset
+	if len(p.segments) > 1 {
+		sources := map[string]string{}
+		for _, seg := range p.segments {
+			src, ok := sources[seg.video.StoragePath]
+			if !ok {
+				if src, err = s.assetFingerprint(ctx, seg.video.StoragePath); err != nil {
+					return ""
+				}
+				sources[seg.video.StoragePath] = src
+			}
+			in.Segments = append(in.Segments, segmentKey{Source: src, Start: seg.start, End: seg.end, Cuts: seg.cuts, Reframe: seg.reframe})
+		}
+	}
+	if p.style != nil {
+		style := *p.style
+		style.ID, style.ClipID = uuid.Nil, uuid.Nil
+		style.CreatedAt, style.UpdatedAt = time.Time{}, time.Time{}
+		in.Style = &style
+		if style.BrandLogoURL != nil && *style.BrandLogoURL != "" {
+			if in.Logo, err = s.assetFingerprint(ctx, *style.BrandLogoURL); err != nil {
+				return ""
+			}
+		}
+		if style.BackgroundMusicURL != nil && *style.BackgroundMusicURL != "" {
+			if in.Music, err = s.assetFingerprint(ctx, *style.BackgroundMusicURL); err != nil {
+				return ""
+			}
+		}
+	}
+	return hashRenderKey(in)
+}
+
+func hashRenderKey(in renderKeyInput) string {
+	data, _ := json.Marshal(in)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// assetFingerprint identifies the current content of a storage key by its ETag and size. Style assets
+// are storage keys (see validateStyleAsset), so building a render key never leaves the object store.
+func (s *RenderingService) assetFingerprint(ctx context.Context, ref string) (string, error) {
+	if strings.Contains(ref, "://") {
+		return "", fmt.Errorf("%s: assets must be storage keys", ref)
+	}
+	info, err := s.storage.Head(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s|%s|%d", ref, info.ETag, info.Size), nil
+}
+
+// outputKey is where the plan's render is stored: under its content hash when it has one, so identical
+// renders share one object, otherwise under the clip, named for the export preset if any.
+func (p *renderPlan) outputKey() string {
+	if p.key != "" {
+		return path.Join("renders", p.key, "output.mp4")
+	}
+	name := "output.mp4"
+	if p.preset != nil {
+		name = p.preset.Name + ".mp4"
+	}
+	return path.Join("renders", p.clip.ID.String(), name)
+}
+
+// ReuseCached points the clip at an existing render with identical inputs, if there is one, and marks
+// it ready without running FFmpeg. It reports whether a cached render was used.
+func (s *RenderingService) ReuseCached(ctx context.Context, clipID string) (bool, error) {
+	p, err := s.plan(ctx, clipID, nil)
+	if err != nil {
+		return false, err
+	}
+	return s.reuse(ctx, p)
+}
+
+// ReuseCachedExports is ReuseCached for each of the named export presets, recording cached renders in
+// the clip's exports. It returns the presets that still have to be rendered.
+func (s *RenderingService) ReuseCachedExports(ctx context.Context, clipID string, presets []string) ([]string, error) {
+	var pending []string
+	for _, name := range presets {
+		preset, ok := video.Presets[name]
+		if !ok {
+			return nil, presetError()
+		}
+		p, err := s.plan(ctx, clipID, &preset)
+		if err != nil {
+			return nil, err
+		}
+		cached, err := s.reuse(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if !cached {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
+
+func (s *RenderingService) reuse(ctx context.Context, p *renderPlan) (bool, error) {
+	if p.key == "" {
+		return false, nil
+	}
+	outputKey := p.outputKey()
+	if _, err := s.storage.Head(ctx, outputKey); err != nil {
+		return false, nil
+	}
+	if p.preset == nil {
+		attachClipCovers(ctx, s.storage, p.clip, outputKey)
+	}
+	return true, s.setOutput(ctx, p, outputKey)
+}
+
+// setOutput points the clip at its finished render, or records it under the plan's export preset, and
+// marks the clip ready.
+func (s *RenderingService) setOutput(ctx context.Context, p *renderPlan, outputKey string) error {
+	c := p.clip
+	if p.preset == nil {
+		c.StoragePath = &outputKey
+	} else {
+		if c.Exports == nil {
+			c.Exports = domain.Exports{}
+		}
+		c.Exports[p.preset.Name] = outputKey
+	}
+	c.Status = "ready"
+	return s.clipRepo.Update(ctx, c)
+}
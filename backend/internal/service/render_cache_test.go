@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+
+	"github.com/google/uuid"
+)
+
+func TestHashRenderKey(t *testing.T) {
+	base := renderKeyInput{Version: renderCacheVersion, Source: "videos/a.mp4|\"abc\"|100", Start: 1, End: 11, AspectRatio: "9:16", Width: 1080, Height: 1920, Captions: "Dialogue: hi"}
+	if hashRenderKey(base) != hashRenderKey(base) {
+		t.Fatal("render key is not deterministic")
+	}
+	changed := []renderKeyInput{base, base, base, base, base}
+	changed[0].End = 11.5
+	changed[1].Captions = "Dialogue: hello"
+	changed[2].Source = "videos/a.mp4|\"def\"|100"
+	changed[3].Music = "music/a.mp3|\"1\"|10"
+	reels := video.Presets[video.PresetReels]
+	changed[4].Preset = &reels
+	for i, in := range changed {
+		if hashRenderKey(in) == hashRenderKey(base) {
+			t.Errorf("change %d did not change the render key", i)
+		}
+	}
+}
+
+func TestRenderPlanOutputKey(t *testing.T) {
+	clip := &domain.Clip{ID: uuid.MustParse("6f1c2d3e-0000-4000-8000-000000000001")}
+	tiktok := video.Presets[video.PresetTikTok]
+	tests := []struct {
+		p    renderPlan
+		want string
+	}{
+		{renderPlan{clip: clip, key: "abc"}, "renders/abc/output.mp4"},
+		{renderPlan{clip: clip, key: "abc", preset: &tiktok}, "renders/abc/output.mp4"},
+		{renderPlan{clip: clip}, "renders/6f1c2d3e-0000-4000-8000-000000000001/output.mp4"},
+		{renderPlan{clip: clip, preset: &tiktok}, "renders/6f1c2d3e-0000-4000-8000-000000000001/tiktok.mp4"},
+	}
+	for _, tt := range tests {
+		if got := tt.p.outputKey(); got != tt.want {
+			t.Errorf("outputKey(key %q, preset %v) = %q, want %q", tt.p.key, tt.p.preset != nil, got, tt.want)
+		}
+	}
+}
+
+func TestPlanPresetAspectRatio(t *testing.T) {
+	f := newBatchFixture(t, 0)
+	c := f.addClip("Wide", 10, 20)
+	c.AspectRatio = "16:9"
+	tests := []struct {
+		preset        string
+		width, height int
+	}{
+		{"", 1920, 1080},
+		{video.PresetTikTok, 1080, 1920},
+		{video.PresetShorts, 1080, 1920},
+		{video.PresetLinkedIn, 1920, 1080}, // keeps the clip's aspect ratio
+	}
+	for _, tt := range tests {
+		var preset *video.Preset
+		if tt.preset != "" {
+			p := video.Presets[tt.preset]
+			preset = &p
+		}
+		p, err := f.svc.renderingSvc.plan(context.Background(), c.ID.String(), preset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.width != tt.width || p.height != tt.height {
+			t.Errorf("%q: output %dx%d, want %dx%d", tt.preset, p.width, p.height, tt.width, tt.height)
+		}
+	}
+}
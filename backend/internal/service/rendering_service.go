@@ -4,130 +4,392 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"reelcut/internal/domain"
 	"reelcut/internal/repository"
 	"reelcut/internal/video"
 )
 
+const (
+	// logoWidthRatio is the logo width at BrandLogoScale 1, as a fraction of the output width.
+	logoWidthRatio = 0.15
+	// maxLogoScale caps BrandLogoScale; styles accept scales in (0, maxLogoScale].
+	maxLogoScale = 2
+	// logoMarginRatio is the logo distance from the frame edges, as a fraction of the output width.
+	logoMarginRatio = 0.03
+	maxLogoBytes    = 10 << 20
+	maxMusicBytes   = 50 << 20
+	// musicFadeSec is the music fade-in/out length, capped at a quarter of the clip.
+	musicFadeSec = 1.5
+	// musicDuckVolume is the music gain multiplier while someone is speaking.
+	musicDuckVolume = 0.35
+)
+
+// allowedLogoTypes are the sniffed content types accepted for brand logos.
+// PNG and WebP may carry alpha; JPEG is composited as an opaque rectangle.
+var allowedLogoTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/webp": ".webp",
+}
+
 type RenderingService struct {
 	clipRepo         repository.ClipRepository
 	clipStyleRepo    repository.ClipStyleRepository
 	videoRepo        repository.VideoRepository
+	analysisRepo     repository.VideoAnalysisRepository
 	transcriptionSvc *TranscriptionService
 	storage          *StorageService
+	sources          *SourceCache
+	cutMode          video.CutMode
+	fillers          FillerDictionary
 }
 
 func NewRenderingService(
 	clipRepo repository.ClipRepository,
 	clipStyleRepo repository.ClipStyleRepository,
 	videoRepo repository.VideoRepository,
+	analysisRepo repository.VideoAnalysisRepository,
 	transcriptionSvc *TranscriptionService,
 	storage *StorageService,
+	sources *SourceCache,
+	cutMode video.CutMode,
+	fillers FillerDictionary,
 ) *RenderingService {
 	return &RenderingService{
 		clipRepo:         clipRepo,
 		clipStyleRepo:     clipStyleRepo,
 		videoRepo:         videoRepo,
+		analysisRepo:      analysisRepo,
 		transcriptionSvc:  transcriptionSvc,
 		storage:           storage,
+		sources:           sources,
+		cutMode:           cutMode,
+		fillers:           fillers,
 	}
 }
 
-// Render produces the output video for the clip and uploads to storage.
+// Render produces the output video for the clip and uploads to storage. A render whose inputs hash
+// to an existing output reuses it instead.
 func (s *RenderingService) Render(ctx context.Context, clipID string) error {
-	c, err := s.clipRepo.GetByID(ctx, clipID)
-	if err != nil || c == nil {
-		return domain.ErrNotFound
+	return s.render(ctx, clipID, nil)
+}
+
+// RenderExport renders the clip for the named export preset and records the output in the clip's
+// exports: at the preset's resolution, frame rate and bitrates, normalised to its loudness target,
+// with captions inside its safe area. A clip longer than the preset's maximum duration fails the
+// render; StartRender rejects it up front (see checkPresetDurations).
+func (s *RenderingService) RenderExport(ctx context.Context, clipID, name string) error {
+	preset, ok := video.Presets[name]
+	if !ok {
+		return presetError()
+	}
+	return s.render(ctx, clipID, &preset)
+}
+
+func (s *RenderingService) render(ctx context.Context, clipID string, preset *video.Preset) error {
+	p, err := s.plan(ctx, clipID, preset)
+	if err != nil {
+		return err
 	}
-	style, _ := s.clipStyleRepo.GetByClipID(ctx, clipID)
-	v, err := s.videoRepo.GetByID(ctx, c.VideoID.String())
-	if err != nil || v == nil {
-		return domain.ErrNotFound
+	if ok, err := s.reuse(ctx, p); ok || err != nil {
+		return err
 	}
+	c, style, v := p.clip, p.style, p.video
 	tmpDir := filepath.Join(os.TempDir(), "reelcut", "render", clipID)
 	if err := os.MkdirAll(tmpDir, 0755); err != nil {
 		return err
 	}
 	defer os.RemoveAll(tmpDir)
 
-	sourcePath := filepath.Join(tmpDir, "source.mp4")
-	rc, err := s.storage.Download(ctx, v.StoragePath)
-	if err != nil {
-		return fmt.Errorf("download source: %w", err)
+	clipDur := spansDuration(clipSpans(c))
+	w, h := p.width, p.height
+	var assPath, logoPath, musicPath string
+	if p.captions != "" {
+		assPath = filepath.Join(tmpDir, "captions.ass")
+		if err := os.WriteFile(assPath, []byte(p.captions), 0644); err != nil {
+			return err
+		}
 	}
-	f, err := os.Create(sourcePath)
-	if err != nil {
-		rc.Close()
-		return err
+	if style != nil && style.BrandLogoURL != nil && *style.BrandLogoURL != "" {
+		if logoPath, err = s.fetchLogo(ctx, c.UserID.String(), *style.BrandLogoURL, tmpDir); err != nil {
+			return err
+		}
 	}
-	_, err = io.Copy(f, rc)
-	rc.Close()
-	f.Close()
-	if err != nil {
-		return err
+	if style != nil && style.BackgroundMusicURL != nil && *style.BackgroundMusicURL != "" {
+		if musicPath, err = s.fetchMusic(ctx, c.UserID.String(), *style.BackgroundMusicURL, tmpDir); err != nil {
+			return err
+		}
 	}
 
-	stepPath := filepath.Join(tmpDir, "step1_cut.mp4")
-	if err := video.Cut(ctx, sourcePath, stepPath, c.StartTime, c.EndTime); err != nil {
-		return err
+	// One decode and one encode: trim by input seek, then scale/crop, captions, logo and music in a
+	// single filtergraph. Rendering always re-encodes, so accurate and reencode cut modes both start on
+	// the exact frame; copy mode starts on the preceding keyframe to skip decoding the partial GOP.
+	// Sources are read through WithSources, so FFmpeg only fetches the bytes around the clip.
+	// Jump cuts (silence, filler and stutter removal) trim and concatenate the kept ranges first; captions and ducking
+	// ranges were already retimed to match. Each segment of a multi-segment clip is an input of its own,
+	// trimmed and framed separately, then joined with the style's transition.
+	outPath := filepath.Join(tmpDir, "output.mp4")
+	outDur := clipDur
+	keys := make([]string, 0, len(p.segments))
+	for _, seg := range p.segments {
+		keys = append(keys, seg.video.StoragePath)
 	}
-	current := stepPath
-
-	stepPath = filepath.Join(tmpDir, "step2_crop.mp4")
-	if err := video.ResizeCrop(ctx, current, stepPath, c.AspectRatio); err != nil {
+	err = s.sources.WithSources(ctx, keys, func(sourcePaths map[string]string) error {
+		g := video.NewFilterGraph()
+		joins := make([]video.JoinSegment, 0, len(p.segments))
+		var aOut video.Stream
+		var durations []float64
+		audio := map[string]bool{}
+		hasAudio := func(path string) (bool, error) {
+			if has, ok := audio[path]; ok {
+				return has, nil
+			}
+			has, err := video.HasAudio(ctx, path)
+			audio[path] = has
+			return has, err
+		}
+		for _, seg := range p.segments {
+			sourcePath, segDur := sourcePaths[seg.video.StoragePath], seg.end-seg.start
+			keep := seg.keep
+			if seg.probeSilence {
+				silences, err := video.DetectSilences(ctx, sourcePath, seg.start, segDur, silenceNoiseDB, silenceMinDuration(style))
+				if err != nil {
+					return err
+				}
+				keep = jumpCutKeep(segDur, append(slices.Clone(seg.cuts), padCuts(silences, segDur)...))
+			}
+			src := g.AddInput(video.Input{Path: sourcePath, Start: seg.start, Duration: segDur, KeyframeSeek: s.cutMode == video.CutModeCopy})
+			vIn, aIn := video.VideoStream(src), video.AudioStream(src)
+			aOut = video.Stream(fmt.Sprintf("%d:a?", src))
+			if musicPath != "" || keep != nil || len(p.segments) > 1 {
+				// Mixing, jump cuts and joins need an audio stream: a source without one gets silence instead.
+				has, err := hasAudio(sourcePath)
+				if err != nil {
+					return err
+				}
+				if !has {
+					aIn = g.Silence(segDur)
+					aOut = aIn
+				}
+			}
+			outLen := segDur
+			if keep != nil {
+				vIn, aIn = g.JumpCut(vIn, aIn, keep)
+				aOut = aIn
+				outLen = video.KeptDuration(keep)
+			}
+			var vOut video.Stream
+			if p.layout != nil {
+				vOut = g.Layout(vIn, w, h, clipLayout(p.layout))
+			} else if p.fit != nil {
+				vOut = g.Fit(vIn, w, h, *p.fit)
+			} else {
+				vOut = g.Reframe(vIn, w, h, reframeKeys(seg.reframe, keep), reframeSource(seg.video))
+			}
+			joins = append(joins, video.JoinSegment{V: vOut, A: aIn, Duration: outLen})
+			durations = append(durations, outLen)
+		}
+		vOut, aIn := joins[0].V, joins[0].A
+		outDur = durations[0]
+		if len(joins) > 1 {
+			var fps float64
+			if v.FPS != nil {
+				fps = *v.FPS
+			}
+			vOut, aIn = g.Join(joins, p.transition, fps)
+			aOut = aIn
+			_, outDur = video.JoinOffsets(durations, p.transition)
+		}
+		if p.preset != nil && !p.preset.Fits(outDur) {
+			// Checked when the export was started; the clip or its cuts may have changed since.
+			return fmt.Errorf("the clip is %.1fs, longer than the %gs %s allows", outDur, p.preset.MaxDuration, p.preset.Label)
+		}
+		if assPath != "" {
+			vOut = g.Subtitles(vOut, assPath)
+		}
+		if logoPath != "" {
+			logo := g.AddInput(video.Input{Path: logoPath})
+			vOut = g.Overlay(vOut, video.VideoStream(logo), logoOverlayOptions(style, w))
+		}
+		if musicPath != "" {
+			music := g.AddInput(video.Input{Path: musicPath, Loop: true})
+			aOut = g.MixMusic(aIn, video.AudioStream(music), musicMixOptions(style, outDur, p.speech))
+		}
+		encode := video.H264Encode
+		if p.preset != nil {
+			var af []string
+			aOut, af = g.Loudness(aOut, *p.preset)
+			encode = append(p.preset.Encode(), af...)
+		}
+		return g.Run(video.WithExpectedDuration(ctx, outDur), outPath, []video.Stream{vOut, aOut}, encode...)
+	})
+	if err != nil {
 		return err
 	}
-	current = stepPath
 
-	if style != nil && style.CaptionEnabled {
-		t, _ := s.transcriptionSvc.GetByVideoID(ctx, c.VideoID.String())
-		if t != nil {
-			blocks := BlocksFromSegments(t.Segments, style, c.StartTime, c.EndTime)
-			srtPath := filepath.Join(tmpDir, "captions.srt")
-			if err := os.WriteFile(srtPath, []byte(ToSRT(blocks)), 0644); err != nil {
-				return err
-			}
-			stepPath = filepath.Join(tmpDir, "step3_subs.mp4")
-			if err := video.BurnSubtitles(ctx, current, srtPath, stepPath); err != nil {
+	// Covers go up first: once the output exists under its render key, other clips may reuse it.
+	// Exports share the clip's covers.
+	outputKey := p.outputKey()
+	if p.preset == nil {
+		if err := GenerateClipCovers(ctx, s.storage, c, outPath, outputKey, outDur); err != nil {
+			if ctx.Err() != nil {
 				return err
 			}
-			current = stepPath
+			slog.Warn("render: clip covers failed", "clip_id", clipID, "err", err)
 		}
 	}
+	if err := uploadFile(ctx, s.storage, outPath, outputKey, "video/mp4"); err != nil {
+		return fmt.Errorf("upload render: %w", err)
+	}
+	return s.setOutput(ctx, p, outputKey)
+}
 
-	outputKey := filepath.Join("renders", clipID, "output.mp4")
-	outPath := filepath.Join(tmpDir, "output.mp4")
-	if err := copyFile(current, outPath); err != nil {
-		return err
+// logoOverlayOptions maps the brand fields of style onto overlay options for a frame frameWidth pixels wide.
+func logoOverlayOptions(style *domain.ClipStyle, frameWidth int) video.OverlayOptions {
+	opts := video.OverlayOptions{Opacity: style.BrandWatermarkOpacity}
+	if style.BrandLogoPosition != nil {
+		opts.Position = *style.BrandLogoPosition
 	}
-	outFile, err := os.Open(outPath)
-	if err != nil {
-		return err
+	scale := style.BrandLogoScale
+	if scale <= 0 {
+		scale = 1
 	}
-	defer outFile.Close()
-	if err := s.storage.Upload(ctx, outputKey, outFile, "video/mp4"); err != nil {
-		return fmt.Errorf("upload render: %w", err)
+	if frameWidth > 0 {
+		opts.Width = int(float64(frameWidth)*logoWidthRatio*scale) &^ 1
+		opts.Margin = int(float64(frameWidth) * logoMarginRatio)
 	}
+	return opts
+}
 
-	c.StoragePath = &outputKey
-	c.Status = "ready"
-	return s.clipRepo.Update(ctx, c)
+// openAsset opens a style asset by storage key. Assets are never fetched from URLs: a style can
+// only reference objects uploaded under its owner's prefix (see validateStyleAsset).
+func (s *RenderingService) openAsset(ctx context.Context, ref string) (io.ReadCloser, error) {
+	if strings.Contains(ref, "://") {
+		return nil, fmt.Errorf("%s: assets must be storage keys", ref)
+	}
+	return s.storage.Download(ctx, ref)
 }
 
-func copyFile(src, dst string) error {
-	in, err := os.Open(src)
+// fetchLogo downloads owner's brand logo into dir and validates its format.
+func (s *RenderingService) fetchLogo(ctx context.Context, owner, ref, dir string) (string, error) {
+	if err := validateStyleAsset(owner, styleAssetLogo, ref); err != nil {
+		return "", err
+	}
+	rc, err := s.openAsset(ctx, ref)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("download logo: %w", err)
 	}
-	defer in.Close()
-	out, err := os.Create(dst)
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxLogoBytes+1))
 	if err != nil {
-		return err
+		return "", fmt.Errorf("download logo: %w", err)
+	}
+	if len(data) > maxLogoBytes {
+		return "", &domain.ValidationError{Field: "brand_logo_url", Message: "logo exceeds 10MB"}
+	}
+	ext, ok := allowedLogoTypes[http.DetectContentType(data)]
+	if !ok {
+		return "", &domain.ValidationError{Field: "brand_logo_url", Message: "allowed formats: png, jpeg, webp"}
+	}
+	path := filepath.Join(dir, "logo"+ext)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// fetchMusic downloads owner's background music track into dir.
+func (s *RenderingService) fetchMusic(ctx context.Context, owner, ref, dir string) (string, error) {
+	if err := validateStyleAsset(owner, styleAssetMusic, ref); err != nil {
+		return "", err
+	}
+	rc, err := s.openAsset(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("download music: %w", err)
+	}
+	defer rc.Close()
+	ext := strings.ToLower(filepath.Ext(ref))
+	if ext == "" {
+		ext = ".audio"
+	}
+	path := filepath.Join(dir, "music"+ext)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	n, err := io.Copy(f, io.LimitReader(rc, maxMusicBytes+1))
+	f.Close()
+	if err != nil {
+		return "", fmt.Errorf("download music: %w", err)
+	}
+	if n > maxMusicBytes {
+		return "", &domain.ValidationError{Field: "background_music_url", Message: "music exceeds 50MB"}
+	}
+	return path, nil
+}
+
+// musicMixOptions maps the audio fields of style onto mix options for a clip of clipDur seconds.
+func musicMixOptions(style *domain.ClipStyle, clipDur float64, speech []video.TimeRange) video.MixOptions {
+	fade := musicFadeSec
+	if fade > clipDur/4 {
+		fade = clipDur / 4
+	}
+	return video.MixOptions{
+		MusicVolume:    style.BackgroundMusicVolume,
+		OriginalVolume: style.OriginalAudioVolume,
+		Duration:       clipDur,
+		FadeIn:         fade,
+		FadeOut:        fade,
+		SpeechRanges:   speech,
+		DuckVolume:     musicDuckVolume,
+		AutoDuck:       len(speech) == 0,
+	}
+}
+
+// SpeechRanges returns the spans of [clipStart, clipEnd] where the transcript has speech, relative
+// to clipStart. Word timings are used when present; ranges closer than 0.6s are joined.
+func SpeechRanges(segments []domain.TranscriptSegment, clipStart, clipEnd float64) []video.TimeRange {
+	return speechRanges(segments, clipStart, clipEnd, 0.6)
+}
+
+// speechRanges is SpeechRanges joining ranges closer than mergeGap.
+func speechRanges(segments []domain.TranscriptSegment, clipStart, clipEnd, mergeGap float64) []video.TimeRange {
+	var out []video.TimeRange
+	add := func(start, end float64) {
+		if end <= clipStart || start >= clipEnd {
+			return
+		}
+		if start < clipStart {
+			start = clipStart
+		}
+		if end > clipEnd {
+			end = clipEnd
+		}
+		start -= clipStart
+		end -= clipStart
+		if n := len(out); n > 0 && start-out[n-1].End < mergeGap {
+			if end > out[n-1].End {
+				out[n-1].End = end
+			}
+			return
+		}
+		out = append(out, video.TimeRange{Start: start, End: end})
+	}
+	for _, seg := range segments {
+		if len(seg.Words) == 0 {
+			add(seg.StartTime, seg.EndTime)
+			continue
+		}
+		for _, w := range seg.Words {
+			add(w.StartTime, w.EndTime)
+		}
 	}
-	defer out.Close()
-	_, err = io.Copy(out, in)
-	return err
+	return out
 }
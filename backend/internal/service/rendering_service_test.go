@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+func TestLogoOverlayOptions(t *testing.T) {
+	topLeft := "top-left"
+	tests := []struct {
+		name  string
+		style domain.ClipStyle
+		width int
+		want  video.OverlayOptions
+	}{
+		{"defaults", domain.ClipStyle{}, 1080, video.OverlayOptions{Width: 162, Margin: 32}},
+		{"position and opacity", domain.ClipStyle{BrandLogoPosition: &topLeft, BrandLogoScale: 1, BrandWatermarkOpacity: 0.8}, 1080, video.OverlayOptions{Position: "top-left", Width: 162, Margin: 32, Opacity: 0.8}},
+		// Width stays even for the overlay scale.
+		{"scaled", domain.ClipStyle{BrandLogoScale: 1.5}, 1080, video.OverlayOptions{Width: 242, Margin: 32}},
+		{"landscape", domain.ClipStyle{BrandLogoScale: 1}, 1920, video.OverlayOptions{Width: 288, Margin: 57}},
+	}
+	for _, tt := range tests {
+		if got := logoOverlayOptions(&tt.style, tt.width); got != tt.want {
+			t.Errorf("%s: logoOverlayOptions = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+const (
+	// maxClipSegments bounds the ranges of one clip; each is another input to the render.
+	maxClipSegments = 20
+	// minSegmentSec is the shortest segment: long enough to carry a full transition at each end.
+	minSegmentSec = 2 * video.TransitionSec
+)
+
+// clipSpans returns the source ranges of c in output order: its segments, or its one range.
+func clipSpans(c *domain.Clip) domain.Segments {
+	if len(c.Segments) > 0 {
+		return c.Segments
+	}
+	return domain.Segments{{VideoID: c.VideoID, StartTime: c.StartTime, EndTime: c.EndTime}}
+}
+
+// spansDuration is the length of spans laid end to end, before jump cuts and transitions. Reframe
+// keyframes of a multi-segment clip are timed on this timeline.
+func spansDuration(spans domain.Segments) float64 {
+	var d float64
+	for _, sp := range spans {
+		d += sp.EndTime - sp.StartTime
+	}
+	return d
+}
+
+// clipTransition returns the xfade transition style asks for between segments, or "" for hard cuts.
+func clipTransition(style *domain.ClipStyle) string {
+	if style == nil || style.TransitionEffect == nil || !video.Transitions[*style.TransitionEffect] {
+		return ""
+	}
+	return *style.TransitionEffect
+}
+
+// validateTransition checks a ClipStyle.TransitionEffect; "" and "none" mean hard cuts.
+func validateTransition(t string) error {
+	if t != "" && t != "none" && !video.Transitions[t] {
+		return &domain.ValidationError{Field: "transition_effect", Message: "must be none or an FFmpeg xfade transition such as fade, dissolve, wipeleft or slideup"}
+	}
+	return nil
+}
+
+// GetSegments returns the clip's source ranges in order; a single-range clip has one.
+func (s *ClipService) GetSegments(ctx context.Context, clipID, userID string) (domain.Segments, error) {
+	c, err := s.GetByID(ctx, clipID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return clipSpans(c), nil
+}
+
+// UpdateSegments replaces the clip's source ranges. Every range must lie within one of the user's
+// videos. One range makes the clip a plain single-range clip again.
+func (s *ClipService) UpdateSegments(ctx context.Context, clipID, userID string, segs domain.Segments) (*domain.Clip, error) {
+	c, err := s.GetByID(ctx, clipID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(segs) == 0 || len(segs) > maxClipSegments {
+		return nil, &domain.ValidationError{Field: "segments", Message: fmt.Sprintf("must have between 1 and %d segments", maxClipSegments)}
+	}
+	videos := map[string]*domain.Video{}
+	for i, sp := range segs {
+		field := fmt.Sprintf("segments[%d]", i)
+		v, ok := videos[sp.VideoID.String()]
+		if !ok {
+			v, err = s.videoRepo.GetByID(ctx, sp.VideoID.String())
+			if err != nil || v == nil || v.UserID != c.UserID {
+				return nil, &domain.ValidationError{Field: field + ".video_id", Message: "video not found"}
+			}
+			videos[sp.VideoID.String()] = v
+		}
+		if err := validateSpan(field, sp, v); err != nil {
+			return nil, err
+		}
+	}
+	c.VideoID, c.StartTime, c.EndTime = segs[0].VideoID, segs[0].StartTime, segs[0].EndTime
+	c.Segments = nil
+	if len(segs) > 1 {
+		c.Segments = segs
+	}
+	dur := spansDuration(clipSpans(c))
+	c.DurationSeconds = &dur
+	if err := s.clipRepo.Update(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// validateSpan checks that the source range sp of a multi-segment clip lies within v and is long
+// enough to join. field prefixes the error's field.
+func validateSpan(field string, sp domain.ClipSegment, v *domain.Video) error {
+	switch {
+	case sp.StartTime < 0 || sp.EndTime-sp.StartTime < minSegmentSec:
+		return &domain.ValidationError{Field: field, Message: fmt.Sprintf("must start at 0 or later and last at least %gs", minSegmentSec)}
+	case v.DurationSeconds != nil && sp.EndTime > *v.DurationSeconds:
+		return &domain.ValidationError{Field: field + ".end_time", Message: fmt.Sprintf("must not be after the end of the video (%.3f)", *v.DurationSeconds)}
+	}
+	return nil
+}
+
+// stitchBlocks places each segment's caption blocks, timed from the start of that segment's output of
+// the given length, on the joined timeline. Blocks are cut at the middle of each transition so the
+// captions of neighbouring segments do not overlap.
+func stitchBlocks(parts [][]CaptionBlock, lengths []float64, transition string) []CaptionBlock {
+	offsets, _ := video.JoinOffsets(lengths, transition)
+	overlaps := video.JoinOverlaps(lengths, transition)
+	var out []CaptionBlock
+	for i, blocks := range parts {
+		from, to := 0.0, lengths[i]
+		if i > 0 {
+			from = overlaps[i-1] / 2
+		}
+		if i < len(overlaps) {
+			to -= overlaps[i] / 2
+		}
+		out = append(out, ShiftBlocks(clampBlocks(blocks, from, to), offsets[i])...)
+	}
+	return out
+}
+
+// stitchRanges places each segment's ranges, timed from the start of that segment's output, on the
+// joined timeline.
+func stitchRanges(parts [][]video.TimeRange, lengths []float64, transition string) []video.TimeRange {
+	offsets, _ := video.JoinOffsets(lengths, transition)
+	var out []video.TimeRange
+	for i, ranges := range parts {
+		for _, r := range ranges {
+			out = append(out, video.TimeRange{Start: r.Start + offsets[i], End: r.End + offsets[i]})
+		}
+	}
+	return out
+}
+
+// clampBlocks limits blocks and their words to [from, to], dropping those outside.
+func clampBlocks(blocks []CaptionBlock, from, to float64) []CaptionBlock {
+	out := make([]CaptionBlock, 0, len(blocks))
+	for _, blk := range blocks {
+		if blk.EndTime <= from || blk.StartTime >= to {
+			continue
+		}
+		blk.StartTime, blk.EndTime = max(blk.StartTime, from), min(blk.EndTime, to)
+		if len(blk.Words) > 0 {
+			blk.Words = clampWords(append([]CaptionWord(nil), blk.Words...), blk.StartTime, blk.EndTime)
+		}
+		out = append(out, blk)
+	}
+	return out
+}
+
+// sliceReframe returns the keyframes of a multi-segment clip that fall in [from, to] of its timeline,
+// relative to from. When the first of them is after from, the keyframe before the range is moved to
+// from, so the segment opens where the clip's framing was and eases on as it would have.
+func sliceReframe(keys domain.Reframe, from, to float64) domain.Reframe {
+	var out domain.Reframe
+	for i, k := range keys {
+		if k.Time < from || k.Time > to {
+			continue
+		}
+		if len(out) == 0 && k.Time > from && i > 0 {
+			prev := keys[i-1]
+			prev.Time = 0
+			out = append(out, prev)
+		}
+		k.Time -= from
+		out = append(out, k)
+	}
+	if len(out) == 0 {
+		// No keyframe inside: hold the last one before the range, if any.
+		for i := len(keys) - 1; i >= 0; i-- {
+			if keys[i].Time < from {
+				k := keys[i]
+				k.Time = 0
+				return domain.Reframe{k}
+			}
+		}
+		if len(keys) > 0 {
+			k := keys[0]
+			k.Time = 0
+			return domain.Reframe{k}
+		}
+	}
+	return out
+}
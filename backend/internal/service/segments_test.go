@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"reelcut/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestStitchBlocks(t *testing.T) {
+	parts := [][]CaptionBlock{
+		{{StartTime: 0, EndTime: 2, Text: "hook"}, {StartTime: 3.5, EndTime: 4, Text: "tail", Words: []CaptionWord{{Text: "tail", StartTime: 3.5, EndTime: 4}}}},
+		{{StartTime: 0, EndTime: 1, Text: "setup"}},
+	}
+	// Joined with a 0.5s transition: the second segment starts at 3.5, and the joint is split at 3.75.
+	got := stitchBlocks(parts, []float64{4, 3}, "fade")
+	want := []CaptionBlock{
+		{StartTime: 0, EndTime: 2, Text: "hook"},
+		{StartTime: 3.5, EndTime: 3.75, Text: "tail"},
+		{StartTime: 3.75, EndTime: 4.5, Text: "setup"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("stitchBlocks = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].StartTime != want[i].StartTime || got[i].EndTime != want[i].EndTime || got[i].Text != want[i].Text {
+			t.Errorf("block %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if w := got[1].Words; len(w) != 1 || w[0].EndTime != 3.75 {
+		t.Errorf("clamped words = %+v, want one ending at 3.75", w)
+	}
+	if parts[0][1].Words[0].EndTime != 4 {
+		t.Error("stitchBlocks modified its input words")
+	}
+
+	got = stitchBlocks(parts, []float64{4, 3}, "")
+	if len(got) != 3 || got[1].EndTime != 4 || got[2].StartTime != 4 {
+		t.Errorf("stitchBlocks(hard cut) = %+v", got)
+	}
+}
+
+func TestSliceReframe(t *testing.T) {
+	keys := domain.Reframe{{Time: 1, X: 0.2, Zoom: 1}, {Time: 6, X: 0.8, Zoom: 1}, {Time: 9, X: 0.5, Zoom: 2}}
+	tests := []struct {
+		from, to float64
+		want     domain.Reframe
+	}{
+		{0, 4, domain.Reframe{{Time: 1, X: 0.2, Zoom: 1}}},
+		{4, 8, domain.Reframe{{Time: 0, X: 0.2, Zoom: 1}, {Time: 2, X: 0.8, Zoom: 1}}},
+		{6, 8, domain.Reframe{{Time: 0, X: 0.8, Zoom: 1}}},
+		{10, 12, domain.Reframe{{Time: 0, X: 0.5, Zoom: 2}}},
+	}
+	for _, tt := range tests {
+		got := sliceReframe(keys, tt.from, tt.to)
+		if len(got) != len(tt.want) {
+			t.Errorf("sliceReframe(%g, %g) = %+v, want %+v", tt.from, tt.to, got, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("sliceReframe(%g, %g)[%d] = %+v, want %+v", tt.from, tt.to, i, got[i], tt.want[i])
+			}
+		}
+	}
+	if got := sliceReframe(nil, 0, 5); got != nil {
+		t.Errorf("sliceReframe(nil) = %+v, want nil", got)
+	}
+}
+
+func TestClipSpans(t *testing.T) {
+	vid := uuid.New()
+	c := &domain.Clip{VideoID: vid, StartTime: 10, EndTime: 25}
+	if spans := clipSpans(c); len(spans) != 1 || spans[0].VideoID != vid || spansDuration(spans) != 15 {
+		t.Errorf("clipSpans(single) = %+v", spans)
+	}
+	c.Segments = domain.Segments{{VideoID: vid, StartTime: 10, EndTime: 25}, {VideoID: uuid.New(), StartTime: 100, EndTime: 104}}
+	if spans := clipSpans(c); len(spans) != 2 || spansDuration(spans) != 19 {
+		t.Errorf("clipSpans(multi) = %+v", spans)
+	}
+}
+
+func TestValidateTransition(t *testing.T) {
+	for _, ok := range []string{"", "none", "fade", "slideup"} {
+		if err := validateTransition(ok); err != nil {
+			t.Errorf("validateTransition(%q) = %v", ok, err)
+		}
+	}
+	var ve *domain.ValidationError
+	if err := validateTransition("spin"); !errors.As(err, &ve) || ve.Field != "transition_effect" {
+		t.Errorf("validateTransition(spin) = %v, want validation error", err)
+	}
+	none := "none"
+	if got := clipTransition(&domain.ClipStyle{TransitionEffect: &none}); got != "" {
+		t.Errorf("clipTransition(none) = %q, want hard cut", got)
+	}
+}
+
+func TestValidateSpan(t *testing.T) {
+	dur := 60.0
+	v := &domain.Video{DurationSeconds: &dur}
+	tests := []struct {
+		start, end float64
+		wantField  string
+	}{
+		{10, 20, ""},
+		{-1, 20, "segments[0]"},
+		{10, 10 + minSegmentSec/2, "segments[0]"},
+		{50, 61, "segments[0].end_time"},
+	}
+	for _, tt := range tests {
+		err := validateSpan("segments[0]", domain.ClipSegment{StartTime: tt.start, EndTime: tt.end}, v)
+		var ve *domain.ValidationError
+		switch {
+		case tt.wantField == "" && err != nil:
+			t.Errorf("[%g, %g]: err = %v, want nil", tt.start, tt.end, err)
+		case tt.wantField != "" && (!errors.As(err, &ve) || ve.Field != tt.wantField):
+			t.Errorf("[%g, %g]: err = %v, want validation error on %s", tt.start, tt.end, err, tt.wantField)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sourceURLExpiry bounds how long a ranged-read URL handed to FFmpeg stays valid; it has to outlast
+// the longest single FFmpeg run.
+const sourceURLExpiry = 6 * time.Hour
+
+// seekableSourceExts are containers with an index FFmpeg can seek through using HTTP range
+// requests. Anything else is read from a full local copy.
+var seekableSourceExts = map[string]bool{
+	".mp4":  true,
+	".m4v":  true,
+	".mov":  true,
+	".mkv":  true,
+	".webm": true,
+}
+
+// sourceStore is the part of StorageService the source cache needs.
+type sourceStore interface {
+	Head(ctx context.Context, key string) (*ObjectInfo, error)
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+	GenerateInternalPresignedGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// SourceCache keeps downloaded source videos on local disk so the jobs one upload fans out into
+// (metadata, thumbnail, transcription, analysis, auto-cut, renders) share a single copy instead of
+// each downloading it. Entries are keyed by storage path and ETag, so a replaced object is fetched
+// again. Files in use are never evicted; the least recently used idle files are removed once the
+// cache grows past its size limit.
+//
+// The cache keeps its files in a reelcut-sources directory of its own inside the configured one and
+// clears its leftover files from there on start, so every worker process needs its own directory.
+type SourceCache struct {
+	storage  sourceStore
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*sourceEntry
+	lru     *list.List // of *sourceEntry, most recently used first
+	size    int64      // bytes of completed entries
+}
+
+type sourceEntry struct {
+	key   string
+	path  string
+	size  int64
+	refs  int
+	elem  *list.Element
+	ready chan struct{} // closed when the download finished; err is set before
+	err   error
+}
+
+// sourceCacheSubdir is the directory the cache creates inside the configured one.
+const sourceCacheSubdir = "reelcut-sources"
+
+// sourceCacheFile matches the files the cache writes: entries (entryID plus the source extension) and
+// downloads in progress.
+var sourceCacheFile = regexp.MustCompile(`^([0-9a-f]{32}(\.[a-z0-9]+)?|partial-[0-9]+)$`)
+
+// NewSourceCache creates a cache in a reelcut-sources directory inside dir (the temp directory's
+// reelcut directory when empty) holding at most maxBytes of idle files.
+func NewSourceCache(storage *StorageService, dir string, maxBytes int64) (*SourceCache, error) {
+	return newSourceCache(storage, dir, maxBytes)
+}
+
+func newSourceCache(storage sourceStore, dir string, maxBytes int64) (*SourceCache, error) {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "reelcut")
+	}
+	dir = filepath.Join(dir, sourceCacheSubdir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	// Files left by a previous process are not tracked, so they would never be evicted.
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.Type().IsRegular() && sourceCacheFile.MatchString(f.Name()) {
+			if err := os.Remove(filepath.Join(dir, f.Name())); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &SourceCache{
+		storage:  storage,
+		dir:      dir,
+		maxBytes: maxBytes,
+		entries:  map[string]*sourceEntry{},
+		lru:      list.New(),
+	}, nil
+}
+
+// Acquire returns a local copy of the storage object at key, downloading it unless the cache already
+// holds (or is already fetching) its current version. The file stays on disk until release is
+// called; callers must treat it as read-only.
+func (c *SourceCache) Acquire(ctx context.Context, key string) (path string, release func(), err error) {
+	id, err := c.entryID(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	for {
+		c.mu.Lock()
+		e, ok := c.entries[id]
+		if !ok {
+			e = &sourceEntry{key: id, path: filepath.Join(c.dir, id+strings.ToLower(filepath.Ext(key))), ready: make(chan struct{})}
+			e.elem = c.lru.PushFront(e)
+			c.entries[id] = e
+		} else {
+			c.lru.MoveToFront(e.elem)
+		}
+		e.refs++
+		c.mu.Unlock()
+
+		if !ok {
+			c.fill(ctx, e, key)
+		}
+		select {
+		case <-e.ready:
+		case <-ctx.Done():
+			c.release(e)
+			return "", nil, ctx.Err()
+		}
+		if e.err != nil {
+			c.release(e)
+			// The download was started by a caller that has since gone away; fetch it ourselves.
+			if errors.Is(e.err, context.Canceled) || errors.Is(e.err, context.DeadlineExceeded) {
+				if ctx.Err() == nil {
+					continue
+				}
+			}
+			return "", nil, e.err
+		}
+		return e.path, c.releaseFunc(e), nil
+	}
+}
+
+// WithSource runs fn with an FFmpeg input for the storage object at key, for jobs that only need part
+// of it. A copy already on local disk is used when there is one. Otherwise seekable containers are
+// read straight from storage through a presigned URL, so FFmpeg fetches just the byte ranges it
+// seeks to. Other containers, and remote reads that fail, fall back to a full local copy; fn may
+// therefore run twice and must overwrite its outputs. Any other error from fn is returned as is, with
+// the presigned URL replaced by key so it never reaches logs or job error messages.
+func (c *SourceCache) WithSource(ctx context.Context, key string, fn func(input string) error) error {
+	if seekableSourceExts[strings.ToLower(filepath.Ext(key))] {
+		id, err := c.entryID(ctx, key)
+		if err != nil {
+			return err
+		}
+		if path, release, ok := c.acquireReady(id); ok {
+			defer release()
+			return fn(path)
+		}
+		url, err := c.storage.GenerateInternalPresignedGet(ctx, key, sourceURLExpiry)
+		if err == nil {
+			if err = fn(url); err == nil {
+				return nil
+			}
+			readErr := isSourceReadError(err, url)
+			err = &redactedError{err: err, secret: url, key: key}
+			if ctx.Err() != nil || !readErr {
+				return err
+			}
+		}
+		slog.Warn("source cache: ranged read failed, downloading source", "key", key, "err", err)
+	}
+	path, release, err := c.Acquire(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn(path)
+}
+
+// sourceReadMarkers are FFmpeg messages from its network layer, printed when reading an input over
+// HTTP fails.
+var sourceReadMarkers = []string{"[http @", "[https @", "[tcp @", "[tls @", "HTTP error", "Server returned", "Connection reset", "Connection timed out"}
+
+// isSourceReadError reports whether err, from running FFmpeg on url, is a failure to read the input
+// rather than of the job itself. FFmpeg reports a failing input as "<url>: <error>"; the banner only
+// quotes it ("from '<url>':").
+func isSourceReadError(err error, url string) bool {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	msg := err.Error()
+	if strings.Contains(msg, url+": ") {
+		return true
+	}
+	for _, m := range sourceReadMarkers {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedError replaces a presigned URL in an error message with the storage key it was signed for.
+type redactedError struct {
+	err         error
+	secret, key string
+}
+
+func (e *redactedError) Error() string { return strings.ReplaceAll(e.err.Error(), e.secret, e.key) }
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// WithSources is WithSource for several storage objects: fn gets an FFmpeg input for each distinct key.
+func (c *SourceCache) WithSources(ctx context.Context, keys []string, fn func(inputs map[string]string) error) error {
+	inputs := make(map[string]string, len(keys))
+	var open func(i int) error
+	open = func(i int) error {
+		if i == len(keys) {
+			return fn(inputs)
+		}
+		if _, ok := inputs[keys[i]]; ok {
+			return open(i + 1)
+		}
+		return c.WithSource(ctx, keys[i], func(input string) error {
+			inputs[keys[i]] = input
+			defer delete(inputs, keys[i])
+			return open(i + 1)
+		})
+	}
+	return open(0)
+}
+
+// entryID identifies the current version of key.
+func (c *SourceCache) entryID(ctx context.Context, key string) (string, error) {
+	info, err := c.storage.Head(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("source cache: head %s: %w", key, err)
+	}
+	sum := sha256.Sum256([]byte(key + "\x00" + info.ETag))
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// acquireReady pins entry id if it is fully downloaded, without starting a download.
+func (c *SourceCache) acquireReady(id string) (string, func(), bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[id]
+	if !ok {
+		return "", nil, false
+	}
+	select {
+	case <-e.ready:
+	default:
+		return "", nil, false
+	}
+	if e.err != nil {
+		return "", nil, false
+	}
+	e.refs++
+	c.lru.MoveToFront(e.elem)
+	return e.path, c.releaseFunc(e), true
+}
+
+func (c *SourceCache) releaseFunc(e *sourceEntry) func() {
+	var once sync.Once
+	return func() { once.Do(func() { c.release(e) }) }
+}
+
+// fill downloads key into e.path and publishes the result. Failed entries are dropped so the next
+// Acquire retries.
+func (c *SourceCache) fill(ctx context.Context, e *sourceEntry, key string) {
+	size, err := c.download(ctx, key, e.path)
+	c.mu.Lock()
+	if err != nil {
+		e.err = fmt.Errorf("source cache: download %s: %w", key, err)
+		c.lru.Remove(e.elem)
+		delete(c.entries, e.key)
+	} else {
+		e.size = size
+		c.size += size
+	}
+	close(e.ready)
+	c.evictLocked()
+	c.mu.Unlock()
+}
+
+func (c *SourceCache) download(ctx context.Context, key, path string) (int64, error) {
+	rc, err := c.storage.Download(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	f, err := os.CreateTemp(c.dir, "partial-*")
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, rc)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return 0, err
+	}
+	return n, nil
+}
+
+func (c *SourceCache) release(e *sourceEntry) {
+	c.mu.Lock()
+	e.refs--
+	c.evictLocked()
+	c.mu.Unlock()
+}
+
+// evictLocked removes idle entries, least recently used first, until the cache fits its limit.
+func (c *SourceCache) evictLocked() {
+	for el := c.lru.Back(); el != nil && c.size > c.maxBytes; {
+		e := el.Value.(*sourceEntry)
+		el = el.Prev()
+		if e.refs > 0 {
+			continue
+		}
+		c.lru.Remove(e.elem)
+		delete(c.entries, e.key)
+		c.size -= e.size
+		if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+			slog.Warn("source cache: evict failed", "path", e.path, "err", err)
+		}
+	}
+}
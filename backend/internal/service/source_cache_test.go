@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeSourceStore struct {
+	objects   map[string]string // key -> content; the content doubles as ETag
+	downloads atomic.Int32
+}
+
+func (s *fakeSourceStore) Head(ctx context.Context, key string) (*ObjectInfo, error) {
+	body, ok := s.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return &ObjectInfo{ETag: body, Size: int64(len(body))}, nil
+}
+
+func (s *fakeSourceStore) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.downloads.Add(1)
+	return io.NopCloser(strings.NewReader(s.objects[key])), nil
+}
+
+func (s *fakeSourceStore) GenerateInternalPresignedGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "http://storage/" + key, nil
+}
+
+func TestSourceCache_SharesConcurrentDownloads(t *testing.T) {
+	store := &fakeSourceStore{objects: map[string]string{"videos/a.mp4": "aaaa"}}
+	cache, err := newSourceCache(store, t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	paths := make([]string, 8)
+	for i := range paths {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path, release, err := cache.Acquire(context.Background(), "videos/a.mp4")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+			paths[i] = path
+		}(i)
+	}
+	wg.Wait()
+	if n := store.downloads.Load(); n != 1 {
+		t.Errorf("downloads = %d, want 1", n)
+	}
+	data, err := os.ReadFile(paths[0])
+	if err != nil || string(data) != "aaaa" {
+		t.Errorf("cached file = %q, %v", data, err)
+	}
+}
+
+func TestSourceCache_ClearsOnlyItsOwnFiles(t *testing.T) {
+	dir := t.TempDir()
+	own := filepath.Join(dir, sourceCacheSubdir)
+	if err := os.MkdirAll(own, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]bool{ // path -> kept
+		filepath.Join(dir, "unrelated.mp4"):                        true,
+		filepath.Join(own, "notes.txt"):                            true,
+		filepath.Join(own, "0123456789abcdef0123456789abcdef.mp4"): false,
+		filepath.Join(own, "partial-12345"):                        false,
+	}
+	for path := range files {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := newSourceCache(&fakeSourceStore{}, dir, 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	for path, kept := range files {
+		if _, err := os.Stat(path); (err == nil) != kept {
+			t.Errorf("%s: exists = %v, want %v", path, err == nil, kept)
+		}
+	}
+}
+
+func TestSourceCache_EvictsIdleLRUAndRefetchesChangedETag(t *testing.T) {
+	store := &fakeSourceStore{objects: map[string]string{"a.mp4": "aaaa", "b.mp4": "bbbb", "c.mp4": "cccc"}}
+	cache, err := newSourceCache(store, t.TempDir(), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	acquire := func(key string) (string, func()) {
+		path, release, err := cache.Acquire(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path, release
+	}
+
+	pathA, releaseA := acquire("a.mp4")
+	_, releaseB := acquire("b.mp4")
+	releaseB()
+	// a is in use and b is idle: adding c must evict b, not a.
+	_, releaseC := acquire("c.mp4")
+	if _, err := os.Stat(pathA); err != nil {
+		t.Errorf("in-use entry evicted: %v", err)
+	}
+	releaseA()
+	releaseC()
+	if got := len(cache.entries); got != 2 {
+		t.Errorf("entries = %d, want 2", got)
+	}
+
+	before := store.downloads.Load()
+	_, release := acquire("c.mp4")
+	release()
+	if store.downloads.Load() != before {
+		t.Error("unchanged object downloaded again")
+	}
+	store.objects["c.mp4"] = "CCCC"
+	path, release := acquire("c.mp4")
+	defer release()
+	if data, _ := os.ReadFile(path); string(data) != "CCCC" {
+		t.Errorf("changed object served stale content %q", data)
+	}
+}
+
+func TestSourceCache_WithSource(t *testing.T) {
+	store := &fakeSourceStore{objects: map[string]string{"a.mp4": "aaaa", "a.ts": "tttt"}}
+	cache, err := newSourceCache(store, t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	var inputs []string
+	record := func(input string) error {
+		inputs = append(inputs, input)
+		return nil
+	}
+
+	// Seekable container: read remotely, nothing downloaded.
+	if err := cache.WithSource(ctx, "a.mp4", record); err != nil {
+		t.Fatal(err)
+	}
+	if inputs[0] != "http://storage/a.mp4" || store.downloads.Load() != 0 {
+		t.Errorf("seekable source: input %q, %d downloads", inputs[0], store.downloads.Load())
+	}
+
+	// Failed remote read: falls back to a local copy.
+	inputs = nil
+	err = cache.WithSource(ctx, "a.mp4", func(input string) error {
+		inputs = append(inputs, input)
+		if strings.HasPrefix(input, "http") {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	})
+	if err != nil || len(inputs) != 2 || store.downloads.Load() != 1 {
+		t.Fatalf("fallback: err %v, inputs %q, %d downloads", err, inputs, store.downloads.Load())
+	}
+
+	// A local copy, once there, is preferred over the remote URL.
+	inputs = nil
+	if err := cache.WithSource(ctx, "a.mp4", record); err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasPrefix(inputs[0], "http") {
+		t.Errorf("cached source read remotely: %q", inputs[0])
+	}
+
+	// Non-seekable container: always a full download.
+	inputs = nil
+	if err := cache.WithSource(ctx, "a.ts", record); err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasPrefix(inputs[0], "http") || store.downloads.Load() != 2 {
+		t.Errorf("non-seekable source: input %q, %d downloads", inputs[0], store.downloads.Load())
+	}
+}
+
+func TestSourceCache_WithSourceErrors(t *testing.T) {
+	store := &fakeSourceStore{objects: map[string]string{"a.mp4": "aaaa", "b.mp4": "bbbb"}}
+	cache, err := newSourceCache(store, t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// An FFmpeg failure unrelated to reading the input is returned without a download, and without
+	// the presigned URL.
+	err = cache.WithSource(ctx, "a.mp4", func(input string) error {
+		return fmt.Errorf("ffmpeg render: exit status 1 (output: Input #0, mov,mp4, from '%s':\nError initializing filter 'subtitles')", input)
+	})
+	if err == nil || store.downloads.Load() != 0 {
+		t.Fatalf("filter error: err %v, %d downloads; want the error and no download", err, store.downloads.Load())
+	}
+	if strings.Contains(err.Error(), "http://storage/") || !strings.Contains(err.Error(), "from 'a.mp4'") {
+		t.Errorf("error not redacted: %v", err)
+	}
+
+	// A failed HTTP read falls back to a local copy.
+	var inputs []string
+	err = cache.WithSource(ctx, "b.mp4", func(input string) error {
+		inputs = append(inputs, input)
+		if strings.HasPrefix(input, "http") {
+			return fmt.Errorf("ffmpeg render: exit status 1 (output: %s: Server returned 403 Forbidden)", input)
+		}
+		return nil
+	})
+	if err != nil || len(inputs) != 2 || store.downloads.Load() != 1 {
+		t.Errorf("read error: err %v, inputs %q, %d downloads; want a fallback download", err, inputs, store.downloads.Load())
+	}
+}
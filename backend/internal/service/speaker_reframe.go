@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+
+	"reelcut/internal/ai"
+	"reelcut/internal/domain"
+)
+
+const (
+	// speakerStep is the grid (seconds) the active speaker is decided on: one face sample.
+	speakerStep = 1.0 / ai.FaceSampleFPS
+	// speakerVisibleWindow is how close (seconds) a track's detection must be to count it on screen.
+	speakerVisibleWindow = 0.5
+	// speakerActivityWindow is the half-width (seconds) mouth activity is averaged over.
+	speakerActivityWindow = 1.0
+	// speakerMinHold is the shortest time (seconds) the crop stays on one face before switching.
+	speakerMinHold = 2.0
+	// speakerSwitchRatio is how much more active another face must be to take over.
+	speakerSwitchRatio = 1.5
+	// speakerPanSec is how long the crop takes to move to a new speaker.
+	speakerPanSec = 0.25
+	// speakerDeadZone is how far (fraction of the frame) a face may drift before the crop follows.
+	speakerDeadZone = 0.04
+)
+
+// AutoReframe returns crop keyframes following whoever is speaking in the clip, from the face tracks
+// of its videos' analyses, or nil when there are none. Keyframes of a multi-segment clip are timed on
+// its segments laid end to end.
+func (s *RenderingService) AutoReframe(ctx context.Context, c *domain.Clip) domain.Reframe {
+	spans := clipSpans(c)
+	if len(spans) == 1 {
+		return s.spanReframe(ctx, spans[0])
+	}
+	var out domain.Reframe
+	base := 0.0
+	for _, sp := range spans {
+		for _, k := range s.spanReframe(ctx, sp) {
+			k.Time += base
+			out = append(out, k)
+		}
+		base += sp.EndTime - sp.StartTime
+	}
+	return roundReframe(out)
+}
+
+// spanReframe is SpeakerReframe over one source range, relative to its start.
+func (s *RenderingService) spanReframe(ctx context.Context, sp domain.ClipSegment) domain.Reframe {
+	a, err := s.analysisRepo.GetByVideoID(ctx, sp.VideoID.String())
+	if err != nil || a == nil || len(a.FacesDetected) == 0 {
+		return nil
+	}
+	var tracks []ai.FaceTrack
+	if err := json.Unmarshal(a.FacesDetected, &tracks); err != nil {
+		return nil
+	}
+	return SpeakerReframe(tracks, sp.StartTime, sp.EndTime)
+}
+
+// SpeakerReframe turns face tracks into reframe keyframes for [clipStart, clipEnd], relative to
+// clipStart. The crop follows the face with the most mouth activity, switching only when another face
+// is clearly more active and the current one has been held for speakerMinHold, and ignores drift
+// inside speakerDeadZone. It returns nil when no face is seen in the range.
+func SpeakerReframe(tracks []ai.FaceTrack, clipStart, clipEnd float64) domain.Reframe {
+	// Only detections near the clip matter; trimming first keeps the per-step scans short.
+	margin := speakerActivityWindow + speakerVisibleWindow
+	trimmed := make([]ai.FaceTrack, 0, len(tracks))
+	for _, tr := range tracks {
+		var dets []ai.FaceDetection
+		for _, d := range tr.Detections {
+			if d.Timestamp >= clipStart-margin && d.Timestamp <= clipEnd+margin {
+				dets = append(dets, d)
+			}
+		}
+		if len(dets) > 0 {
+			trimmed = append(trimmed, ai.FaceTrack{ID: tr.ID, Detections: dets})
+		}
+	}
+	tracks = trimmed
+	var targets []speakerTarget
+	cur, since := -1, 0.0
+	for t := clipStart; t <= clipEnd; t += speakerStep {
+		best, bestScore := -1, 0.0
+		curVisible := false
+		for i, tr := range tracks {
+			if _, ok := nearestDetection(tr, t); !ok {
+				continue
+			}
+			score := meanActivity(tr, t-speakerActivityWindow, t+speakerActivityWindow)
+			if best < 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+			curVisible = curVisible || i == cur
+		}
+		switch {
+		case best < 0:
+			continue
+		case !curVisible:
+			cur, since = best, t
+		case best != cur && t-since >= speakerMinHold &&
+			bestScore > speakerSwitchRatio*meanActivity(tracks[cur], t-speakerActivityWindow, t+speakerActivityWindow):
+			cur, since = best, t
+		}
+		x, y := smoothedCenter(tracks[cur], t)
+		targets = append(targets, speakerTarget{T: t - clipStart, X: x, Y: y, Track: cur})
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	keys := speakerKeyframes(targets, speakerDeadZone)
+	for dead := speakerDeadZone; len(keys) > maxReframeKeyframes && dead < 1; dead *= 1.5 {
+		keys = speakerKeyframes(targets, dead)
+	}
+	if len(keys) > maxReframeKeyframes {
+		// Too many speaker changes to follow every one: keep an even spread.
+		thinned := make(domain.Reframe, 0, maxReframeKeyframes)
+		for i := 0; i < maxReframeKeyframes; i++ {
+			thinned = append(thinned, keys[i*len(keys)/maxReframeKeyframes])
+		}
+		keys = thinned
+	}
+	return roundReframe(keys)
+}
+
+// speakerTarget is where the crop should be centred at T seconds into the clip, on face Track.
+type speakerTarget struct {
+	T, X, Y float64
+	Track   int
+}
+
+// speakerKeyframes emits a keyframe whenever the target moves more than dead from the last one, and a
+// quick pan (hold, then move over speakerPanSec) whenever the speaker changes.
+func speakerKeyframes(targets []speakerTarget, dead float64) domain.Reframe {
+	keys := domain.Reframe{{Time: targets[0].T, X: targets[0].X, Y: targets[0].Y, Zoom: 1}}
+	last := targets[0]
+	for _, tg := range targets[1:] {
+		switch {
+		case tg.Track != last.Track:
+			if hold := tg.T - speakerPanSec; hold > keys[len(keys)-1].Time {
+				keys = append(keys, domain.ReframeKeyframe{Time: hold, X: last.X, Y: last.Y, Zoom: 1})
+			}
+		case math.Abs(tg.X-last.X) <= dead && math.Abs(tg.Y-last.Y) <= dead:
+			continue
+		}
+		keys = append(keys, domain.ReframeKeyframe{Time: tg.T, X: tg.X, Y: tg.Y, Zoom: 1})
+		last = tg
+	}
+	return keys
+}
+
+// nearestDetection returns the detection of tr closest to t within speakerVisibleWindow.
+func nearestDetection(tr ai.FaceTrack, t float64) (ai.FaceDetection, bool) {
+	var best ai.FaceDetection
+	bestDist := speakerVisibleWindow
+	found := false
+	for _, d := range tr.Detections {
+		if dist := math.Abs(d.Timestamp - t); dist <= bestDist {
+			best, bestDist, found = d, dist, true
+		}
+	}
+	return best, found
+}
+
+// meanActivity is the mean mouth activity of tr's detections in [from, to].
+func meanActivity(tr ai.FaceTrack, from, to float64) float64 {
+	var sum float64
+	var n int
+	for _, d := range tr.Detections {
+		if d.Timestamp >= from && d.Timestamp <= to {
+			sum += d.Activity
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// smoothedCenter is the mean face centre of tr's detections within speakerVisibleWindow of t, which
+// steadies the jitter of per-frame detection.
+func smoothedCenter(tr ai.FaceTrack, t float64) (x, y float64) {
+	var n int
+	for _, d := range tr.Detections {
+		if math.Abs(d.Timestamp-t) <= speakerVisibleWindow {
+			cx, cy := d.Center()
+			x, y = x+cx, y+cy
+			n++
+		}
+	}
+	if n == 0 {
+		return 0.5, 0.5
+	}
+	return x / float64(n), y / float64(n)
+}
+
+// roundReframe rounds keyframe times to milliseconds and positions to 1/1000 of the frame, which keeps
+// the render key stable against floating-point noise.
+func roundReframe(keys domain.Reframe) domain.Reframe {
+	r := func(v float64) float64 { return math.Round(v*1000) / 1000 }
+	for i := range keys {
+		keys[i].Time, keys[i].X, keys[i].Y = r(keys[i].Time), r(keys[i].X), r(keys[i].Y)
+	}
+	return keys
+}
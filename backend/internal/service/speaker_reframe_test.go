@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+
+	"reelcut/internal/ai"
+)
+
+// speakerTrack is a still face at x that talks (high mouth activity) during [talkFrom, talkTo).
+func speakerTrack(id int, x float64, dur, talkFrom, talkTo float64) ai.FaceTrack {
+	tr := ai.FaceTrack{ID: id}
+	for ts := 0.0; ts <= dur; ts += 0.2 {
+		d := ai.FaceDetection{Timestamp: ts, Bbox: []float64{x - 0.05, 0.35, 0.1, 0.1}, Confidence: 10, Activity: 0.01}
+		if ts >= talkFrom && ts < talkTo {
+			d.Activity = 0.2
+		}
+		tr.Detections = append(tr.Detections, d)
+	}
+	return tr
+}
+
+func TestSpeakerReframe_FollowsSpeaker(t *testing.T) {
+	tracks := []ai.FaceTrack{
+		speakerTrack(1, 0.25, 20, 0, 8),
+		speakerTrack(2, 0.75, 20, 8, 20),
+	}
+	keys := SpeakerReframe(tracks, 2, 14)
+	if len(keys) == 0 {
+		t.Fatal("SpeakerReframe returned no keyframes")
+	}
+	if k := keys[0]; k.Time != 0 || k.X != 0.25 || k.Y != 0.4 || k.Zoom != 1 {
+		t.Errorf("first keyframe = %+v, want the left speaker at 0", k)
+	}
+	last := keys[len(keys)-1]
+	if last.X != 0.75 {
+		t.Errorf("last keyframe = %+v, want the right speaker", last)
+	}
+	// The switch comes once the right speaker's averaged activity clearly leads, shortly after 8s
+	// (6s into the clip), as a quick pan.
+	if len(keys) != 3 || keys[1].X != 0.25 || keys[2].Time-keys[1].Time > speakerPanSec+1e-9 || keys[2].Time < 5 || keys[2].Time > 7 {
+		t.Errorf("keyframes = %+v, want hold then a %.2fs pan to the right speaker around 6s", keys, speakerPanSec)
+	}
+}
+
+func TestSpeakerReframe_HoldsThroughBriefInterjection(t *testing.T) {
+	tracks := []ai.FaceTrack{
+		speakerTrack(1, 0.25, 10, 0, 10),
+		speakerTrack(2, 0.75, 10, 1, 1.6),
+	}
+	keys := SpeakerReframe(tracks, 0, 10)
+	for _, k := range keys {
+		if k.X != 0.25 {
+			t.Errorf("keyframes = %+v, want the crop to stay on the main speaker", keys)
+			break
+		}
+	}
+}
+
+func TestSpeakerReframe_NoFaces(t *testing.T) {
+	if keys := SpeakerReframe([]ai.FaceTrack{speakerTrack(1, 0.5, 5, 0, 5)}, 30, 40); keys != nil {
+		t.Errorf("SpeakerReframe outside the tracks = %+v, want nil", keys)
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"time"
@@ -23,9 +24,10 @@ type S3Config struct {
 }
 
 type StorageService struct {
-	client        *s3.Client
-	presignClient *s3.PresignClient // uses PublicEndpoint when set so presigned URLs work from browser
-	bucket        string
+	client          *s3.Client
+	presignClient   *s3.PresignClient // uses PublicEndpoint when set so presigned URLs work from browser
+	internalPresign *s3.PresignClient // uses Endpoint, for URLs read by this backend (e.g. FFmpeg inputs)
+	bucket          string
 }
 
 func NewStorageService(cfg S3Config) (*StorageService, error) {
@@ -46,7 +48,7 @@ func NewStorageService(cfg S3Config) (*StorageService, error) {
 		BaseEndpoint: aws.String(endpointForPresign),
 		UsePathStyle: true, // MinIO expects path-style for presigned URLs
 	}))
-	return &StorageService{client: client, presignClient: presignClient, bucket: cfg.Bucket}, nil
+	return &StorageService{client: client, presignClient: presignClient, internalPresign: s3.NewPresignClient(client), bucket: cfg.Bucket}, nil
 }
 
 func (s *StorageService) Upload(ctx context.Context, key string, body io.Reader, contentType string) error {
@@ -78,6 +80,40 @@ func (s *StorageService) Delete(ctx context.Context, key string) error {
 	return err
 }
 
+// ErrObjectNotFound is returned by Head when the key does not exist.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo is the metadata returned by Head.
+type ObjectInfo struct {
+	Size         int64
+	ETag         string
+	LastModified time.Time
+}
+
+// Head returns the size, ETag and modification time of key without downloading it.
+func (s *StorageService) Head(ctx context.Context, key string) (*ObjectInfo, error) {
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		var status interface{ HTTPStatusCode() int }
+		if errors.As(err, &notFound) || (errors.As(err, &status) && status.HTTPStatusCode() == 404) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	info := &ObjectInfo{ETag: aws.ToString(out.ETag)}
+	if out.ContentLength != nil {
+		info.Size = *out.ContentLength
+	}
+	if out.LastModified != nil {
+		info.LastModified = *out.LastModified
+	}
+	return info, nil
+}
+
 func (s *StorageService) GeneratePresignedPut(ctx context.Context, key string, contentType string, expiry time.Duration) (string, error) {
 	req, err := s.presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(s.bucket),
@@ -105,6 +141,21 @@ func (s *StorageService) GeneratePresignedGet(ctx context.Context, key string, e
 	return req.URL, nil
 }
 
+// GenerateInternalPresignedGet returns a GET URL for key that is reachable from the backend itself.
+// FFmpeg can read it with HTTP range requests, seeking into the object without downloading it whole.
+func (s *StorageService) GenerateInternalPresignedGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	req, err := s.internalPresign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = expiry
+	})
+	if err != nil {
+		return "", fmt.Errorf("presign get: %w", err)
+	}
+	return req.URL, nil
+}
+
 // Multipart upload (resumable)
 
 type CompletedPart struct {
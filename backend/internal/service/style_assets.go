@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"reelcut/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Style assets are files a clip style references: the brand logo and background music. They are uploaded to storage with a
+// presigned PUT under the user's prefix and referenced by key, so renders only ever read objects the
+// user owns and never fetch arbitrary URLs.
+const (
+	styleAssetLogo  = "logo"
+	styleAssetMusic = "music"
+)
+
+// styleAssetFields names the style field that references each kind of asset.
+var styleAssetFields = map[string]string{
+	styleAssetLogo:  "brand_logo_url",
+	styleAssetMusic: "background_music_url",
+}
+
+// styleAssetTypes are the content types accepted for each kind of asset, with the file extension
+// they are stored under.
+var styleAssetTypes = map[string]map[string]string{
+	styleAssetLogo:  allowedLogoTypes,
+	styleAssetMusic: allowedMusicTypes,
+}
+
+// allowedMusicTypes are the content types accepted for background music.
+var allowedMusicTypes = map[string]string{
+	"audio/mpeg": ".mp3",
+	"audio/mp4":  ".m4a",
+	"audio/aac":  ".aac",
+	"audio/wav":  ".wav",
+	"audio/ogg":  ".ogg",
+	"audio/flac": ".flac",
+}
+
+// styleAssetPrefix is the storage prefix of a user's assets of one kind.
+func styleAssetPrefix(userID, kind string) string {
+	return path.Join("assets", userID, kind) + "/"
+}
+
+// validateStyleAsset checks that key is a storage key of one of the user's uploaded assets of kind.
+func validateStyleAsset(userID, kind, key string) error {
+	if !strings.HasPrefix(key, styleAssetPrefix(userID, kind)) || path.Clean(key) != key {
+		return &domain.ValidationError{Field: styleAssetFields[kind], Message: "must be the key of an asset uploaded through the style asset upload URL"}
+	}
+	return nil
+}
+
+// StyleAssetUploadURL returns a presigned PUT URL for a new style asset of kind ("logo" or "music") and the key to
+// set on the style once the upload has finished.
+func (s *ClipService) StyleAssetUploadURL(ctx context.Context, clipID, userID, kind, contentType string) (uploadURL, key string, err error) {
+	if _, err := s.GetByID(ctx, clipID, userID); err != nil {
+		return "", "", err
+	}
+	types, ok := styleAssetTypes[kind]
+	if !ok {
+		return "", "", &domain.ValidationError{Field: "kind", Message: "must be logo or music"}
+	}
+	ext, ok := types[contentType]
+	if !ok {
+		return "", "", &domain.ValidationError{Field: "content_type", Message: fmt.Sprintf("not accepted for a %s", kind)}
+	}
+	key = styleAssetPrefix(userID, kind) + uuid.New().String() + ext
+	uploadURL, err = s.storage.GeneratePresignedPut(ctx, key, contentType, 15*time.Minute)
+	if err != nil {
+		return "", "", fmt.Errorf("presigned put: %w", err)
+	}
+	return uploadURL, key, nil
+}
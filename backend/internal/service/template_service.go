@@ -19,6 +19,9 @@ func NewTemplateService(templateRepo repository.TemplateRepository) *TemplateSer
 }
 
 func (s *TemplateService) Create(ctx context.Context, userID *uuid.UUID, name, category string, isPublic bool, styleConfig json.RawMessage) (*domain.Template, error) {
+	if err := validateStyleConfig(styleConfig); err != nil {
+		return nil, err
+	}
 	t := &domain.Template{
 		ID:          uuid.New(),
 		UserID:      userID,
@@ -49,6 +52,9 @@ func (s *TemplateService) List(ctx context.Context, userID *string, publicOnly b
 }
 
 func (s *TemplateService) Update(ctx context.Context, t *domain.Template) error {
+	if err := validateStyleConfig(t.StyleConfig); err != nil {
+		return err
+	}
 	return s.templateRepo.Update(ctx, t)
 }
 
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/video"
+)
+
+// Video.RenditionsStatus values.
+const (
+	RenditionsPending    = "pending"
+	RenditionsProcessing = "processing"
+	RenditionsReady      = "ready"
+	RenditionsFailed     = "failed"
+)
+
+const (
+	// renditionURLExpiry is how long segment URLs in a served playlist stay valid; players fetch a
+	// VOD playlist once, so it has to cover a whole viewing session.
+	renditionURLExpiry = 6 * time.Hour
+	maxPlaylistBytes   = 1 << 20
+)
+
+// RenditionsPrefix is the storage prefix of a video's playback renditions.
+func RenditionsPrefix(videoID string) string {
+	return path.Join("renditions", videoID)
+}
+
+// HLSPlaylist returns the playlist name ("master.m3u8" or "<rendition>/index.m3u8") of the video's HLS
+// ladder, rewritten for the browser: segments point at presigned storage URLs, while variant
+// playlists stay relative, with query appended, so players fetch them back through the API.
+func (s *VideoService) HLSPlaylist(ctx context.Context, v *domain.Video, name, query string) (string, error) {
+	if v.HLSPath == nil || *v.HLSPath == "" {
+		return "", domain.ErrNotFound
+	}
+	name = path.Clean("/" + name)[1:]
+	if !strings.HasSuffix(name, ".m3u8") {
+		return "", domain.ErrNotFound
+	}
+	key := path.Join(path.Dir(*v.HLSPath), name)
+	rc, err := s.storage.Download(ctx, key)
+	if err != nil {
+		return "", domain.ErrNotFound
+	}
+	defer rc.Close()
+	body, err := io.ReadAll(io.LimitReader(rc, maxPlaylistBytes))
+	if err != nil {
+		return "", err
+	}
+	dir := path.Dir(key)
+	return rewritePlaylist(string(body), func(uri string) (string, error) {
+		if strings.HasSuffix(uri, ".m3u8") {
+			if query == "" {
+				return uri, nil
+			}
+			return uri + "?" + query, nil
+		}
+		return s.storage.GeneratePresignedGet(ctx, path.Join(dir, uri), renditionURLExpiry)
+	})
+}
+
+// rewritePlaylist passes every URI line of an M3U8 playlist through rewrite. Tags and comments are
+// kept as they are; absolute URIs are left alone.
+func rewritePlaylist(body string, rewrite func(uri string) (string, error)) (string, error) {
+	var b strings.Builder
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line != "" && !strings.HasPrefix(line, "#") && !strings.Contains(line, "://") {
+			uri, err := rewrite(line)
+			if err != nil {
+				return "", fmt.Errorf("rewrite %s: %w", line, err)
+			}
+			line = uri
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String(), sc.Err()
+}
+
+// WaveformKey is the storage key of zoom level zoom (an index into video.WaveformZoomLevels) of the
+// video's audio waveform, in audiowaveform binary format.
+func WaveformKey(videoID string, zoom int) string {
+	return path.Join("waveforms", videoID, fmt.Sprintf("%d.dat", zoom))
+}
+
+// Waveform loads zoom level zoom of the video's waveform. It returns domain.ErrNotFound until the
+// waveform job has run.
+func (s *VideoService) Waveform(ctx context.Context, v *domain.Video, zoom int) (*video.Waveform, error) {
+	if zoom < 0 || zoom >= len(video.WaveformZoomLevels) {
+		return nil, &domain.ValidationError{Field: "zoom", Message: fmt.Sprintf("must be between 0 and %d", len(video.WaveformZoomLevels)-1)}
+	}
+	key := WaveformKey(v.ID.String(), zoom)
+	if _, err := s.storage.Head(ctx, key); err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	rc, err := s.storage.Download(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	var w video.Waveform
+	if err := w.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// ThumbnailTrackName is the WebVTT thumbnail track stored with a video's sprite sheets.
+const ThumbnailTrackName = "thumbnails.vtt"
+
+// SpritesPrefix is the storage prefix of a video's timeline sprite sheets and thumbnail track.
+func SpritesPrefix(videoID string) string {
+	return path.Join("sprites", videoID)
+}
+
+// ThumbnailTrack returns the video's WebVTT thumbnail track with each cue pointing at a presigned URL
+// of its sprite sheet. It returns domain.ErrNotFound until the sprites job has run.
+func (s *VideoService) ThumbnailTrack(ctx context.Context, v *domain.Video) (string, error) {
+	prefix := SpritesPrefix(v.ID.String())
+	key := path.Join(prefix, ThumbnailTrackName)
+	if _, err := s.storage.Head(ctx, key); err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return "", domain.ErrNotFound
+		}
+		return "", err
+	}
+	rc, err := s.storage.Download(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	body, err := io.ReadAll(io.LimitReader(rc, maxPlaylistBytes))
+	if err != nil {
+		return "", err
+	}
+	urls := map[string]string{} // sheets are shared by many cues
+	return rewriteThumbnailTrack(string(body), func(uri string) (string, error) {
+		if u, ok := urls[uri]; ok {
+			return u, nil
+		}
+		u, err := s.storage.GeneratePresignedGet(ctx, path.Join(prefix, path.Base(uri)), renditionURLExpiry)
+		if err != nil {
+			return "", err
+		}
+		urls[uri] = u
+		return u, nil
+	})
+}
+
+// rewriteThumbnailTrack passes the image URI of every cue payload (the part before the #xywh=
+// fragment) of a WebVTT thumbnail track through rewrite.
+func rewriteThumbnailTrack(body string, rewrite func(uri string) (string, error)) (string, error) {
+	var b strings.Builder
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		line := sc.Text()
+		if uri, frag, ok := strings.Cut(line, "#xywh="); ok && !strings.Contains(uri, "://") {
+			u, err := rewrite(uri)
+			if err != nil {
+				return "", fmt.Errorf("rewrite %s: %w", uri, err)
+			}
+			line = u + "#xywh=" + frag
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String(), sc.Err()
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewritePlaylist(t *testing.T) {
+	body := "#EXTM3U\n#EXT-X-TARGETDURATION:4\n#EXTINF:4.000,\nseg_00000.ts\n#EXTINF:2.500,\nseg_00001.ts\n\n#EXT-X-ENDLIST\n"
+	got, err := rewritePlaylist(body, func(uri string) (string, error) {
+		return "https://s3/renditions/v/720p/" + uri + "?sig", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "#EXTM3U\n#EXT-X-TARGETDURATION:4\n#EXTINF:4.000,\nhttps://s3/renditions/v/720p/seg_00000.ts?sig\n" +
+		"#EXTINF:2.500,\nhttps://s3/renditions/v/720p/seg_00001.ts?sig\n\n#EXT-X-ENDLIST\n"
+	if got != want {
+		t.Errorf("rewritePlaylist =\n%s\nwant\n%s", got, want)
+	}
+
+	master := "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1\n360p/index.m3u8\nhttps://cdn/other.m3u8\n"
+	got, _ = rewritePlaylist(master, func(uri string) (string, error) { return uri + "?token=t", nil })
+	if !strings.Contains(got, "\n360p/index.m3u8?token=t\n") || !strings.Contains(got, "\nhttps://cdn/other.m3u8\n") {
+		t.Errorf("master playlist rewritten as\n%s", got)
+	}
+}
+
+func TestRewriteThumbnailTrack(t *testing.T) {
+	body := "WEBVTT\n\n00:00:00.000 --> 00:00:02.000\nsprite_000.jpg#xywh=0,0,160,90\n\n" +
+		"00:00:02.000 --> 00:00:04.000\nsprite_000.jpg#xywh=160,0,160,90\n"
+	got, err := rewriteThumbnailTrack(body, func(uri string) (string, error) {
+		return "https://s3/sprites/v/" + uri + "?sig", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "WEBVTT\n\n00:00:00.000 --> 00:00:02.000\nhttps://s3/sprites/v/sprite_000.jpg?sig#xywh=0,0,160,90\n\n" +
+		"00:00:02.000 --> 00:00:04.000\nhttps://s3/sprites/v/sprite_000.jpg?sig#xywh=160,0,160,90\n"
+	if got != want {
+		t.Errorf("rewriteThumbnailTrack =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -24,6 +24,11 @@ var allowedVideoExtensions = map[string]bool{
 
 const (
 	JobTypeVideoProcessing = "video_processing"
+	// Background tasks on an upload that track their FFmpeg progress in a job of their own.
+	JobTypeVideoTranscode = "video_transcode"
+	JobTypeVideoWaveform  = "video_waveform"
+	JobTypeVideoSprites   = "video_sprites"
+	JobTypeAutoCut        = "auto_cut"
 )
 
 type VideoService struct {
@@ -85,6 +90,8 @@ func (s *VideoService) ConfirmUpload(ctx context.Context, videoID uuid.UUID) err
 	// and thumbnails, but the core actions (playback, transcription, clips)
 	// can proceed as soon as the upload is confirmed.
 	v.Status = "ready"
+	renditions := RenditionsPending
+	v.RenditionsStatus = &renditions
 	if err := s.videoRepo.Update(ctx, v); err != nil {
 		return err
 	}
@@ -106,7 +113,13 @@ func (s *VideoService) ConfirmUpload(ctx context.Context, videoID uuid.UUID) err
 	if err := s.queue.EnqueueVideoThumbnail(videoID); err != nil {
 		return err
 	}
-	return nil
+	if err := s.queue.EnqueueVideoWaveform(videoID); err != nil {
+		return err
+	}
+	if err := s.queue.EnqueueVideoSprites(videoID); err != nil {
+		return err
+	}
+	return s.queue.EnqueueVideoTranscode(videoID)
 }
 
 func (s *VideoService) GetByID(ctx context.Context, videoID, userID string) (*domain.Video, error) {
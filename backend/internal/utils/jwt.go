@@ -95,22 +95,29 @@ func ParseRefreshToken(tokenString, secret string) (userID string, err error) {
 }
 
 // ThumbnailClaims is used for short-lived tokens in thumbnail URLs (e.g. in list so <img> can load without Bearer).
+// A token is scoped to either a video or a clip.
 type ThumbnailClaims struct {
 	jwt.RegisteredClaims
 	VideoID string `json:"video_id"`
+	ClipID  string `json:"clip_id,omitempty"`
 	UserID  string `json:"user_id"`
 }
 
 func IssueThumbnailToken(videoID, userID, secret string, expiry time.Duration) (string, error) {
+	return issueThumbnailToken(ThumbnailClaims{VideoID: videoID, UserID: userID}, secret, expiry)
+}
+
+// IssueClipThumbnailToken issues a thumbnail token for a clip's cover images (thumbnail and preview).
+func IssueClipThumbnailToken(clipID, userID, secret string, expiry time.Duration) (string, error) {
+	return issueThumbnailToken(ThumbnailClaims{ClipID: clipID, UserID: userID}, secret, expiry)
+}
+
+func issueThumbnailToken(claims ThumbnailClaims, secret string, expiry time.Duration) (string, error) {
 	exp := time.Now().Add(expiry)
-	claims := ThumbnailClaims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(exp),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ID:        uuid.New().String(),
-		},
-		VideoID: videoID,
-		UserID:  userID,
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(exp),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ID:        uuid.New().String(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signed, err := token.SignedString([]byte(secret))
@@ -0,0 +1,80 @@
+package video
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Output resolution tiers, named for the short side of the frame.
+const (
+	Resolution720p  = "720p"
+	Resolution1080p = "1080p"
+	Resolution1440p = "1440p"
+	Resolution4K    = "4k"
+)
+
+// DefaultResolution is used when a clip has no resolution set.
+const DefaultResolution = Resolution1080p
+
+// DefaultAspectRatio is used when a clip's aspect ratio is empty or invalid.
+const DefaultAspectRatio = "9:16"
+
+// Resolutions maps each resolution tier to the length of the frame's short side in pixels.
+var Resolutions = map[string]int{
+	Resolution720p:  720,
+	Resolution1080p: 1080,
+	Resolution1440p: 1440,
+	Resolution4K:    2160,
+}
+
+// Aspect ratio bounds: each term at most maxAspectTerm, and the ratio no more extreme than
+// maxAspectElongation:1 either way, which keeps 4K frames within what H.264 encoders accept.
+const (
+	maxAspectTerm       = 100
+	maxAspectElongation = 3
+)
+
+// ParseAspectRatio parses a "W:H" ratio of positive integers such as "9:16", "4:5" or "2:3".
+func ParseAspectRatio(s string) (w, h int, err error) {
+	ws, hs, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("aspect ratio %q: want W:H", s)
+	}
+	w, errW := strconv.Atoi(ws)
+	h, errH := strconv.Atoi(hs)
+	if errW != nil || errH != nil || w <= 0 || h <= 0 {
+		return 0, 0, fmt.Errorf("aspect ratio %q: want positive whole numbers", s)
+	}
+	if w > maxAspectTerm || h > maxAspectTerm {
+		return 0, 0, fmt.Errorf("aspect ratio %q: terms must be at most %d", s, maxAspectTerm)
+	}
+	if w > h*maxAspectElongation || h > w*maxAspectElongation {
+		return 0, 0, fmt.Errorf("aspect ratio %q: must be between 1:%d and %d:1", s, maxAspectElongation, maxAspectElongation)
+	}
+	return w, h, nil
+}
+
+// OutputSizeAt returns the output frame size for aspectRatio at a resolution tier: the short side is
+// the tier's height and the long side follows the ratio, both rounded to even numbers as H.264 with
+// 4:2:0 chroma requires. Invalid ratios fall back to DefaultAspectRatio and unknown tiers to
+// DefaultResolution.
+func OutputSizeAt(aspectRatio, resolution string) (width, height int) {
+	rw, rh, err := ParseAspectRatio(aspectRatio)
+	if err != nil {
+		rw, rh, _ = ParseAspectRatio(DefaultAspectRatio)
+	}
+	short, ok := Resolutions[resolution]
+	if !ok {
+		short = Resolutions[DefaultResolution]
+	}
+	if rw <= rh {
+		return short, evenRound(float64(short) * float64(rh) / float64(rw))
+	}
+	return evenRound(float64(short) * float64(rw) / float64(rh)), short
+}
+
+// evenRound rounds x to the nearest even integer.
+func evenRound(x float64) int {
+	return int(x/2+0.5) * 2
+}
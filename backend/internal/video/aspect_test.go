@@ -0,0 +1,47 @@
+package video
+
+import "testing"
+
+func TestParseAspectRatio(t *testing.T) {
+	valid := map[string][2]int{"9:16": {9, 16}, "4:5": {4, 5}, "2:3": {2, 3}, "21:9": {21, 9}, " 1:1 ": {1, 1}}
+	for in, want := range valid {
+		w, h, err := ParseAspectRatio(in)
+		if err != nil || w != want[0] || h != want[1] {
+			t.Errorf("ParseAspectRatio(%q) = %d, %d, %v; want %d, %d", in, w, h, err, want[0], want[1])
+		}
+	}
+	for _, in := range []string{"", "16x9", "0:1", "-4:5", "1.5:1", "4:1", "1:4", "101:100", "a:b"} {
+		if _, _, err := ParseAspectRatio(in); err == nil {
+			t.Errorf("ParseAspectRatio(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestOutputSizeAt(t *testing.T) {
+	tests := []struct {
+		ratio, res string
+		w, h       int
+	}{
+		{"9:16", Resolution1080p, 1080, 1920},
+		{"16:9", Resolution1080p, 1920, 1080},
+		{"1:1", Resolution1080p, 1080, 1080},
+		{"4:5", Resolution1080p, 1080, 1350},
+		{"2:3", Resolution1080p, 1080, 1620},
+		{"9:16", Resolution720p, 720, 1280},
+		{"16:9", Resolution4K, 3840, 2160},
+		{"21:9", Resolution720p, 1680, 720},
+		{"5:7", Resolution720p, 720, 1008},
+		{"7:5", Resolution720p, 1008, 720},
+		{"3:7", Resolution1080p, 1080, 2520},
+		{"bogus", "", 1080, 1920},
+	}
+	for _, tt := range tests {
+		w, h := OutputSizeAt(tt.ratio, tt.res)
+		if w != tt.w || h != tt.h {
+			t.Errorf("OutputSizeAt(%q, %q) = %dx%d, want %dx%d", tt.ratio, tt.res, w, h, tt.w, tt.h)
+		}
+		if w%2 != 0 || h%2 != 0 {
+			t.Errorf("OutputSizeAt(%q, %q) = %dx%d, want even dimensions", tt.ratio, tt.res, w, h)
+		}
+	}
+}
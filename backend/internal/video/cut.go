@@ -0,0 +1,371 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CutMode selects how a cut trades speed for boundary accuracy.
+type CutMode string
+
+const (
+	// CutModeCopy stream-copies everything; boundaries snap to keyframes (fastest, Cut's behaviour).
+	CutModeCopy CutMode = "copy"
+	// CutModeAccurate re-encodes only the partial GOP at each boundary and stream-copies the middle.
+	CutModeAccurate CutMode = "accurate"
+	// CutModeReencode re-encodes the whole range.
+	CutModeReencode CutMode = "reencode"
+)
+
+// ParseCutMode validates a cut mode name; empty selects CutModeAccurate.
+func ParseCutMode(s string) (CutMode, error) {
+	switch m := CutMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return CutModeAccurate, nil
+	case CutModeCopy, CutModeAccurate, CutModeReencode:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown cut mode %q (want copy, accurate or reencode)", s)
+	}
+}
+
+// smartCutEncoders maps source codecs to the encoder used for re-encoded boundary GOPs. The
+// re-encoded parts must use the source codec so they can be concatenated with the copied middle.
+var smartCutEncoders = map[string]string{
+	"h264": "libx264",
+	"hevc": "libx265",
+}
+
+// cutCRF is the quality for re-encoded cut frames; cuts are edit sources, so keep them near-lossless.
+const cutCRF = "18"
+
+// CutWithMode trims video to [start, end] seconds using mode.
+func CutWithMode(ctx context.Context, inputPath, outputPath string, start, end float64, mode CutMode) error {
+	switch mode {
+	case CutModeAccurate:
+		return cutAccurate(ctx, inputPath, outputPath, start, end)
+	case CutModeReencode:
+		return cutReencode(ctx, inputPath, outputPath, start, end)
+	default:
+		return Cut(ctx, inputPath, outputPath, start, end)
+	}
+}
+
+// cutReencode trims with a full re-encode; input seeking is frame-accurate when transcoding.
+func cutReencode(ctx context.Context, inputPath, outputPath string, start, end float64) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+	args := []string{
+		"-y",
+		"-ss", fmt.Sprintf("%.3f", start),
+		"-i", inputPath,
+		"-t", fmt.Sprintf("%.3f", end-start),
+		"-map", "0:v:0", "-map", "0:a:0?",
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", cutCRF, "-pix_fmt", "yuv420p",
+		"-c:a", "aac", "-b:a", "192k",
+		"-movflags", "+faststart",
+		outputPath,
+	}
+	out, err := RunFFmpeg(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("ffmpeg cut: %w (output: %s)", err, string(out))
+	}
+	return nil
+}
+
+// cutAccurate re-encodes [start, first keyframe) and [last keyframe, end], stream-copies the GOPs in
+// between, concatenates the video parts and muxes audio re-encoded for the exact range. The boundary
+// encodes are pinned to the source's profile, level, pix_fmt and colour tags, and the parts are joined
+// as MPEG-TS so each keeps its own in-band SPS/PPS. Falls back to cutReencode when the codec cannot be
+// matched, a re-encoded part does not come out with the source's parameters, or the range holds fewer
+// than two keyframes.
+func cutAccurate(ctx context.Context, inputPath, outputPath string, start, end float64) error {
+	info, err := probeVideoStream(ctx, inputPath)
+	if err != nil {
+		return err
+	}
+	encode, ok := smartCutEncodeArgs(info)
+	if !ok {
+		return cutReencode(ctx, inputPath, outputPath, start, end)
+	}
+	kfs, err := keyframeTimes(ctx, inputPath, start, end)
+	if err != nil {
+		return err
+	}
+	plan, ok := planSmartCut(kfs, start, end)
+	if !ok {
+		return cutReencode(ctx, inputPath, outputPath, start, end)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(outputPath), "cut-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var parts []string
+	for i, p := range plan {
+		part := filepath.Join(tmpDir, fmt.Sprintf("part%d.ts", i))
+		args := []string{"-y", "-ss", seekTime(p.start), "-i", inputPath, "-t", fmt.Sprintf("%.3f", p.end-p.start), "-map", "0:v:0", "-an"}
+		if p.copy {
+			args = append(args, "-c:v", "copy", "-bsf:v", info.codec+"_mp4toannexb", "-avoid_negative_ts", "make_zero")
+		} else {
+			args = append(args, encode...)
+		}
+		out, err := RunFFmpeg(ctx, append(args, "-f", "mpegts", part)...)
+		if err != nil {
+			if !p.copy {
+				// The encoder cannot produce the source's format (e.g. an 8-bit-only build for a 10-bit source).
+				return cutReencode(ctx, inputPath, outputPath, start, end)
+			}
+			return fmt.Errorf("ffmpeg cut: %w (output: %s)", err, string(out))
+		}
+		if !p.copy {
+			got, err := probeVideoStream(ctx, part)
+			if err != nil {
+				return err
+			}
+			if !info.matches(got) {
+				return cutReencode(ctx, inputPath, outputPath, start, end)
+			}
+		}
+		parts = append(parts, part)
+	}
+
+	listPath := filepath.Join(tmpDir, "parts.txt")
+	var list strings.Builder
+	for _, p := range parts {
+		list.WriteString("file '" + strings.ReplaceAll(p, "'", `'\''`) + "'\n")
+	}
+	if err := os.WriteFile(listPath, []byte(list.String()), 0644); err != nil {
+		return err
+	}
+	videoPath := filepath.Join(tmpDir, "video.ts")
+	out, err := RunFFmpeg(ctx, "-y", "-f", "concat", "-safe", "0", "-i", listPath, "-c", "copy", "-f", "mpegts", videoPath)
+	if err != nil {
+		return fmt.Errorf("ffmpeg cut concat: %w (output: %s)", err, string(out))
+	}
+
+	args := []string{
+		"-y",
+		"-i", videoPath,
+		"-ss", fmt.Sprintf("%.3f", start),
+		"-i", inputPath,
+		"-t", fmt.Sprintf("%.3f", end-start),
+		"-map", "0:v", "-map", "1:a:0?",
+		"-c:v", "copy",
+		"-c:a", "aac", "-b:a", "192k",
+		"-shortest",
+		"-movflags", "+faststart",
+	}
+	if info.timescale > 0 {
+		args = append(args, "-video_track_timescale", strconv.Itoa(info.timescale))
+	}
+	out, err = RunFFmpeg(ctx, append(args, outputPath)...)
+	if err != nil {
+		return fmt.Errorf("ffmpeg cut mux: %w (output: %s)", err, string(out))
+	}
+	return nil
+}
+
+// x264Profiles maps ffprobe H.264 profile names to libx264 -profile:v values.
+var x264Profiles = map[string]string{
+	"Constrained Baseline":  "baseline",
+	"Baseline":              "baseline",
+	"Main":                  "main",
+	"High":                  "high",
+	"High 10":               "high10",
+	"High 4:2:2":            "high422",
+	"High 4:4:4 Predictive": "high444",
+}
+
+// x265Profiles maps ffprobe HEVC profile names to libx265 -profile:v values.
+var x265Profiles = map[string]string{
+	"Main":    "main",
+	"Main 10": "main10",
+}
+
+// smartCutEncodeArgs returns encoder arguments pinned to the source stream's parameters. ok is false
+// when the codec or profile cannot be reproduced, in which case the cut must be fully re-encoded.
+func smartCutEncodeArgs(info *videoStreamInfo) ([]string, bool) {
+	encoder, ok := smartCutEncoders[info.codec]
+	if !ok || info.pixFmt == "" || info.level <= 0 {
+		return nil, false
+	}
+	args := []string{"-c:v", encoder, "-preset", "veryfast", "-crf", cutCRF, "-pix_fmt", info.pixFmt}
+	switch info.codec {
+	case "h264":
+		profile, ok := x264Profiles[info.profile]
+		if !ok || info.level < 10 {
+			return nil, false
+		}
+		args = append(args, "-profile:v", profile, "-level", fmt.Sprintf("%d.%d", info.level/10, info.level%10))
+	case "hevc":
+		profile, ok := x265Profiles[info.profile]
+		if !ok {
+			return nil, false
+		}
+		// ffprobe reports general_level_idc, which is 30 times the HEVC level.
+		args = append(args, "-profile:v", profile, "-x265-params", fmt.Sprintf("level-idc=%g", float64(info.level)/30))
+	}
+	for _, c := range []struct{ flag, v string }{
+		{"-color_range", info.colorRange},
+		{"-colorspace", info.colorSpace},
+		{"-color_trc", info.colorTransfer},
+		{"-color_primaries", info.colorPrimaries},
+	} {
+		if c.v != "" && c.v != "unknown" && c.v != "reserved" {
+			args = append(args, c.flag, c.v)
+		}
+	}
+	return args, true
+}
+
+// cutPart is one piece of a smart cut: either re-encoded or stream-copied.
+type cutPart struct {
+	start, end float64
+	copy       bool
+}
+
+// keyframeEpsilon treats keyframes this close to a boundary as on it.
+const keyframeEpsilon = 0.001
+
+// planSmartCut splits [start, end] at the first and last keyframe inside it. ok is false when there
+// is no whole GOP to copy.
+func planSmartCut(keyframes []float64, start, end float64) ([]cutPart, bool) {
+	first, last := -1.0, -1.0
+	for _, k := range keyframes {
+		if k < start-keyframeEpsilon || k > end+keyframeEpsilon {
+			continue
+		}
+		if first < 0 {
+			first = k
+		}
+		last = k
+	}
+	if first < 0 || last-first <= keyframeEpsilon {
+		return nil, false
+	}
+	var plan []cutPart
+	if first-start > keyframeEpsilon {
+		plan = append(plan, cutPart{start: start, end: first})
+	}
+	plan = append(plan, cutPart{start: first, end: last, copy: true})
+	if end-last > keyframeEpsilon {
+		plan = append(plan, cutPart{start: last, end: end})
+	}
+	return plan, true
+}
+
+// seekTime formats t for -ss, rounding down to the millisecond so a seek onto a keyframe never lands
+// just after it (which would make a stream copy start at the previous keyframe).
+func seekTime(t float64) string {
+	return fmt.Sprintf("%.3f", math.Floor(t*1000)/1000)
+}
+
+// videoStreamInfo is what a smart cut needs to match the source encoding.
+type videoStreamInfo struct {
+	codec          string
+	profile        string
+	level          int
+	pixFmt         string
+	width, height  int
+	colorRange     string
+	colorSpace     string
+	colorTransfer  string
+	colorPrimaries string
+	timescale      int
+}
+
+// matches reports whether a re-encoded part can be spliced into a stream described by info.
+func (info *videoStreamInfo) matches(part *videoStreamInfo) bool {
+	return part.codec == info.codec && part.profile == info.profile && part.level == info.level &&
+		part.pixFmt == info.pixFmt && part.width == info.width && part.height == info.height
+}
+
+func probeVideoStream(ctx context.Context, inputPath string) (*videoStreamInfo, error) {
+	out, err := RunFFprobe(ctx, "-v", "error", "-select_streams", "v:0",
+		"-show_entries", "stream=codec_name,profile,level,pix_fmt,width,height,color_range,color_space,color_transfer,color_primaries,time_base",
+		"-of", "default=noprint_wrappers=1", inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe stream: %w (output: %s)", err, string(out))
+	}
+	return parseVideoStreamInfo(string(out)), nil
+}
+
+// parseVideoStreamInfo parses ffprobe key=value stream output.
+func parseVideoStreamInfo(out string) *videoStreamInfo {
+	info := &videoStreamInfo{}
+	for _, line := range strings.Split(out, "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "codec_name":
+			info.codec = v
+		case "profile":
+			info.profile = v
+		case "level":
+			info.level, _ = strconv.Atoi(v)
+		case "pix_fmt":
+			if v != "unknown" {
+				info.pixFmt = v
+			}
+		case "width":
+			info.width, _ = strconv.Atoi(v)
+		case "height":
+			info.height, _ = strconv.Atoi(v)
+		case "color_range":
+			info.colorRange = v
+		case "color_space":
+			info.colorSpace = v
+		case "color_transfer":
+			info.colorTransfer = v
+		case "color_primaries":
+			info.colorPrimaries = v
+		case "time_base":
+			if _, den, ok := strings.Cut(v, "/"); ok {
+				info.timescale, _ = strconv.Atoi(den)
+			}
+		}
+	}
+	return info
+}
+
+// keyframeTimes returns the sorted keyframe timestamps of the first video stream in [start, end].
+func keyframeTimes(ctx context.Context, inputPath string, start, end float64) ([]float64, error) {
+	out, err := RunFFprobe(ctx, "-v", "error", "-select_streams", "v:0", "-skip_frame", "nokey",
+		"-read_intervals", fmt.Sprintf("%.3f%%%.3f", start, end+keyframeEpsilon),
+		"-show_entries", "frame=pts_time", "-of", "csv=p=0", inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe keyframes: %w (output: %s)", err, string(out))
+	}
+	return parseKeyframeTimes(string(out)), nil
+}
+
+// parseKeyframeTimes parses ffprobe csv output (one pts_time per line, possibly with trailing commas).
+func parseKeyframeTimes(out string) []float64 {
+	var kfs []float64
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.Trim(strings.TrimSpace(line), ",")
+		if line == "" || line == "N/A" {
+			continue
+		}
+		if t, err := strconv.ParseFloat(line, 64); err == nil {
+			kfs = append(kfs, t)
+		}
+	}
+	sort.Float64s(kfs)
+	return kfs
+}
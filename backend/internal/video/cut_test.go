@@ -2,8 +2,11 @@ package video
 
 import (
 	"context"
+	"math"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -31,3 +34,133 @@ func TestCut_SegmentTimes(t *testing.T) {
 		t.Error("output file should not exist when cut fails")
 	}
 }
+
+func TestPlanSmartCut(t *testing.T) {
+	kfs := parseKeyframeTimes("8.000000,\n10.000000\n12.000000,\nN/A\n14.000000\n")
+	plan, ok := planSmartCut(kfs, 9.5, 13.25)
+	if !ok {
+		t.Fatal("expected a smart cut plan")
+	}
+	want := []cutPart{
+		{start: 9.5, end: 10},
+		{start: 10, end: 12, copy: true},
+		{start: 12, end: 13.25},
+	}
+	if len(plan) != len(want) {
+		t.Fatalf("plan = %+v, want %+v", plan, want)
+	}
+	for i := range want {
+		if plan[i] != want[i] {
+			t.Errorf("part %d = %+v, want %+v", i, plan[i], want[i])
+		}
+	}
+
+	// Boundaries on keyframes need no re-encoded head or tail.
+	if plan, _ := planSmartCut(kfs, 10, 14); len(plan) != 1 || !plan[0].copy {
+		t.Errorf("keyframe-aligned plan = %+v, want one copied part", plan)
+	}
+	// A single keyframe in range leaves no whole GOP to copy.
+	if _, ok := planSmartCut(kfs, 10.5, 12.5); ok {
+		t.Error("expected no plan with one keyframe in range")
+	}
+}
+
+func TestParseCutMode(t *testing.T) {
+	if m, err := ParseCutMode(""); err != nil || m != CutModeAccurate {
+		t.Errorf("empty mode = %q, %v; want accurate", m, err)
+	}
+	if m, err := ParseCutMode("Reencode"); err != nil || m != CutModeReencode {
+		t.Errorf("Reencode = %q, %v", m, err)
+	}
+	if _, err := ParseCutMode("fast"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestSmartCutEncodeArgs(t *testing.T) {
+	info := parseVideoStreamInfo("codec_name=h264\nprofile=High\nlevel=40\npix_fmt=yuv420p\nwidth=1920\nheight=1080\n" +
+		"color_range=tv\ncolor_space=bt709\ncolor_transfer=bt709\ncolor_primaries=unknown\ntime_base=1/15360\n")
+	args, ok := smartCutEncodeArgs(info)
+	if !ok {
+		t.Fatal("expected H.264 High to be reproducible")
+	}
+	got := strings.Join(args, " ")
+	for _, want := range []string{"-c:v libx264", "-pix_fmt yuv420p", "-profile:v high", "-level 4.0", "-color_range tv", "-colorspace bt709"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("args %q missing %q", got, want)
+		}
+	}
+	if strings.Contains(got, "-color_primaries") {
+		t.Errorf("args %q should skip unknown colour tags", got)
+	}
+	if info.timescale != 15360 {
+		t.Errorf("timescale = %d, want 15360", info.timescale)
+	}
+
+	hevc := &videoStreamInfo{codec: "hevc", profile: "Main 10", level: 120, pixFmt: "yuv420p10le"}
+	if args, ok := smartCutEncodeArgs(hevc); !ok || !strings.Contains(strings.Join(args, " "), "level-idc=4") {
+		t.Errorf("hevc args = %v, %v", args, ok)
+	}
+	for _, info := range []*videoStreamInfo{
+		{codec: "vp9", profile: "Profile 0", level: 40, pixFmt: "yuv420p"},
+		{codec: "h264", profile: "High 4:4:4 Intra", level: 40, pixFmt: "yuv444p"},
+		{codec: "h264", profile: "High", pixFmt: "yuv420p"},
+	} {
+		if _, ok := smartCutEncodeArgs(info); ok {
+			t.Errorf("expected full re-encode for %+v", info)
+		}
+	}
+}
+
+// TestCutAccurate_FFmpeg cuts a synthetic source off its keyframes and checks the joined output keeps
+// the source's codec parameters and decodes cleanly. Skipped when FFmpeg is not installed.
+func TestCutAccurate_FFmpeg(t *testing.T) {
+	for _, bin := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := exec.LookPath(bin); err != nil {
+			t.Skipf("%s not installed", bin)
+		}
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.mp4")
+	// Main profile without B-frames so the boundary encodes (High, with B-frames by default) must be pinned.
+	out, err := RunFFmpeg(ctx, "-y", "-f", "lavfi", "-i", "testsrc2=size=320x240:rate=25:duration=10",
+		"-f", "lavfi", "-i", "sine=frequency=440:duration=10",
+		"-c:v", "libx264", "-profile:v", "main", "-level", "3.1", "-bf", "0", "-g", "50", "-pix_fmt", "yuv420p",
+		"-c:a", "aac", "-shortest", input)
+	if err != nil {
+		t.Fatalf("generate source: %v (output: %s)", err, out)
+	}
+	output := filepath.Join(dir, "out.mp4")
+	// Keyframes every 2s: 1.3-7.5 re-encodes a head and a tail around three copied GOPs.
+	if err := CutWithMode(ctx, input, output, 1.3, 7.5, CutModeAccurate); err != nil {
+		t.Fatalf("cut: %v", err)
+	}
+
+	src, err := probeVideoStream(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := probeVideoStream(ctx, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !src.matches(got) {
+		t.Errorf("output stream %+v does not match source %+v", got, src)
+	}
+
+	counted, err := RunFFprobe(ctx, "-v", "error", "-select_streams", "v:0", "-count_frames",
+		"-show_entries", "stream=nb_read_frames", "-of", "csv=p=0", output)
+	if err != nil {
+		t.Fatalf("ffprobe frames: %v (output: %s)", err, counted)
+	}
+	frames, _ := strconv.Atoi(strings.Trim(strings.TrimSpace(string(counted)), ","))
+	if want := 6.2 * 25; math.Abs(float64(frames)-want) > 2 {
+		t.Errorf("output has %d frames, want about %.0f", frames, want)
+	}
+
+	decode, err := RunFFmpeg(ctx, "-v", "error", "-i", output, "-f", "null", "-")
+	if err != nil || strings.TrimSpace(string(decode)) != "" {
+		t.Errorf("decoding joined output: %v: %s", err, decode)
+	}
+}
@@ -9,7 +9,12 @@ import (
 	"strings"
 )
 
+// RunFFmpeg runs ffmpeg and returns its output. When ctx carries a progress callback and an expected
+// duration (WithProgress, WithExpectedDuration), progress is parsed from -progress and reported live.
 func RunFFmpeg(ctx context.Context, args ...string) ([]byte, error) {
+	if fn, dur, ok := progressFrom(ctx); ok {
+		return runFFmpegWithProgress(ctx, fn, dur, args)
+	}
 	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
 	return cmd.CombinedOutput()
 }
@@ -125,81 +130,107 @@ func Cut(ctx context.Context, inputPath, outputPath string, start, end float64)
 	return nil
 }
 
-// ResizeCrop scales and crops to aspect ratio (e.g. "9:16", "1:1", "16:9").
+// OutputSize returns the output frame size for an aspect ratio ("9:16", "4:5", "16:9", ...) at
+// DefaultResolution. See OutputSizeAt.
+func OutputSize(aspectRatio string) (width, height int) {
+	return OutputSizeAt(aspectRatio, DefaultResolution)
+}
+
+// ResizeCrop scales and centre-crops to aspect ratio (any W:H, see OutputSize).
 func ResizeCrop(ctx context.Context, inputPath, outputPath, aspectRatio string) error {
+	return ResizeFit(ctx, inputPath, outputPath, aspectRatio, FitOptions{Mode: FitCrop})
+}
+
+// BurnSubtitles burns an SRT or ASS file into video; ASS styling and PlayRes scaling are honoured.
+func BurnSubtitles(ctx context.Context, inputPath, subsPath, outputPath string) error {
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return err
 	}
-	// scale and crop: scale to cover then crop to aspect
-	var vf string
-	switch aspectRatio {
-	case "9:16":
-		vf = "scale=-2:ih*2,crop=ih*9/16:ih"
-	case "1:1":
-		vf = "scale=min(iw\\,ih):min(iw\\,ih),crop=min(iw\\,ih):min(iw\\,ih)"
-	case "16:9":
-		vf = "scale=iw*2:-2,crop=iw:iw*9/16"
-	default:
-		vf = "scale=-2:ih*2,crop=ih*9/16:ih"
-	}
 	args := []string{
 		"-y", "-i", inputPath,
-		"-vf", vf,
+		"-vf", "subtitles=" + escapeFilterPath(subsPath),
 		"-c:a", "copy",
 		outputPath,
 	}
 	out, err := RunFFmpeg(ctx, args...)
 	if err != nil {
-		return fmt.Errorf("ffmpeg resize: %w (output: %s)", err, string(out))
+		return fmt.Errorf("ffmpeg subtitles: %w (output: %s)", err, string(out))
 	}
 	return nil
 }
 
-// BurnSubtitles burns SRT file into video.
-func BurnSubtitles(ctx context.Context, inputPath, srtPath, outputPath string) error {
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return err
-	}
-	// Escape path for filter (Windows/Unix)
-	escaped := srtPath
+// escapeFilterPath escapes a file path for use as a filter option value (Windows/Unix).
+func escapeFilterPath(path string) string {
+	escaped := path
 	if filepath.Separator == '\\' {
-		escaped = strings.ReplaceAll(srtPath, "\\", "\\\\")
+		escaped = strings.ReplaceAll(path, "\\", "\\\\")
 	}
-	escaped = strings.ReplaceAll(escaped, ":", "\\:")
-	args := []string{
-		"-y", "-i", inputPath,
-		"-vf", "subtitles=" + escaped,
-		"-c:a", "copy",
-		outputPath,
+	return strings.ReplaceAll(escaped, ":", "\\:")
+}
+
+// OverlayOptions controls how OverlayImage places a logo or watermark.
+type OverlayOptions struct {
+	Position string  // top-left, top-right, bottom-left, bottom-right, center (default bottom-right)
+	Width    int     // logo width in pixels; 0 keeps the image's native width
+	Margin   int     // distance from the frame edges in pixels
+	Opacity  float64 // 0-1; 0 is treated as fully opaque
+}
+
+// OverlayPositions lists the accepted OverlayOptions.Position values.
+var OverlayPositions = map[string]bool{
+	"top-left":     true,
+	"top-right":    true,
+	"bottom-left":  true,
+	"bottom-right": true,
+	"center":       true,
+}
+
+// OverlayFilter returns the filter_complex that composites input 1 (image) onto input 0 (video).
+// The image is converted to RGBA first so PNG/WebP alpha and palette transparency survive scaling,
+// and opacity is applied to the alpha channel only.
+func OverlayFilter(opts OverlayOptions) string {
+	return "[1]" + overlayImageFilters(opts) + "[logo];[0][logo]" + overlayFilter(opts)
+}
+
+// overlayImageFilters prepares the overlay image: RGBA conversion, scaling and opacity.
+func overlayImageFilters(opts OverlayOptions) string {
+	logo := "format=rgba"
+	if opts.Width > 0 {
+		logo += fmt.Sprintf(",scale=%d:-1", opts.Width)
 	}
-	out, err := RunFFmpeg(ctx, args...)
-	if err != nil {
-		return fmt.Errorf("ffmpeg subtitles: %w (output: %s)", err, string(out))
+	if opts.Opacity > 0 && opts.Opacity < 1 {
+		logo += fmt.Sprintf(",colorchannelmixer=aa=%.2f", opts.Opacity)
 	}
-	return nil
+	return logo
 }
 
-// OverlayImage overlays image on video at position (e.g. "bottom-right").
-func OverlayImage(ctx context.Context, inputPath, imagePath, outputPath, position string) error {
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return err
-	}
-	var overlay string
-	switch position {
+// overlayFilter returns the overlay filter placing the image per opts.Position and opts.Margin.
+func overlayFilter(opts OverlayOptions) string {
+	m := opts.Margin
+	var pos string
+	switch opts.Position {
 	case "top-left":
-		overlay = "overlay=10:10"
+		pos = fmt.Sprintf("%d:%d", m, m)
 	case "top-right":
-		overlay = "overlay=main_w-overlay_w-10:10"
+		pos = fmt.Sprintf("main_w-overlay_w-%d:%d", m, m)
 	case "bottom-left":
-		overlay = "overlay=10:main_h-overlay_h-10"
-	case "bottom-right":
-		overlay = "overlay=main_w-overlay_w-10:main_h-overlay_h-10"
+		pos = fmt.Sprintf("%d:main_h-overlay_h-%d", m, m)
+	case "center":
+		pos = "(main_w-overlay_w)/2:(main_h-overlay_h)/2"
 	default:
-		overlay = "overlay=main_w-overlay_w-10:main_h-overlay_h-10"
+		pos = fmt.Sprintf("main_w-overlay_w-%d:main_h-overlay_h-%d", m, m)
+	}
+	return "overlay=" + pos + ":format=auto"
+}
+
+// OverlayImage overlays image on video using opts (position, width, opacity).
+func OverlayImage(ctx context.Context, inputPath, imagePath, outputPath string, opts OverlayOptions) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
 	}
 	args := []string{
 		"-y", "-i", inputPath, "-i", imagePath,
-		"-filter_complex", "[1]scale=iw*0.2:-1[logo];[0][logo]" + overlay,
+		"-filter_complex", OverlayFilter(opts),
 		"-c:a", "copy",
 		outputPath,
 	}
@@ -210,17 +241,92 @@ func OverlayImage(ctx context.Context, inputPath, imagePath, outputPath, positio
 	return nil
 }
 
-// MixAudio mixes background audio with video at volume (0-1).
-func MixAudio(ctx context.Context, videoInput, audioInput, outputPath string, audioVolume float64) error {
+// TimeRange is a [Start, End] span in seconds.
+type TimeRange struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// MixOptions controls how MixAudio combines background music with the video's own audio.
+type MixOptions struct {
+	MusicVolume    float64     // 0-1 gain for the music
+	OriginalVolume float64     // 0-1 gain for the video's audio
+	Duration       float64     // output length in seconds; the music is looped (input side) and trimmed to it
+	FadeIn         float64     // music fade-in seconds
+	FadeOut        float64     // music fade-out seconds
+	SpeechRanges   []TimeRange // ranges (output time) where the music is ducked
+	DuckVolume     float64     // music gain multiplier while ducked (e.g. 0.3)
+	AutoDuck       bool        // when SpeechRanges is empty, duck with a sidechain compressor keyed on the original audio
+}
+
+// duckRamp is the attack/release time in seconds for transcript-driven ducking.
+const duckRamp = 0.25
+
+// MixFilter returns the filter_complex that mixes input 1 (music) under input 0's audio into [aout].
+func MixFilter(opts MixOptions) string {
+	music := "[1:a]" + musicFilters(opts)
+	orig := fmt.Sprintf("[0:a]volume=%.2f", opts.OriginalVolume)
+	if len(opts.SpeechRanges) == 0 && opts.AutoDuck {
+		return music + "[music];" + orig + ",asplit=2[orig][sc];" +
+			"[music][sc]" + sidechainDuckFilter + "[ducked];" +
+			"[orig][ducked]" + mixFilter + "[aout]"
+	}
+	return music + "[music];" + orig + "[orig];[orig][music]" + mixFilter + "[aout]"
+}
+
+const (
+	// sidechainDuckFilter compresses the music (first input) keyed on the original audio (second input).
+	sidechainDuckFilter = "sidechaincompress=threshold=0.02:ratio=8:attack=20:release=400"
+	// mixFilter sums the original audio and music without amix's per-input attenuation.
+	mixFilter = "amix=inputs=2:duration=first:normalize=0"
+)
+
+// musicFilters returns the music chain: gain, trim, fades and transcript-driven ducking.
+func musicFilters(opts MixOptions) string {
+	music := fmt.Sprintf("volume=%.2f", opts.MusicVolume)
+	if opts.Duration > 0 {
+		music += fmt.Sprintf(",atrim=0:%.3f,asetpts=PTS-STARTPTS", opts.Duration)
+		if opts.FadeIn > 0 {
+			music += fmt.Sprintf(",afade=t=in:st=0:d=%.2f", opts.FadeIn)
+		}
+		if opts.FadeOut > 0 && opts.FadeOut < opts.Duration {
+			music += fmt.Sprintf(",afade=t=out:st=%.3f:d=%.2f", opts.Duration-opts.FadeOut, opts.FadeOut)
+		}
+	}
+	if len(opts.SpeechRanges) > 0 {
+		music += ",volume='" + duckExpr(opts.SpeechRanges, opts.DuckVolume) + "':eval=frame"
+	}
+	return music
+}
+
+// duckExpr builds a per-frame gain expression that ramps down to duck inside each range.
+func duckExpr(ranges []TimeRange, duck float64) string {
+	var dip string
+	for i, r := range ranges {
+		// 0 outside the range, ramps to 1 over duckRamp at each edge
+		d := fmt.Sprintf("clip(min((t-%.3f)/%.2f,(%.3f-t)/%.2f),0,1)", r.Start-duckRamp, duckRamp, r.End+duckRamp, duckRamp)
+		if i == 0 {
+			dip = d
+		} else {
+			dip = "max(" + dip + "," + d + ")"
+		}
+	}
+	return fmt.Sprintf("1-%.2f*%s", 1-duck, dip)
+}
+
+// MixAudio mixes background audio under the video's audio per opts; the music input is looped so
+// it always covers opts.Duration.
+func MixAudio(ctx context.Context, videoInput, audioInput, outputPath string, opts MixOptions) error {
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return err
 	}
-	vol := fmt.Sprintf("%.2f", audioVolume)
 	args := []string{
-		"-y", "-i", videoInput, "-i", audioInput,
-		"-filter_complex", "[1]volume=" + vol + "[a1];[0:a][a1]amix=inputs=2:duration=first[aout]",
+		"-y", "-i", videoInput, "-stream_loop", "-1", "-i", audioInput,
+		"-filter_complex", MixFilter(opts),
 		"-map", "0:v", "-map", "[aout]",
 		"-c:v", "copy",
+		"-c:a", "aac",
+		"-shortest",
 		outputPath,
 	}
 	out, err := RunFFmpeg(ctx, args...)
@@ -0,0 +1,180 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Stream is a filtergraph pad: an input stream specifier such as "0:v" / "0:a?" or a label
+// produced by FilterGraph (written without brackets).
+type Stream string
+
+// VideoStream returns the video stream of input i.
+func VideoStream(i int) Stream { return Stream(fmt.Sprintf("%d:v", i)) }
+
+// AudioStream returns the audio stream of input i.
+func AudioStream(i int) Stream { return Stream(fmt.Sprintf("%d:a", i)) }
+
+// isInput reports whether s refers to an input stream rather than a graph label.
+func (s Stream) isInput() bool { return strings.Contains(string(s), ":") }
+
+func (s Stream) pad() string { return "[" + string(s) + "]" }
+
+// Input is one ffmpeg input plus the demuxer options placed before its -i.
+type Input struct {
+	Path     string
+	Start    float64 // -ss; seeking on the input is fast and frame-accurate because the graph re-encodes
+	Duration float64 // -t; 0 reads to the end
+	// KeyframeSeek starts at the keyframe before Start (-noaccurate_seek): faster, not frame-accurate.
+	KeyframeSeek bool
+	Loop         bool // -stream_loop -1, e.g. for music shorter than the clip
+}
+
+// H264Encode is the default single-encode output: H.264/AAC MP4 playable everywhere.
+var H264Encode = []string{
+	"-c:v", "libx264", "-preset", "medium", "-crf", "20", "-pix_fmt", "yuv420p",
+	"-c:a", "aac", "-b:a", "192k",
+	"-movflags", "+faststart",
+}
+
+// FilterGraph composes inputs and labelled filter chains into a single ffmpeg invocation, so a
+// render with trim, scale/crop, subtitles, overlays and audio mixing is decoded and encoded once.
+type FilterGraph struct {
+	inputs []Input
+	chains []string
+	labels int
+}
+
+// NewFilterGraph returns an empty graph.
+func NewFilterGraph() *FilterGraph {
+	return &FilterGraph{}
+}
+
+// AddInput registers an input and returns its index.
+func (g *FilterGraph) AddInput(in Input) int {
+	g.inputs = append(g.inputs, in)
+	return len(g.inputs) - 1
+}
+
+// Chain appends "[in...]filters[out]" and returns the new output label.
+func (g *FilterGraph) Chain(filters string, in ...Stream) Stream {
+	return g.ChainN(filters, 1, in...)[0]
+}
+
+// ChainN is Chain for filters with several outputs (split, asplit).
+func (g *FilterGraph) ChainN(filters string, outputs int, in ...Stream) []Stream {
+	var b strings.Builder
+	for _, s := range in {
+		b.WriteString(s.pad())
+	}
+	b.WriteString(filters)
+	outs := make([]Stream, outputs)
+	for i := range outs {
+		g.labels++
+		outs[i] = Stream(fmt.Sprintf("s%d", g.labels))
+		b.WriteString(outs[i].pad())
+	}
+	g.chains = append(g.chains, b.String())
+	return outs
+}
+
+// Trim keeps [start, end] seconds of a video stream and resets timestamps to 0.
+func (g *FilterGraph) Trim(v Stream, start, end float64) Stream {
+	return g.Chain(fmt.Sprintf("trim=start=%.3f:end=%.3f,setpts=PTS-STARTPTS", start, end), v)
+}
+
+// ATrim keeps [start, end] seconds of an audio stream and resets timestamps to 0.
+func (g *FilterGraph) ATrim(a Stream, start, end float64) Stream {
+	return g.Chain(fmt.Sprintf("atrim=start=%.3f:end=%.3f,asetpts=PTS-STARTPTS", start, end), a)
+}
+
+// Silence returns duration seconds of silent 48kHz stereo audio, in place of the audio of a source that
+// has none wherever the graph filters audio.
+func (g *FilterGraph) Silence(duration float64) Stream {
+	return g.Chain(fmt.Sprintf("anullsrc=r=48000:cl=stereo,atrim=0:%.3f", duration))
+}
+
+// ScaleCrop scales v to cover width x height and centre-crops the overflow.
+func (g *FilterGraph) ScaleCrop(v Stream, width, height int) Stream {
+	return g.Chain(coverFilter(width, height), v)
+}
+
+// coverFilter scales a frame to cover width x height and centre-crops the overflow.
+func coverFilter(width, height int) string {
+	return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,setsar=1", width, height, width, height)
+}
+
+// Subtitles burns an SRT or ASS file into v.
+func (g *FilterGraph) Subtitles(v Stream, subsPath string) Stream {
+	return g.Chain("subtitles="+escapeFilterPath(subsPath), v)
+}
+
+// Overlay composites image onto v per opts (see OverlayFilter).
+func (g *FilterGraph) Overlay(v, image Stream, opts OverlayOptions) Stream {
+	logo := g.Chain(overlayImageFilters(opts), image)
+	return g.Chain(overlayFilter(opts), v, logo)
+}
+
+// MixMusic mixes music under the original audio a per opts (see MixFilter).
+func (g *FilterGraph) MixMusic(a, music Stream, opts MixOptions) Stream {
+	m := g.Chain(musicFilters(opts), music)
+	orig := fmt.Sprintf("volume=%.2f", opts.OriginalVolume)
+	if len(opts.SpeechRanges) == 0 && opts.AutoDuck {
+		split := g.ChainN(orig+",asplit=2", 2, a)
+		ducked := g.Chain(sidechainDuckFilter, m, split[1])
+		return g.Chain(mixFilter, split[0], ducked)
+	}
+	return g.Chain(mixFilter, g.Chain(orig, a), m)
+}
+
+// String returns the -filter_complex value.
+func (g *FilterGraph) String() string {
+	return strings.Join(g.chains, ";")
+}
+
+// Args returns the full ffmpeg argument list writing maps to output with the given encode options.
+func (g *FilterGraph) Args(output string, maps []Stream, encode ...string) []string {
+	args := []string{"-y"}
+	for _, in := range g.inputs {
+		if in.Loop {
+			args = append(args, "-stream_loop", "-1")
+		}
+		if in.Start > 0 {
+			if in.KeyframeSeek {
+				args = append(args, "-noaccurate_seek")
+			}
+			args = append(args, "-ss", fmt.Sprintf("%.3f", in.Start))
+		}
+		if in.Duration > 0 {
+			args = append(args, "-t", fmt.Sprintf("%.3f", in.Duration))
+		}
+		args = append(args, "-i", in.Path)
+	}
+	if len(g.chains) > 0 {
+		args = append(args, "-filter_complex", g.String())
+	}
+	for _, m := range maps {
+		if m.isInput() {
+			args = append(args, "-map", string(m))
+		} else {
+			args = append(args, "-map", m.pad())
+		}
+	}
+	args = append(args, encode...)
+	return append(args, output)
+}
+
+// Run executes the graph, writing maps to output with the given encode options.
+func (g *FilterGraph) Run(ctx context.Context, output string, maps []Stream, encode ...string) error {
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return err
+	}
+	out, err := RunFFmpeg(ctx, g.Args(output, maps, encode...)...)
+	if err != nil {
+		return fmt.Errorf("ffmpeg render: %w (output: %s)", err, string(out))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package video
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterGraph_RenderArgs(t *testing.T) {
+	g := NewFilterGraph()
+	src := g.AddInput(Input{Path: "source.mp4", Start: 12.5, Duration: 30})
+	logo := g.AddInput(Input{Path: "logo.png"})
+	music := g.AddInput(Input{Path: "music.mp3", Loop: true})
+
+	v := g.ScaleCrop(VideoStream(src), 1080, 1920)
+	v = g.Subtitles(v, "/tmp/render/captions.ass")
+	v = g.Overlay(v, VideoStream(logo), OverlayOptions{Position: "top-left", Width: 160, Margin: 32, Opacity: 0.8})
+	a := g.MixMusic(AudioStream(src), AudioStream(music), MixOptions{MusicVolume: 0.3, OriginalVolume: 1, Duration: 30})
+
+	got := g.Args("out.mp4", []Stream{v, a}, "-c:v", "libx264")
+	want := []string{
+		"-y",
+		"-ss", "12.500", "-t", "30.000", "-i", "source.mp4",
+		"-i", "logo.png",
+		"-stream_loop", "-1", "-i", "music.mp3",
+		"-filter_complex",
+		"[0:v]scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920,setsar=1[s1];" +
+			"[s1]subtitles=/tmp/render/captions.ass[s2];" +
+			"[1:v]format=rgba,scale=160:-1,colorchannelmixer=aa=0.80[s3];" +
+			"[s2][s3]overlay=32:32:format=auto[s4];" +
+			"[2:a]volume=0.30,atrim=0:30.000,asetpts=PTS-STARTPTS[s5];" +
+			"[0:a]volume=1.00[s6];" +
+			"[s6][s5]amix=inputs=2:duration=first:normalize=0[s7]",
+		"-map", "[s4]", "-map", "[s7]",
+		"-c:v", "libx264",
+		"out.mp4",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Args mismatch\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestFilterGraph_TrimAndPassthroughAudio(t *testing.T) {
+	g := NewFilterGraph()
+	src := g.AddInput(Input{Path: "in.mp4"})
+	v := g.Trim(VideoStream(src), 1, 4.25)
+	a := g.ATrim(AudioStream(src), 1, 4.25)
+
+	got := g.Args("out.mp4", []Stream{v, a, "0:s?"})
+	want := []string{
+		"-y", "-i", "in.mp4",
+		"-filter_complex",
+		"[0:v]trim=start=1.000:end=4.250,setpts=PTS-STARTPTS[s1];[0:a]atrim=start=1.000:end=4.250,asetpts=PTS-STARTPTS[s2]",
+		"-map", "[s1]", "-map", "[s2]", "-map", "0:s?",
+		"out.mp4",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Args mismatch\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestFilterGraph_AutoDuck(t *testing.T) {
+	g := NewFilterGraph()
+	g.AddInput(Input{Path: "in.mp4"})
+	g.AddInput(Input{Path: "music.mp3"})
+	g.MixMusic(AudioStream(0), AudioStream(1), MixOptions{MusicVolume: 0.5, OriginalVolume: 1, AutoDuck: true})
+
+	want := "[1:a]volume=0.50[s1];[0:a]volume=1.00,asplit=2[s2][s3];" +
+		"[s1][s3]sidechaincompress=threshold=0.02:ratio=8:attack=20:release=400[s4];" +
+		"[s2][s4]amix=inputs=2:duration=first:normalize=0[s5]"
+	if got := g.String(); got != want {
+		t.Errorf("filter_complex mismatch\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestFilterGraph_Silence(t *testing.T) {
+	g := NewFilterGraph()
+	music := g.AddInput(Input{Path: "music.mp3", Loop: true})
+	a := g.MixMusic(g.Silence(12.5), AudioStream(music), MixOptions{MusicVolume: 0.3, OriginalVolume: 1, Duration: 12.5})
+	want := "anullsrc=r=48000:cl=stereo,atrim=0:12.500[s1];" +
+		"[0:a]volume=0.30,atrim=0:12.500,asetpts=PTS-STARTPTS[s2];" +
+		"[s1]volume=1.00[s3];" +
+		"[s3][s2]amix=inputs=2:duration=first:normalize=0[s4]"
+	if got := g.String(); got != want || a != "s4" {
+		t.Errorf("graph = %s (%s)\nwant %s", got, a, want)
+	}
+}
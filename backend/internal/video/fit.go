@@ -0,0 +1,92 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Fit modes: how a source frame is placed in an output of a different aspect ratio.
+const (
+	FitCrop  = "crop"  // scale to cover the output and centre-crop the overflow
+	FitBlur  = "blur"  // whole frame scaled to fit, over a blurred, zoomed copy of itself
+	FitColor = "color" // whole frame scaled to fit, over a solid colour
+)
+
+// FitModes lists the accepted fit modes.
+var FitModes = map[string]bool{
+	FitCrop:  true,
+	FitBlur:  true,
+	FitColor: true,
+}
+
+// DefaultFitColor is the FitColor background when none is set.
+const DefaultFitColor = "#000000"
+
+var reFitColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// ValidFitColor reports whether c is a #RRGGBB colour.
+func ValidFitColor(c string) bool { return reFitColor.MatchString(c) }
+
+// fitBlurDownscale shrinks the background before blurring: the blur is far cheaper on a small frame
+// and looks the same once scaled back up.
+const fitBlurDownscale = 4
+
+// FitOptions selects the fit mode and, for FitColor, the background (#RRGGBB).
+type FitOptions struct {
+	Mode  string
+	Color string
+}
+
+// Fit places v in a width x height frame per opts. Unknown or empty modes crop.
+func (g *FilterGraph) Fit(v Stream, width, height int, opts FitOptions) Stream {
+	switch opts.Mode {
+	case FitBlur:
+		split := g.ChainN("split=2", 2, v)
+		bg := g.Chain(fitBlurBackground(width, height), split[0])
+		fg := g.Chain(fitScale(width, height), split[1])
+		return g.Chain("overlay=(W-w)/2:(H-h)/2,setsar=1", bg, fg)
+	case FitColor:
+		return g.Chain(fitScale(width, height)+","+fitPad(width, height, opts.Color), v)
+	default:
+		return g.ScaleCrop(v, width, height)
+	}
+}
+
+// fitScale scales a frame to fit inside width x height, keeping its aspect ratio.
+func fitScale(width, height int) string {
+	return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease:force_divisible_by=2,setsar=1", width, height)
+}
+
+// fitPad centres a fitted frame on a width x height canvas of color.
+func fitPad(width, height int, color string) string {
+	if !ValidFitColor(color) {
+		color = DefaultFitColor
+	}
+	return fmt.Sprintf("pad=%d:%d:(ow-iw)/2:(oh-ih)/2:color=0x%s", width, height, strings.ToUpper(color[1:]))
+}
+
+// fitBlurBackground covers width x height with the frame, blurred at reduced size.
+func fitBlurBackground(width, height int) string {
+	sw, sh := max(2, width/fitBlurDownscale&^1), max(2, height/fitBlurDownscale&^1)
+	return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,boxblur=10:2,scale=%d:%d,setsar=1", sw, sh, sw, sh, width, height)
+}
+
+// ResizeFit resizes a video to aspectRatio (see OutputSize) using opts.
+func ResizeFit(ctx context.Context, inputPath, outputPath, aspectRatio string, opts FitOptions) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+	w, h := OutputSize(aspectRatio)
+	g := NewFilterGraph()
+	src := g.AddInput(Input{Path: inputPath})
+	v := g.Fit(VideoStream(src), w, h, opts)
+	out, err := RunFFmpeg(ctx, g.Args(outputPath, []Stream{v, "0:a?"}, "-c:a", "copy")...)
+	if err != nil {
+		return fmt.Errorf("ffmpeg resize: %w (output: %s)", err, string(out))
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package video
+
+import "testing"
+
+func TestFilterGraph_Fit(t *testing.T) {
+	tests := []struct {
+		opts FitOptions
+		want string
+	}{
+		{FitOptions{Mode: FitCrop}, "[0:v]scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920,setsar=1[s1]"},
+		{FitOptions{Mode: FitColor, Color: "#1a2b3c"},
+			"[0:v]scale=1080:1920:force_original_aspect_ratio=decrease:force_divisible_by=2,setsar=1,pad=1080:1920:(ow-iw)/2:(oh-ih)/2:color=0x1A2B3C[s1]"},
+		{FitOptions{Mode: FitColor, Color: "red"},
+			"[0:v]scale=1080:1920:force_original_aspect_ratio=decrease:force_divisible_by=2,setsar=1,pad=1080:1920:(ow-iw)/2:(oh-ih)/2:color=0x000000[s1]"},
+		{FitOptions{Mode: FitBlur},
+			"[0:v]split=2[s1][s2];" +
+				"[s1]scale=270:480:force_original_aspect_ratio=increase,crop=270:480,boxblur=10:2,scale=1080:1920,setsar=1[s3];" +
+				"[s2]scale=1080:1920:force_original_aspect_ratio=decrease:force_divisible_by=2,setsar=1[s4];" +
+				"[s3][s4]overlay=(W-w)/2:(H-h)/2,setsar=1[s5]"},
+	}
+	for _, tt := range tests {
+		g := NewFilterGraph()
+		src := g.AddInput(Input{Path: "in.mp4"})
+		g.Fit(VideoStream(src), 1080, 1920, tt.opts)
+		if got := g.String(); got != tt.want {
+			t.Errorf("Fit(%+v) =\n%s\nwant\n%s", tt.opts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hlsSegmentSec is the target HLS segment length. Keyframes are forced on segment boundaries so every
+// rendition switches cleanly.
+const hlsSegmentSec = 4
+
+// Rendition is one rung of an HLS ladder. Height is the short side of the output, so portrait and
+// landscape sources get the same quality per rung.
+type Rendition struct {
+	Name         string // variant directory, e.g. "720p"
+	Height       int
+	VideoBitrate int // kbit/s
+	AudioBitrate int // kbit/s
+}
+
+// HLSLadder is the default adaptive playback ladder, lowest first.
+var HLSLadder = []Rendition{
+	{Name: "360p", Height: 360, VideoBitrate: 800, AudioBitrate: 96},
+	{Name: "720p", Height: 720, VideoBitrate: 2800, AudioBitrate: 128},
+	{Name: "1080p", Height: 1080, VideoBitrate: 5000, AudioBitrate: 128},
+}
+
+// LadderFor returns the rungs of ladder that do not upscale a width x height source. The lowest rung
+// is always kept so even tiny sources get one rendition.
+func LadderFor(ladder []Rendition, width, height int) []Rendition {
+	short := min(width, height)
+	var out []Rendition
+	for i, r := range ladder {
+		if i == 0 || r.Height <= short {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// RenditionSize scales a width x height source so its short side is shortSide, keeping the aspect
+// ratio with even dimensions (required by yuv420p H.264).
+func RenditionSize(width, height, shortSide int) (int, int) {
+	if width <= 0 || height <= 0 {
+		return 0, 0
+	}
+	even := func(v float64) int { return max(2, int(v/2+0.5)*2) }
+	if width >= height {
+		return even(float64(width) * float64(shortSide) / float64(height)), even(float64(shortSide))
+	}
+	return even(float64(shortSide)), even(float64(height) * float64(shortSide) / float64(width))
+}
+
+// MasterPlaylist returns the HLS master playlist for renditions produced by Transcode, with the
+// variant playlists at <name>/index.m3u8. sizes holds each rendition's output size.
+func MasterPlaylist(renditions []Rendition, sizes [][2]int) string {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	for i, r := range renditions {
+		avg := (r.VideoBitrate + r.AudioBitrate) * 1000
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,AVERAGE-BANDWIDTH=%d,RESOLUTION=%dx%d\n", avg*110/100, avg, sizes[i][0], sizes[i][1])
+		fmt.Fprintf(&b, "%s/index.m3u8\n", r.Name)
+	}
+	return b.String()
+}
+
+// Transcode writes the playback renditions of inputPath to dir in a single FFmpeg run, so the source
+// is decoded once and split between the encoders: every rendition of ladder, scaled to the matching
+// sizes entry, as a VOD media playlist with MPEG-TS segments at hls/<name>/index.m3u8, and the editor
+// proxy, scaled to proxy, at proxy.mp4.
+func Transcode(ctx context.Context, inputPath, dir string, ladder []Rendition, sizes [][2]int, proxy [2]int) error {
+	for _, r := range ladder {
+		if err := os.MkdirAll(filepath.Join(dir, "hls", r.Name), 0755); err != nil {
+			return err
+		}
+	}
+	out, err := RunFFmpeg(ctx, transcodeArgs(inputPath, dir, ladder, sizes, proxy)...)
+	if err != nil {
+		return fmt.Errorf("ffmpeg transcode: %w (output: %s)", err, string(out))
+	}
+	return nil
+}
+
+// transcodeArgs builds the FFmpeg arguments of Transcode: the video is split once per output and each
+// branch scaled on its own, while the audio is mapped (when there is any) and encoded per output.
+func transcodeArgs(inputPath, dir string, ladder []Rendition, sizes [][2]int, proxy [2]int) []string {
+	n := len(ladder) + 1
+	var split, scale strings.Builder
+	fmt.Fprintf(&split, "[0:v:0]split=%d", n)
+	for i := range n {
+		w, h := proxy[0], proxy[1]
+		if i < len(ladder) {
+			w, h = sizes[i][0], sizes[i][1]
+		}
+		fmt.Fprintf(&split, "[s%d]", i)
+		fmt.Fprintf(&scale, ";[s%d]scale=%d:%d,setsar=1[v%d]", i, w, h, i)
+	}
+	args := []string{"-y", "-i", inputPath, "-filter_complex", split.String() + scale.String()}
+	for i, r := range ladder {
+		hlsDir := filepath.Join(dir, "hls", r.Name)
+		args = append(args,
+			"-map", fmt.Sprintf("[v%d]", i), "-map", "0:a:0?",
+			"-c:v", "libx264", "-preset", "veryfast", "-profile:v", "high", "-pix_fmt", "yuv420p",
+			"-b:v", fmt.Sprintf("%dk", r.VideoBitrate),
+			"-maxrate", fmt.Sprintf("%dk", r.VideoBitrate*107/100),
+			"-bufsize", fmt.Sprintf("%dk", r.VideoBitrate*3/2),
+			"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", hlsSegmentSec),
+			"-sc_threshold", "0",
+			"-c:a", "aac", "-b:a", fmt.Sprintf("%dk", r.AudioBitrate), "-ac", "2",
+			"-f", "hls",
+			"-hls_time", fmt.Sprint(hlsSegmentSec),
+			"-hls_playlist_type", "vod",
+			"-hls_segment_filename", filepath.Join(hlsDir, "seg_%05d.ts"),
+			filepath.Join(hlsDir, "index.m3u8"),
+		)
+	}
+	// The proxy keeps a keyframe every second so scrubbing stays responsive, and faststart lets
+	// playback begin before it is loaded.
+	return append(args,
+		"-map", fmt.Sprintf("[v%d]", len(ladder)), "-map", "0:a:0?",
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "28", "-pix_fmt", "yuv420p",
+		"-force_key_frames", "expr:gte(t,n_forced*1)",
+		"-c:a", "aac", "-b:a", "96k", "-ac", "2",
+		"-movflags", "+faststart",
+		filepath.Join(dir, "proxy.mp4"),
+	)
+}
@@ -0,0 +1,88 @@
+package video
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLadderFor(t *testing.T) {
+	names := func(rs []Rendition) string {
+		var out []string
+		for _, r := range rs {
+			out = append(out, r.Name)
+		}
+		return strings.Join(out, ",")
+	}
+	tests := []struct {
+		w, h int
+		want string
+	}{
+		{3840, 2160, "360p,720p,1080p"},
+		{1280, 720, "360p,720p"},
+		{1080, 1920, "360p,720p,1080p"},
+		{320, 240, "360p"},
+	}
+	for _, tt := range tests {
+		if got := names(LadderFor(HLSLadder, tt.w, tt.h)); got != tt.want {
+			t.Errorf("LadderFor(%dx%d) = %s, want %s", tt.w, tt.h, got, tt.want)
+		}
+	}
+}
+
+func TestRenditionSize(t *testing.T) {
+	tests := []struct {
+		w, h, short, wantW, wantH int
+	}{
+		{3840, 2160, 720, 1280, 720},
+		{1080, 1920, 360, 360, 640},
+		{1440, 1080, 360, 480, 360},
+		{853, 480, 360, 640, 360},
+	}
+	for _, tt := range tests {
+		if w, h := RenditionSize(tt.w, tt.h, tt.short); w != tt.wantW || h != tt.wantH {
+			t.Errorf("RenditionSize(%d, %d, %d) = %dx%d, want %dx%d", tt.w, tt.h, tt.short, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestMasterPlaylist(t *testing.T) {
+	got := MasterPlaylist(HLSLadder[:2], [][2]int{{640, 360}, {1280, 720}})
+	want := "#EXTM3U\n#EXT-X-VERSION:3\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=985600,AVERAGE-BANDWIDTH=896000,RESOLUTION=640x360\n360p/index.m3u8\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=3220800,AVERAGE-BANDWIDTH=2928000,RESOLUTION=1280x720\n720p/index.m3u8\n"
+	if got != want {
+		t.Errorf("MasterPlaylist =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTranscodeArgs(t *testing.T) {
+	args := transcodeArgs("in.mp4", "out", HLSLadder[:2], [][2]int{{640, 360}, {1280, 720}}, [2]int{854, 480})
+	if n := strings.Count(strings.Join(args, " "), "-i "); n != 1 {
+		t.Errorf("%d inputs, want the source decoded once", n)
+	}
+	var graph string
+	var maps, outputs []string
+	for i, a := range args {
+		switch a {
+		case "-filter_complex":
+			graph = args[i+1]
+		case "-map":
+			if strings.HasPrefix(args[i+1], "[") {
+				maps = append(maps, args[i+1])
+			}
+		}
+		if strings.HasSuffix(a, ".m3u8") || strings.HasSuffix(a, ".mp4") && a != "in.mp4" {
+			outputs = append(outputs, a)
+		}
+	}
+	wantGraph := "[0:v:0]split=3[s0][s1][s2];[s0]scale=640:360,setsar=1[v0];[s1]scale=1280:720,setsar=1[v1];[s2]scale=854:480,setsar=1[v2]"
+	if graph != wantGraph {
+		t.Errorf("filter graph = %s, want %s", graph, wantGraph)
+	}
+	if got := strings.Join(maps, ","); got != "[v0],[v1],[v2]" {
+		t.Errorf("video maps = %s, want one branch per output", got)
+	}
+	if got := strings.Join(outputs, ","); got != "out/hls/360p/index.m3u8,out/hls/720p/index.m3u8,out/proxy.mp4" {
+		t.Errorf("outputs = %s", got)
+	}
+}
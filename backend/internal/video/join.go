@@ -0,0 +1,108 @@
+package video
+
+import (
+	"fmt"
+	"math"
+)
+
+// TransitionSec is how long a transition between two joined segments lasts, at most.
+const TransitionSec = 0.5
+
+// defaultJoinFPS is the frame rate segments are conformed to when the caller does not know one.
+const defaultJoinFPS = 30.0
+
+// Transitions lists the accepted transitions between joined segments (FFmpeg xfade transitions).
+var Transitions = map[string]bool{
+	"fade":        true,
+	"fadeblack":   true,
+	"fadewhite":   true,
+	"dissolve":    true,
+	"wipeleft":    true,
+	"wiperight":   true,
+	"wipeup":      true,
+	"wipedown":    true,
+	"slideleft":   true,
+	"slideright":  true,
+	"slideup":     true,
+	"slidedown":   true,
+	"smoothleft":  true,
+	"smoothright": true,
+	"circleopen":  true,
+	"circleclose": true,
+	"radial":      true,
+	"zoomin":      true,
+	"pixelize":    true,
+}
+
+// JoinSegment is one segment to join: its video and audio streams and length in seconds.
+type JoinSegment struct {
+	V, A     Stream
+	Duration float64
+}
+
+// JoinOverlaps returns how many seconds each joint between segments of the given durations overlaps:
+// TransitionSec capped at half of either neighbour, or 0 everywhere when transition is empty or unknown
+// (hard cuts).
+func JoinOverlaps(durations []float64, transition string) []float64 {
+	if len(durations) < 2 {
+		return nil
+	}
+	out := make([]float64, len(durations)-1)
+	if !Transitions[transition] {
+		return out
+	}
+	for i := range out {
+		out[i] = math.Min(TransitionSec, math.Min(durations[i], durations[i+1])/2)
+	}
+	return out
+}
+
+// JoinOffsets returns where each segment starts in the joined output and the output's length.
+func JoinOffsets(durations []float64, transition string) (offsets []float64, total float64) {
+	overlaps := JoinOverlaps(durations, transition)
+	offsets = make([]float64, len(durations))
+	for i, d := range durations {
+		if i > 0 {
+			total -= overlaps[i-1]
+		}
+		offsets[i] = total
+		total += d
+	}
+	return offsets, total
+}
+
+// Join conforms segments to one frame rate, pixel format and audio layout, which segments from
+// different sources may not share, and joins them in order: with xfade and acrossfade when transition
+// is one of Transitions (see JoinOverlaps), otherwise with concat. Segments must already share a frame
+// size. fps 0 uses 30.
+func (g *FilterGraph) Join(segs []JoinSegment, transition string, fps float64) (Stream, Stream) {
+	if fps <= 0 {
+		fps = defaultJoinFPS
+	}
+	vs, as := make([]Stream, len(segs)), make([]Stream, len(segs))
+	durations := make([]float64, len(segs))
+	for i, s := range segs {
+		vs[i] = g.Chain(fmt.Sprintf("fps=%g,format=yuv420p,setsar=1,settb=AVTB,setpts=PTS-STARTPTS", fps), s.V)
+		as[i] = g.Chain("aformat=sample_fmts=fltp:sample_rates=48000:channel_layouts=stereo,asetpts=PTS-STARTPTS", s.A)
+		durations[i] = s.Duration
+	}
+	if len(segs) == 1 {
+		return vs[0], as[0]
+	}
+	if !Transitions[transition] {
+		in := make([]Stream, 0, 2*len(segs))
+		for i := range segs {
+			in = append(in, vs[i], as[i])
+		}
+		out := g.ChainN(fmt.Sprintf("concat=n=%d:v=1:a=1", len(segs)), 2, in...)
+		return out[0], out[1]
+	}
+	overlaps := JoinOverlaps(durations, transition)
+	offsets, _ := JoinOffsets(durations, transition)
+	v, a := vs[0], as[0]
+	for i := 1; i < len(segs); i++ {
+		v = g.Chain(fmt.Sprintf("xfade=transition=%s:duration=%.3f:offset=%.3f", transition, overlaps[i-1], offsets[i]), v, vs[i])
+		a = g.Chain(fmt.Sprintf("acrossfade=d=%.3f", overlaps[i-1]), a, as[i])
+	}
+	return v, a
+}
@@ -0,0 +1,58 @@
+package video
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJoinOffsets(t *testing.T) {
+	offsets, total := JoinOffsets([]float64{10, 0.6, 5}, "fade")
+	// The joint next to the 0.6s segment overlaps only 0.3s.
+	want := []float64{0, 9.7, 10}
+	for i := range want {
+		if diff := offsets[i] - want[i]; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("offsets[%d] = %g, want %g", i, offsets[i], want[i])
+		}
+	}
+	if diff := total - 15; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("total = %g, want 15", total)
+	}
+
+	offsets, total = JoinOffsets([]float64{10, 5}, "")
+	if offsets[1] != 10 || total != 15 {
+		t.Errorf("hard cut offsets = %v, total %g; want [0 10], 15", offsets, total)
+	}
+	if _, total = JoinOffsets([]float64{10, 5}, "spin"); total != 15 {
+		t.Errorf("unknown transition total = %g, want 15 (hard cut)", total)
+	}
+}
+
+func TestFilterGraph_Join(t *testing.T) {
+	g := NewFilterGraph()
+	a, b := g.AddInput(Input{Path: "a.mp4"}), g.AddInput(Input{Path: "b.mp4"})
+	g.Join([]JoinSegment{
+		{V: VideoStream(a), A: AudioStream(a), Duration: 4},
+		{V: VideoStream(b), A: AudioStream(b), Duration: 3},
+	}, "dissolve", 25)
+	got := g.String()
+	for _, want := range []string{
+		"[0:v]fps=25,format=yuv420p,setsar=1,settb=AVTB,setpts=PTS-STARTPTS[s1]",
+		"[1:a]aformat=sample_fmts=fltp:sample_rates=48000:channel_layouts=stereo,asetpts=PTS-STARTPTS[s4]",
+		"[s1][s3]xfade=transition=dissolve:duration=0.500:offset=3.500[s5]",
+		"[s2][s4]acrossfade=d=0.500[s6]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Join = %s\nmissing %s", got, want)
+		}
+	}
+
+	g = NewFilterGraph()
+	a, b = g.AddInput(Input{Path: "a.mp4"}), g.AddInput(Input{Path: "b.mp4"})
+	v, au := g.Join([]JoinSegment{
+		{V: VideoStream(a), A: AudioStream(a), Duration: 4},
+		{V: VideoStream(b), A: AudioStream(b), Duration: 3},
+	}, "", 0)
+	if want := "[s1][s2][s3][s4]concat=n=2:v=1:a=1[s5][s6]"; !strings.Contains(g.String(), want) || v != "s5" || au != "s6" {
+		t.Errorf("Join(cut) = %s (%s, %s)\nmissing %s", g.String(), v, au, want)
+	}
+}
@@ -0,0 +1,113 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var (
+	reSilenceStart = regexp.MustCompile(`silence_start: (-?\d+(?:\.\d+)?)`)
+	reSilenceEnd   = regexp.MustCompile(`silence_end: (-?\d+(?:\.\d+)?)`)
+)
+
+// DetectSilences runs FFmpeg's silencedetect over [start, start+duration) of inputPath and returns
+// the spans quieter than noiseDB for at least minDuration seconds, relative to start.
+func DetectSilences(ctx context.Context, inputPath string, start, duration, noiseDB, minDuration float64) ([]TimeRange, error) {
+	args := []string{
+		"-hide_banner", "-nostats",
+		"-ss", fmt.Sprintf("%.3f", start),
+		"-t", fmt.Sprintf("%.3f", duration),
+		"-i", inputPath,
+		"-vn",
+		"-af", fmt.Sprintf("silencedetect=noise=%gdB:d=%g", noiseDB, minDuration),
+		"-f", "null", "-",
+	}
+	out, err := RunFFmpeg(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg silencedetect: %w (output: %s)", err, string(out))
+	}
+	return parseSilences(string(out), duration), nil
+}
+
+// parseSilences reads silencedetect log lines. A silence still open at the end of the input runs to
+// duration.
+func parseSilences(log string, duration float64) []TimeRange {
+	starts := reSilenceStart.FindAllStringSubmatch(log, -1)
+	ends := reSilenceEnd.FindAllStringSubmatch(log, -1)
+	var out []TimeRange
+	for i, m := range starts {
+		s, _ := strconv.ParseFloat(m[1], 64)
+		e := duration
+		if i < len(ends) {
+			e, _ = strconv.ParseFloat(ends[i][1], 64)
+		}
+		s, e = max(s, 0), min(e, duration)
+		if e > s {
+			out = append(out, TimeRange{Start: s, End: e})
+		}
+	}
+	return out
+}
+
+// KeepRanges returns the parts of [0, duration] not covered by removed, in order. Removed ranges may
+// overlap and be unsorted.
+func KeepRanges(duration float64, removed []TimeRange) []TimeRange {
+	sorted := append([]TimeRange(nil), removed...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	var keep []TimeRange
+	pos := 0.0
+	for _, r := range sorted {
+		if r.Start > pos {
+			keep = append(keep, TimeRange{Start: pos, End: min(r.Start, duration)})
+		}
+		pos = max(pos, r.End)
+		if pos >= duration {
+			break
+		}
+	}
+	if pos < duration {
+		keep = append(keep, TimeRange{Start: pos, End: duration})
+	}
+	return keep
+}
+
+// KeptDuration is the total length of keep.
+func KeptDuration(keep []TimeRange) float64 {
+	var d float64
+	for _, k := range keep {
+		d += k.End - k.Start
+	}
+	return d
+}
+
+// RemapTime maps t on the input timeline onto the output of a jump cut keeping keep. Times inside a
+// removed range map to the cut point.
+func RemapTime(keep []TimeRange, t float64) float64 {
+	var out float64
+	for _, k := range keep {
+		if t <= k.Start {
+			return out
+		}
+		if t < k.End {
+			return out + t - k.Start
+		}
+		out += k.End - k.Start
+	}
+	return out
+}
+
+// JumpCut keeps only the keep ranges of v and a and joins them back to back.
+func (g *FilterGraph) JumpCut(v, a Stream, keep []TimeRange) (Stream, Stream) {
+	n := len(keep)
+	vs := g.ChainN(fmt.Sprintf("split=%d", n), n, v)
+	as := g.ChainN(fmt.Sprintf("asplit=%d", n), n, a)
+	parts := make([]Stream, 0, 2*n)
+	for i, k := range keep {
+		parts = append(parts, g.Trim(vs[i], k.Start, k.End), g.ATrim(as[i], k.Start, k.End))
+	}
+	out := g.ChainN(fmt.Sprintf("concat=n=%d:v=1:a=1", n), 2, parts...)
+	return out[0], out[1]
+}
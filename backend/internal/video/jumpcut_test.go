@@ -0,0 +1,53 @@
+package video
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSilences(t *testing.T) {
+	log := "[silencedetect @ 0x1] silence_start: -0.012\n" +
+		"[silencedetect @ 0x1] silence_end: 0.84 | silence_duration: 0.852\n" +
+		"[silencedetect @ 0x1] silence_start: 4.5\n" +
+		"[silencedetect @ 0x1] silence_end: 5.25 | silence_duration: 0.75\n" +
+		"[silencedetect @ 0x1] silence_start: 9.1\n"
+	got := parseSilences(log, 10)
+	want := []TimeRange{{0, 0.84}, {4.5, 5.25}, {9.1, 10}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSilences = %v, want %v", got, want)
+	}
+}
+
+func TestKeepRangesAndRemapTime(t *testing.T) {
+	keep := KeepRanges(10, []TimeRange{{6, 7}, {0, 1}, {2, 3}, {2.5, 4}})
+	want := []TimeRange{{1, 2}, {4, 6}, {7, 10}}
+	if !reflect.DeepEqual(keep, want) {
+		t.Fatalf("KeepRanges = %v, want %v", keep, want)
+	}
+	if d := KeptDuration(keep); d != 6 {
+		t.Errorf("KeptDuration = %v, want 6", d)
+	}
+	for _, tt := range []struct{ in, want float64 }{
+		{0.5, 0}, {1.5, 0.5}, {3, 1}, {5, 2}, {6.5, 3}, {8, 4}, {10, 6},
+	} {
+		if got := RemapTime(keep, tt.in); got != tt.want {
+			t.Errorf("RemapTime(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFilterGraph_JumpCut(t *testing.T) {
+	g := NewFilterGraph()
+	src := g.AddInput(Input{Path: "in.mp4"})
+	v, a := g.JumpCut(VideoStream(src), AudioStream(src), []TimeRange{{0, 2}, {3.5, 5}})
+	if v != "s9" || a != "s10" {
+		t.Errorf("outputs = %s, %s", v, a)
+	}
+	want := "[0:v]split=2[s1][s2];[0:a]asplit=2[s3][s4];" +
+		"[s1]trim=start=0.000:end=2.000,setpts=PTS-STARTPTS[s5];[s3]atrim=start=0.000:end=2.000,asetpts=PTS-STARTPTS[s6];" +
+		"[s2]trim=start=3.500:end=5.000,setpts=PTS-STARTPTS[s7];[s4]atrim=start=3.500:end=5.000,asetpts=PTS-STARTPTS[s8];" +
+		"[s5][s6][s7][s8]concat=n=2:v=1:a=1[s9][s10]"
+	if got := g.String(); got != want {
+		t.Errorf("graph =\n%s\nwant\n%s", got, want)
+	}
+}
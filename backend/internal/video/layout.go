@@ -0,0 +1,109 @@
+package video
+
+import (
+	"fmt"
+	"math"
+)
+
+// Layout presets: how two regions of the source are composed into one output frame.
+const (
+	LayoutSplit      = "split"        // regions stacked top and bottom, each half the output height
+	LayoutSideBySide = "side_by_side" // regions next to each other, each half the output width
+	LayoutPiP        = "pip"          // first region fills the frame, second is inset in a corner
+)
+
+// LayoutPresets lists the accepted layout presets.
+var LayoutPresets = map[string]bool{
+	LayoutSplit:      true,
+	LayoutSideBySide: true,
+	LayoutPiP:        true,
+}
+
+// LayoutRegionCount is how many source regions every preset takes.
+const LayoutRegionCount = 2
+
+// Picture-in-picture inset width, as a fraction of the output width.
+const (
+	MinPiPScale     = 0.15
+	MaxPiPScale     = 0.6
+	DefaultPiPScale = 0.35
+)
+
+// DefaultPiPPosition is the corner of the inset when none is set (see OverlayPositions).
+const DefaultPiPPosition = "bottom-right"
+
+// pipMarginDivisor sets the inset's distance from the frame edges to 1/pipMarginDivisor of the output
+// width.
+const pipMarginDivisor = 30
+
+// LayoutRegion is a rectangle of the source frame in fractions of its size: X, Y the top-left corner,
+// W, H the width and height.
+type LayoutRegion struct {
+	X, Y, W, H float64
+}
+
+// LayoutOptions selects the preset, its source regions (in panel order: top/bottom, left/right or
+// background/inset) and, for LayoutPiP, the inset's corner and width.
+type LayoutOptions struct {
+	Preset      string
+	Regions     []LayoutRegion
+	PiPPosition string
+	PiPScale    float64
+}
+
+// DefaultLayoutRegions returns the regions preset uses when none are given: the left and right halves
+// of the source for split and side by side, the whole frame and its bottom-right quarter for
+// picture-in-picture.
+func DefaultLayoutRegions(preset string) []LayoutRegion {
+	if preset == LayoutPiP {
+		return []LayoutRegion{{X: 0, Y: 0, W: 1, H: 1}, {X: 0.5, Y: 0.5, W: 0.5, H: 0.5}}
+	}
+	return []LayoutRegion{{X: 0, Y: 0, W: 0.5, H: 1}, {X: 0.5, Y: 0, W: 0.5, H: 1}}
+}
+
+// Layout composes two regions of v into a width x height frame per opts. Each region is cropped from
+// the source, then scaled to cover its panel and centre-cropped. Unknown presets fall back to ScaleCrop;
+// missing or malformed regions fall back to DefaultLayoutRegions.
+func (g *FilterGraph) Layout(v Stream, width, height int, opts LayoutOptions) Stream {
+	if !LayoutPresets[opts.Preset] {
+		return g.ScaleCrop(v, width, height)
+	}
+	regions := opts.Regions
+	if len(regions) != LayoutRegionCount {
+		regions = DefaultLayoutRegions(opts.Preset)
+	}
+	split := g.ChainN("split=2", 2, v)
+	switch opts.Preset {
+	case LayoutSplit:
+		top := max(2, height/2&^1)
+		a := g.Chain(regionCrop(regions[0])+","+coverFilter(width, top), split[0])
+		b := g.Chain(regionCrop(regions[1])+","+coverFilter(width, height-top), split[1])
+		return g.Chain("vstack=inputs=2,setsar=1", a, b)
+	case LayoutSideBySide:
+		left := max(2, width/2&^1)
+		a := g.Chain(regionCrop(regions[0])+","+coverFilter(left, height), split[0])
+		b := g.Chain(regionCrop(regions[1])+","+coverFilter(width-left, height), split[1])
+		return g.Chain("hstack=inputs=2,setsar=1", a, b)
+	default:
+		scale := opts.PiPScale
+		if scale < MinPiPScale || scale > MaxPiPScale {
+			scale = DefaultPiPScale
+		}
+		pos := opts.PiPPosition
+		if !OverlayPositions[pos] {
+			pos = DefaultPiPPosition
+		}
+		bg := g.Chain(regionCrop(regions[0])+","+coverFilter(width, height), split[0])
+		inset := g.Chain(fmt.Sprintf("%s,scale=%d:-2,setsar=1", regionCrop(regions[1]), evenRound(float64(width)*scale)), split[1])
+		return g.Chain(overlayFilter(OverlayOptions{Position: pos, Margin: width / pipMarginDivisor})+",setsar=1", bg, inset)
+	}
+}
+
+// regionCrop crops r out of the frame, clamped to it.
+func regionCrop(r LayoutRegion) string {
+	x, y := clamp01(r.X), clamp01(r.Y)
+	w, h := math.Max(math.Min(r.W, 1-x), 0.01), math.Max(math.Min(r.H, 1-y), 0.01)
+	return fmt.Sprintf("crop=iw*%.4f:ih*%.4f:iw*%.4f:ih*%.4f", w, h, x, y)
+}
+
+func clamp01(v float64) float64 { return math.Min(math.Max(v, 0), 1) }
@@ -0,0 +1,73 @@
+package video
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterGraph_Layout(t *testing.T) {
+	tests := []struct {
+		name string
+		opts LayoutOptions
+		want []string
+	}{
+		{
+			name: "split",
+			opts: LayoutOptions{Preset: LayoutSplit, Regions: []LayoutRegion{{X: 0.1, Y: 0, W: 0.3, H: 1}, {X: 0.6, Y: 0.2, W: 0.3, H: 0.5}}},
+			want: []string{
+				"[0:v]split=2[s1][s2]",
+				"[s1]crop=iw*0.3000:ih*1.0000:iw*0.1000:ih*0.0000,scale=1080:960:force_original_aspect_ratio=increase,crop=1080:960,setsar=1[s3]",
+				"[s2]crop=iw*0.3000:ih*0.5000:iw*0.6000:ih*0.2000,scale=1080:960:force_original_aspect_ratio=increase,crop=1080:960,setsar=1[s4]",
+				"[s3][s4]vstack=inputs=2,setsar=1[s5]",
+			},
+		},
+		{
+			name: "side by side, default regions",
+			opts: LayoutOptions{Preset: LayoutSideBySide},
+			want: []string{
+				"[s1]crop=iw*0.5000:ih*1.0000:iw*0.0000:ih*0.0000,scale=540:1920:",
+				"[s2]crop=iw*0.5000:ih*1.0000:iw*0.5000:ih*0.0000,scale=540:1920:",
+				"[s3][s4]hstack=inputs=2,setsar=1[s5]",
+			},
+		},
+		{
+			name: "pip",
+			opts: LayoutOptions{Preset: LayoutPiP, Regions: []LayoutRegion{{X: 0, Y: 0, W: 1, H: 1}, {X: 0.75, Y: 0, W: 0.25, H: 0.25}}, PiPPosition: "top-left", PiPScale: 0.3},
+			want: []string{
+				"[s1]crop=iw*1.0000:ih*1.0000:iw*0.0000:ih*0.0000,scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920,setsar=1[s3]",
+				"[s2]crop=iw*0.2500:ih*0.2500:iw*0.7500:ih*0.0000,scale=324:-2,setsar=1[s4]",
+				"[s3][s4]overlay=36:36:format=auto,setsar=1[s5]",
+			},
+		},
+		{
+			name: "pip defaults",
+			opts: LayoutOptions{Preset: LayoutPiP, PiPPosition: "middle", PiPScale: 2},
+			want: []string{
+				"scale=378:-2",
+				"overlay=main_w-overlay_w-36:main_h-overlay_h-36:format=auto",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewFilterGraph()
+			src := g.AddInput(Input{Path: "in.mp4"})
+			g.Layout(VideoStream(src), 1080, 1920, tt.opts)
+			got := g.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Layout = %s\nmissing %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterGraph_LayoutUnknownPreset(t *testing.T) {
+	g := NewFilterGraph()
+	src := g.AddInput(Input{Path: "in.mp4"})
+	g.Layout(VideoStream(src), 1080, 1920, LayoutOptions{Preset: "grid"})
+	if got, want := g.String(), "[0:v]scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920,setsar=1[s1]"; got != want {
+		t.Errorf("Layout(unknown) = %s, want %s", got, want)
+	}
+}
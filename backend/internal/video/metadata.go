@@ -1,6 +1,7 @@
 package video
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -78,3 +79,12 @@ func GetMetadata(ctx context.Context, path string) (*Metadata, error) {
 	}
 	return meta, nil
 }
+
+// HasAudio reports whether path has at least one audio stream.
+func HasAudio(ctx context.Context, path string) (bool, error) {
+	out, err := RunFFprobe(ctx, "-v", "error", "-select_streams", "a", "-show_entries", "stream=index", "-of", "csv=p=0", path)
+	if err != nil {
+		return false, fmt.Errorf("ffprobe: %w", err)
+	}
+	return len(bytes.TrimSpace(out)) > 0, nil
+}
@@ -0,0 +1,106 @@
+package video
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Platform export presets.
+const (
+	PresetTikTok   = "tiktok"
+	PresetReels    = "reels"
+	PresetShorts   = "shorts"
+	PresetLinkedIn = "linkedin"
+)
+
+// SafeArea is how much of each edge of the frame a platform covers with its own interface (buttons,
+// description, progress bar), as fractions of the frame width (Left, Right) or height (Top, Bottom).
+type SafeArea struct {
+	Top, Bottom, Left, Right float64
+}
+
+// Preset is what one platform accepts uploads under: the output aspect ratio (empty keeps the clip's),
+// resolution tier, frame rate, longest duration in seconds, peak video and audio bitrates in kbit/s,
+// integrated loudness target (LUFS) and true-peak ceiling (dBTP), and the edges captions must keep
+// clear of.
+type Preset struct {
+	Name         string
+	Label        string
+	AspectRatio  string
+	Resolution   string
+	FPS          int
+	MaxDuration  float64
+	VideoBitrate int
+	AudioBitrate int
+	Loudness     float64
+	TruePeak     float64
+	SafeArea     SafeArea
+}
+
+// Presets lists the accepted export presets by name.
+var Presets = map[string]Preset{
+	PresetTikTok: {
+		Name: PresetTikTok, Label: "TikTok", AspectRatio: "9:16", Resolution: Resolution1080p, FPS: 30, MaxDuration: 600,
+		VideoBitrate: 8000, AudioBitrate: 192, Loudness: -14, TruePeak: -1,
+		SafeArea: SafeArea{Top: 0.1, Bottom: 0.25, Left: 0.05, Right: 0.15},
+	},
+	PresetReels: {
+		Name: PresetReels, Label: "Instagram Reels", AspectRatio: "9:16", Resolution: Resolution1080p, FPS: 30, MaxDuration: 180,
+		VideoBitrate: 8000, AudioBitrate: 128, Loudness: -14, TruePeak: -1,
+		SafeArea: SafeArea{Top: 0.14, Bottom: 0.35, Left: 0.06, Right: 0.06},
+	},
+	PresetShorts: {
+		Name: PresetShorts, Label: "YouTube Shorts", AspectRatio: "9:16", Resolution: Resolution1080p, FPS: 30, MaxDuration: 180,
+		VideoBitrate: 12000, AudioBitrate: 192, Loudness: -14, TruePeak: -1,
+		SafeArea: SafeArea{Top: 0.1, Bottom: 0.2, Left: 0.05, Right: 0.15},
+	},
+	PresetLinkedIn: {
+		Name: PresetLinkedIn, Label: "LinkedIn", Resolution: Resolution1080p, FPS: 30, MaxDuration: 600,
+		VideoBitrate: 5000, AudioBitrate: 192, Loudness: -16, TruePeak: -1.5,
+		SafeArea: SafeArea{Top: 0.05, Bottom: 0.05, Left: 0.05, Right: 0.05},
+	},
+}
+
+// PresetNames returns the names of Presets in alphabetical order.
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Fits reports whether an output of duration seconds is within p's MaxDuration, give or take a frame.
+func (p Preset) Fits(duration float64) bool {
+	return duration <= p.MaxDuration+1/float64(p.FPS)
+}
+
+// Encode returns the output options for p: H.264 High profile held under the peak bitrate at the
+// preset frame rate, and 48kHz AAC.
+func (p Preset) Encode() []string {
+	return []string{
+		"-c:v", "libx264", "-preset", "medium", "-crf", "20", "-profile:v", "high", "-pix_fmt", "yuv420p",
+		"-maxrate", fmt.Sprintf("%dk", p.VideoBitrate), "-bufsize", fmt.Sprintf("%dk", 2*p.VideoBitrate),
+		"-r", strconv.Itoa(p.FPS), "-g", strconv.Itoa(2 * p.FPS),
+		"-c:a", "aac", "-b:a", fmt.Sprintf("%dk", p.AudioBitrate), "-ar", "48000",
+		"-movflags", "+faststart",
+	}
+}
+
+// loudnormFilter is single-pass EBU R128 normalisation to p's loudness target. loudnorm upsamples
+// internally, so the result is brought back to 48kHz.
+func loudnormFilter(p Preset) string {
+	return fmt.Sprintf("loudnorm=I=%g:TP=%g:LRA=11,aresample=48000", p.Loudness, p.TruePeak)
+}
+
+// Loudness normalises a to p's loudness target. A graph stream gets a chain; an input stream, which may
+// be absent (as "0:a?" is), cannot be filtered in the graph, so a is returned as is with the filter as
+// -af output options instead.
+func (g *FilterGraph) Loudness(a Stream, p Preset) (Stream, []string) {
+	if a.isInput() {
+		return a, []string{"-af", loudnormFilter(p)}
+	}
+	return g.Chain(loudnormFilter(p), a), nil
+}
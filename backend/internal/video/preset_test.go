@@ -0,0 +1,51 @@
+package video
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	for name, p := range Presets {
+		if p.Name != name {
+			t.Errorf("Presets[%q].Name = %q", name, p.Name)
+		}
+		if _, ok := Resolutions[p.Resolution]; !ok {
+			t.Errorf("%s: unknown resolution %q", name, p.Resolution)
+		}
+		if p.FPS <= 0 || p.MaxDuration <= 0 || p.VideoBitrate <= 0 || p.AudioBitrate <= 0 || p.Loudness >= 0 {
+			t.Errorf("%s: incomplete preset %+v", name, p)
+		}
+		if sa := p.SafeArea; sa.Top+sa.Bottom >= 1 || sa.Left+sa.Right >= 1 {
+			t.Errorf("%s: safe area %+v leaves no room", name, sa)
+		}
+	}
+	if got := PresetNames(); !slices.Equal(got, []string{"linkedin", "reels", "shorts", "tiktok"}) {
+		t.Errorf("PresetNames() = %v", got)
+	}
+}
+
+func TestPreset_Encode(t *testing.T) {
+	got := strings.Join(Presets[PresetReels].Encode(), " ")
+	for _, want := range []string{"-maxrate 8000k -bufsize 16000k", "-r 30 -g 60", "-b:a 128k -ar 48000", "-profile:v high"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Encode() = %s\nmissing %s", got, want)
+		}
+	}
+}
+
+func TestFilterGraph_Loudness(t *testing.T) {
+	p := Presets[PresetLinkedIn]
+	g := NewFilterGraph()
+	in := g.AddInput(Input{Path: "a.mp4"})
+	a, af := g.Loudness(Stream("0:a?"), p)
+	if a != "0:a?" || strings.Join(af, " ") != "-af loudnorm=I=-16:TP=-1.5:LRA=11,aresample=48000" || g.String() != "" {
+		t.Errorf("Loudness(input) = %s, %v; graph %q", a, af, g.String())
+	}
+
+	a, af = g.Loudness(g.Chain("volume=1", AudioStream(in)), p)
+	if want := "[s1]loudnorm=I=-16:TP=-1.5:LRA=11,aresample=48000[s2]"; af != nil || a != "s2" || !strings.Contains(g.String(), want) {
+		t.Errorf("Loudness(label) = %s, %v; graph %s\nmissing %s", a, af, g.String(), want)
+	}
+}
@@ -0,0 +1,88 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ProgressFunc receives the completed fraction (0-1) of an FFmpeg run.
+type ProgressFunc func(fraction float64)
+
+type progressKey struct{}
+type durationKey struct{}
+
+// WithProgress returns a context whose FFmpeg runs report progress to fn. Progress is only reported
+// once the expected output duration is known (see WithExpectedDuration).
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// WithExpectedDuration sets the output duration in seconds that FFmpeg's out_time is divided by.
+func WithExpectedDuration(ctx context.Context, seconds float64) context.Context {
+	return context.WithValue(ctx, durationKey{}, seconds)
+}
+
+// progressFrom returns the progress callback and expected duration carried by ctx.
+func progressFrom(ctx context.Context) (ProgressFunc, float64, bool) {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	dur, _ := ctx.Value(durationKey{}).(float64)
+	return fn, dur, fn != nil && dur > 0
+}
+
+// runFFmpegWithProgress runs ffmpeg with -progress on stdout and reports out_time/duration to fn.
+// It returns stderr, which carries FFmpeg's log and error messages.
+func runFFmpegWithProgress(ctx context.Context, fn ProgressFunc, duration float64, args []string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg", append([]string{"-progress", "pipe:1", "-nostats"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	readProgress(stdout, duration, fn)
+	err = cmd.Wait()
+	return stderr.Bytes(), err
+}
+
+// readProgress parses FFmpeg -progress key=value output until EOF, calling fn with the clamped fraction.
+func readProgress(r io.Reader, duration float64, fn ProgressFunc) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		sec, ok := parseProgressLine(sc.Text())
+		if !ok {
+			continue
+		}
+		f := sec / duration
+		if f < 0 {
+			f = 0
+		}
+		if f > 1 {
+			f = 1
+		}
+		fn(f)
+	}
+	// Drain so FFmpeg never blocks on a full pipe.
+	_, _ = io.Copy(io.Discard, r)
+}
+
+// parseProgressLine extracts the output position in seconds from an out_time_us (or the
+// historically misnamed out_time_ms, also microseconds) line.
+func parseProgressLine(line string) (float64, bool) {
+	k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+	if !ok || (k != "out_time_us" && k != "out_time_ms") {
+		return 0, false
+	}
+	us, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return float64(us) / 1e6, true
+}
@@ -0,0 +1,30 @@
+package video
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadProgress(t *testing.T) {
+	out := strings.Join([]string{
+		"frame=10",
+		"out_time_us=N/A",
+		"out_time_us=2500000",
+		"out_time=00:00:02.500000",
+		"progress=continue",
+		"out_time_ms=5000000",
+		"out_time_us=12000000",
+		"progress=end",
+	}, "\n")
+	var got []float64
+	readProgress(strings.NewReader(out), 10, func(f float64) { got = append(got, f) })
+	want := []float64{0.25, 0.5, 1}
+	if len(got) != len(want) {
+		t.Fatalf("fractions = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("fraction %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package video
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MaxReframeZoom is the largest zoom a reframe keyframe may ask for.
+const MaxReframeZoom = 4.0
+
+// defaultReframeFPS is assumed for zoompan when the source frame rate is unknown.
+const defaultReframeFPS = 30.0
+
+// ReframeKey is one reframe keyframe: at Time seconds (on the timeline of the stream being reframed)
+// the crop window is centred on X, Y (fractions of the source frame) and magnified Zoom times, where
+// 1 is the largest window of the output's aspect ratio.
+type ReframeKey struct {
+	Time, X, Y, Zoom float64
+}
+
+// ReframeSource describes the stream being reframed: its frame size, needed to place zoomed windows,
+// and frame rate, which zoompan has to be told to keep timestamps intact.
+type ReframeSource struct {
+	Width, Height int
+	FPS           float64
+}
+
+// Reframe scales v to cover width x height and crops a window that follows keys, easing between them
+// with smoothstep and holding still before the first and after the last. Without keys it centre-crops
+// like ScaleCrop. Zooming needs src.Width and src.Height; when they are unknown keys are followed
+// at zoom 1.
+func (g *FilterGraph) Reframe(v Stream, width, height int, keys []ReframeKey, src ReframeSource) Stream {
+	if len(keys) == 0 {
+		return g.ScaleCrop(v, width, height)
+	}
+	keys = normalizeReframeKeys(keys)
+	zmax := 1.0
+	for _, k := range keys {
+		zmax = math.Max(zmax, k.Zoom)
+	}
+	if src.Width <= 0 || src.Height <= 0 {
+		zmax = 1
+	}
+	x, y := reframeExpr(keys, "t", func(k ReframeKey) float64 { return k.X }), reframeExpr(keys, "t", func(k ReframeKey) float64 { return k.Y })
+	if zmax == 1 {
+		return g.Chain(fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d:x='clip((%s)*iw-ow/2,0,iw-ow)':y='clip((%s)*ih-oh/2,0,ih-oh)',setsar=1",
+			width, height, width, height, x, y), v)
+	}
+
+	// Two passes, since crop cannot change size per frame: crop the zoom-1 window (rendered at zmax
+	// times the output size so zooming in does not upscale), then zoompan within it. zoompan's window
+	// offset is the zoomed window's position in the cover-scaled source less the crop's.
+	cw, ch := evenRound(float64(width)*zmax), evenRound(float64(height)*zmax)
+	cover := math.Max(float64(cw)/float64(src.Width), float64(ch)/float64(src.Height))
+	sw, sh := max(cw, evenRound(float64(src.Width)*cover)), max(ch, evenRound(float64(src.Height)*cover))
+	fps := src.FPS
+	if fps <= 0 {
+		fps = defaultReframeFPS
+	}
+	zx, zy := reframeExpr(keys, "it", func(k ReframeKey) float64 { return k.X }), reframeExpr(keys, "it", func(k ReframeKey) float64 { return k.Y })
+	z := reframeExpr(keys, "it", func(k ReframeKey) float64 { return k.Zoom })
+	// offset is the zoomed window's start along an axis of full pixels, relative to the crop of win.
+	offset := func(c string, full, win int) string {
+		return fmt.Sprintf("clip((%s)*%d-%d/2/zoom,0,%d-%d/zoom)-clip((%s)*%d-%d/2,0,%d)", c, full, win, full, win, c, full, win, full-win)
+	}
+	filters := []string{
+		fmt.Sprintf("scale=%d:%d,setsar=1", sw, sh),
+		fmt.Sprintf("crop=%d:%d:x='clip((%s)*iw-ow/2,0,iw-ow)':y='clip((%s)*ih-oh/2,0,ih-oh)'", cw, ch, x, y),
+		fmt.Sprintf("zoompan=z='%s':x='%s':y='%s':d=1:s=%dx%d:fps=%g", z, offset(zx, sw, cw), offset(zy, sh, ch), width, height, fps),
+		"setsar=1",
+	}
+	return g.Chain(strings.Join(filters, ","), v)
+}
+
+// normalizeReframeKeys sorts keys by time and clamps their position to the frame and zoom to
+// [1, MaxReframeZoom]; of keys at the same millisecond the last wins.
+func normalizeReframeKeys(keys []ReframeKey) []ReframeKey {
+	sorted := append([]ReframeKey(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+	out := make([]ReframeKey, 0, len(sorted))
+	for _, k := range sorted {
+		k.Time = math.Round(k.Time*1000) / 1000
+		k.X, k.Y = math.Min(math.Max(k.X, 0), 1), math.Min(math.Max(k.Y, 0), 1)
+		k.Zoom = math.Min(math.Max(k.Zoom, 1), MaxReframeZoom)
+		if n := len(out); n > 0 && out[n-1].Time == k.Time {
+			out[n-1] = k
+			continue
+		}
+		out = append(out, k)
+	}
+	return out
+}
+
+// reframeExpr is an FFmpeg expression in the time variable tv for the value val picks from keys
+// (sorted, distinct times): constant outside the keys and smoothstep-eased between neighbours.
+func reframeExpr(keys []ReframeKey, tv string, val func(ReframeKey) float64) string {
+	constant := true
+	for _, k := range keys {
+		constant = constant && val(k) == val(keys[0])
+	}
+	if constant {
+		return fmt.Sprintf("%.4f", val(keys[0]))
+	}
+	expr := fmt.Sprintf("%.4f", val(keys[len(keys)-1]))
+	for i := len(keys) - 2; i >= 0; i-- {
+		a, b := keys[i], keys[i+1]
+		va, vb := val(a), val(b)
+		seg := fmt.Sprintf("%.4f", va)
+		if vb != va {
+			u := fmt.Sprintf("clip((%s-%.3f)/%.3f,0,1)", tv, a.Time, b.Time-a.Time)
+			seg = fmt.Sprintf("%.4f+%.4f*%s*%s*(3-2*%s)", va, vb-va, u, u, u)
+		}
+		expr = fmt.Sprintf("if(lt(%s,%.3f),%s,%s)", tv, b.Time, seg, expr)
+	}
+	return expr
+}
@@ -0,0 +1,71 @@
+package video
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReframeExpr(t *testing.T) {
+	keys := []ReframeKey{{Time: 0, X: 0.2}, {Time: 2, X: 0.8}, {Time: 4, X: 0.8}}
+	got := reframeExpr(keys, "t", func(k ReframeKey) float64 { return k.X })
+	want := "if(lt(t,2.000),0.2000+0.6000*clip((t-0.000)/2.000,0,1)*clip((t-0.000)/2.000,0,1)*(3-2*clip((t-0.000)/2.000,0,1)),if(lt(t,4.000),0.8000,0.8000))"
+	if got != want {
+		t.Errorf("reframeExpr =\n%s\nwant\n%s", got, want)
+	}
+	if got := reframeExpr(keys, "t", func(k ReframeKey) float64 { return k.Y }); got != "0.0000" {
+		t.Errorf("reframeExpr(constant) = %s, want 0.0000", got)
+	}
+}
+
+func TestNormalizeReframeKeys(t *testing.T) {
+	got := normalizeReframeKeys([]ReframeKey{
+		{Time: 3, X: 1.5, Y: -1, Zoom: 9},
+		{Time: 1, X: 0.5, Y: 0.5, Zoom: 0},
+		{Time: 1.0001, X: 0.25, Y: 0.5, Zoom: 2},
+	})
+	want := []ReframeKey{
+		{Time: 1, X: 0.25, Y: 0.5, Zoom: 2},
+		{Time: 3, X: 1, Y: 0, Zoom: MaxReframeZoom},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("normalizeReframeKeys = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("key %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFilterGraph_Reframe(t *testing.T) {
+	g := NewFilterGraph()
+	src := g.AddInput(Input{Path: "in.mp4"})
+	g.Reframe(VideoStream(src), 1080, 1920, nil, ReframeSource{})
+	if got, want := g.String(), "[0:v]scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920,setsar=1[s1]"; got != want {
+		t.Errorf("Reframe(no keys) = %s, want %s", got, want)
+	}
+
+	g = NewFilterGraph()
+	src = g.AddInput(Input{Path: "in.mp4"})
+	g.Reframe(VideoStream(src), 1080, 1920, []ReframeKey{{Time: 0, X: 0.3, Y: 0.5, Zoom: 1}}, ReframeSource{})
+	if got, want := g.String(), "[0:v]scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920:x='clip((0.3000)*iw-ow/2,0,iw-ow)':y='clip((0.5000)*ih-oh/2,0,ih-oh)',setsar=1[s1]"; got != want {
+		t.Errorf("Reframe(pan) =\n%s\nwant\n%s", got, want)
+	}
+
+	// Zoom 2 on a 1920x1080 source into 540x960: the zoom-1 window is cropped at 1080x1920 from the
+	// source cover-scaled to 3414x1920, then zoompan picks the zoomed window inside it.
+	g = NewFilterGraph()
+	src = g.AddInput(Input{Path: "in.mp4"})
+	g.Reframe(VideoStream(src), 540, 960, []ReframeKey{{Time: 0, X: 0.5, Y: 0.5, Zoom: 1}, {Time: 1, X: 0.5, Y: 0.5, Zoom: 2}}, ReframeSource{Width: 1920, Height: 1080, FPS: 25})
+	got := g.String()
+	for _, want := range []string{
+		"[0:v]scale=3414:1920,setsar=1,crop=1080:1920:x='clip((0.5000)*iw-ow/2,0,iw-ow)'",
+		"zoompan=z='if(lt(it,1.000),1.0000+1.0000*clip((it-0.000)/1.000,0,1)",
+		":x='clip((0.5000)*3414-1080/2/zoom,0,3414-1080/zoom)-clip((0.5000)*3414-1080/2,0,2334)'",
+		":d=1:s=540x960:fps=25,setsar=1[s1]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Reframe(zoom) = %s\nmissing %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	spriteTileWidth = 160
+	spriteColumns   = 10
+	spriteRows      = 10
+)
+
+// SpriteLayout is the grid of timeline thumbnails packed into each sprite sheet: one tile every
+// Interval, left to right and top to bottom, Columns x Rows tiles per sheet.
+type SpriteLayout struct {
+	Interval   time.Duration
+	TileWidth  int
+	TileHeight int
+	Columns    int
+	Rows       int
+}
+
+// SpriteLayoutFor returns the layout for a width x height source with a tile every interval. Tiles are
+// a fixed width with the source's aspect ratio.
+func SpriteLayoutFor(width, height int, interval time.Duration) SpriteLayout {
+	tileHeight := spriteTileWidth * 9 / 16
+	if width > 0 && height > 0 {
+		tileHeight = max(2, int(float64(spriteTileWidth)*float64(height)/float64(width)/2+0.5)*2)
+	}
+	return SpriteLayout{Interval: interval, TileWidth: spriteTileWidth, TileHeight: tileHeight, Columns: spriteColumns, Rows: spriteRows}
+}
+
+// PerSheet is the number of tiles in a full sheet.
+func (l SpriteLayout) PerSheet() int { return l.Columns * l.Rows }
+
+// Tiles is the number of tiles covering durationSec.
+func (l SpriteLayout) Tiles(durationSec float64) int {
+	if durationSec <= 0 || l.Interval <= 0 {
+		return 0
+	}
+	return int(math.Ceil(durationSec / l.Interval.Seconds()))
+}
+
+// SpriteSheets writes the sprite sheets of inputPath to dir as sprite_000.jpg, sprite_001.jpg, ... and
+// returns their paths in order. The last sheet may be partly empty.
+func SpriteSheets(ctx context.Context, inputPath, dir string, l SpriteLayout) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	args := []string{
+		"-y",
+		"-i", inputPath,
+		"-an",
+		"-vf", fmt.Sprintf("fps=1/%g,scale=%d:%d,setsar=1,tile=%dx%d", l.Interval.Seconds(), l.TileWidth, l.TileHeight, l.Columns, l.Rows),
+		"-q:v", "5",
+		"-start_number", "0",
+		filepath.Join(dir, "sprite_%03d.jpg"),
+	}
+	out, err := RunFFmpeg(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg sprites: %w (output: %s)", err, string(out))
+	}
+	sheets, err := filepath.Glob(filepath.Join(dir, "sprite_*.jpg"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(sheets)
+	return sheets, nil
+}
+
+// SpriteSheetName is the file name SpriteSheets gives sheet i.
+func SpriteSheetName(i int) string {
+	return fmt.Sprintf("sprite_%03d.jpg", i)
+}
+
+// ThumbnailTrack returns a WebVTT thumbnail track for sheets laid out as l over durationSec: one cue
+// per tile whose payload is the sheet URI with a #xywh= media fragment selecting the tile.
+func ThumbnailTrack(l SpriteLayout, durationSec float64, sheetURI func(sheet int) string) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	step := l.Interval.Seconds()
+	for i, n := 0, l.Tiles(durationSec); i < n; i++ {
+		start := float64(i) * step
+		end := math.Min(start+step, durationSec)
+		idx := i % l.PerSheet()
+		x, y := (idx%l.Columns)*l.TileWidth, (idx/l.Columns)*l.TileHeight
+		fmt.Fprintf(&b, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n", vttTimestamp(start), vttTimestamp(end), sheetURI(i/l.PerSheet()), x, y, l.TileWidth, l.TileHeight)
+	}
+	return b.String()
+}
+
+// vttTimestamp formats sec as HH:MM:SS.mmm.
+func vttTimestamp(sec float64) string {
+	ms := int64(math.Round(sec * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
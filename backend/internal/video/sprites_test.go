@@ -0,0 +1,36 @@
+package video
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpriteLayoutFor(t *testing.T) {
+	if l := SpriteLayoutFor(1920, 1080, 2*time.Second); l.TileWidth != 160 || l.TileHeight != 90 {
+		t.Errorf("landscape tile = %dx%d, want 160x90", l.TileWidth, l.TileHeight)
+	}
+	if l := SpriteLayoutFor(1080, 1920, 2*time.Second); l.TileHeight != 284 {
+		t.Errorf("portrait tile height = %d, want 284", l.TileHeight)
+	}
+}
+
+func TestThumbnailTrack(t *testing.T) {
+	l := SpriteLayout{Interval: 2 * time.Second, TileWidth: 160, TileHeight: 90, Columns: 2, Rows: 2}
+	got := ThumbnailTrack(l, 9, SpriteSheetName)
+	if !strings.HasPrefix(got, "WEBVTT\n\n00:00:00.000 --> 00:00:02.000\nsprite_000.jpg#xywh=0,0,160,90\n") {
+		t.Errorf("first cue wrong:\n%s", got)
+	}
+	for _, want := range []string{
+		"00:00:06.000 --> 00:00:08.000\nsprite_000.jpg#xywh=160,90,160,90\n",
+		// The fifth tile starts the next sheet and the last cue ends with the video.
+		"00:00:08.000 --> 00:00:09.000\nsprite_001.jpg#xywh=0,0,160,90\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("track missing cue %q:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, "-->"); n != 5 {
+		t.Errorf("cues = %d, want 5", n)
+	}
+}
@@ -0,0 +1,133 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"os"
+	"path/filepath"
+)
+
+// thumbnailCandidates is how many evenly spaced frames are scored when picking a thumbnail.
+const thumbnailCandidates = 6
+
+// SharpestFrame extracts candidate frames across [0, duration) of inputPath and writes the sharpest
+// (highest variance of the Laplacian, i.e. least motion blur / defocus) as a JPEG to outputPath.
+// The first and last instants are skipped: they are often black, mid-transition or frozen.
+func SharpestFrame(ctx context.Context, inputPath, outputPath string, duration float64) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp(filepath.Dir(outputPath), "thumbs-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	best, bestScore := "", -1.0
+	for i := 0; i < thumbnailCandidates; i++ {
+		ts := duration * (float64(i) + 0.5) / thumbnailCandidates
+		candidate := filepath.Join(dir, fmt.Sprintf("c%d.jpg", i))
+		if err := ExtractFrame(ctx, inputPath, ts, candidate); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			continue
+		}
+		score, err := imageSharpness(candidate)
+		if err != nil {
+			continue
+		}
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	if best == "" {
+		return fmt.Errorf("extract thumbnail: no frame could be decoded")
+	}
+	return os.Rename(best, outputPath)
+}
+
+// imageSharpness scores a JPEG by the variance of its luma Laplacian.
+func imageSharpness(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return 0, err
+	}
+	return laplacianVariance(img), nil
+}
+
+// laplacianVariance returns the variance of the 4-neighbour Laplacian over the image's luma.
+// Sharp frames have strong edges and therefore a high variance; blurred frames a low one.
+func laplacianVariance(img image.Image) float64 {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w < 3 || h < 3 {
+		return 0
+	}
+	luma := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, bl, _ := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			luma[y*w+x] = (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)) / 257
+		}
+	}
+	var sum, sumSq float64
+	n := 0
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*w + x
+			l := luma[i-w] + luma[i+w] + luma[i-1] + luma[i+1] - 4*luma[i]
+			sum += l
+			sumSq += l * l
+			n++
+		}
+	}
+	mean := sum / float64(n)
+	return sumSq/float64(n) - mean*mean
+}
+
+// PreviewOptions controls AnimatedPreview.
+type PreviewOptions struct {
+	Start    float64 // offset into the input in seconds
+	Duration float64 // preview length in seconds
+	Width    int     // output width in pixels; height keeps the aspect ratio
+	FPS      int
+}
+
+// AnimatedPreview writes a short, silent, infinitely looping animated WebP of inputPath.
+func AnimatedPreview(ctx context.Context, inputPath, outputPath string, opts PreviewOptions) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+	if opts.FPS <= 0 {
+		opts.FPS = 12
+	}
+	if opts.Width <= 0 {
+		opts.Width = 320
+	}
+	args := []string{
+		"-y",
+		"-ss", fmt.Sprintf("%.3f", opts.Start),
+		"-t", fmt.Sprintf("%.3f", opts.Duration),
+		"-i", inputPath,
+		"-vf", fmt.Sprintf("fps=%d,scale=%d:-2:flags=lanczos", opts.FPS, opts.Width),
+		"-an",
+		"-c:v", "libwebp",
+		"-lossless", "0",
+		"-quality", "60",
+		"-loop", "0",
+		outputPath,
+	}
+	out, err := RunFFmpeg(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("ffmpeg preview: %w (output: %s)", err, string(out))
+	}
+	return nil
+}
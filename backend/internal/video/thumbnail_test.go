@@ -0,0 +1,28 @@
+package video
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestLaplacianVariance_SharpBeatsBlurred(t *testing.T) {
+	sharp := image.NewGray(image.Rect(0, 0, 32, 32))
+	blurred := image.NewGray(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			// Hard-edged checkerboard vs. the same pattern as a gentle ramp.
+			if (x/4+y/4)%2 == 0 {
+				sharp.SetGray(x, y, color.Gray{Y: 255})
+			}
+			blurred.SetGray(x, y, color.Gray{Y: uint8(x * 4)})
+		}
+	}
+	s, b := laplacianVariance(sharp), laplacianVariance(blurred)
+	if s <= b {
+		t.Errorf("sharp variance %.1f should exceed blurred %.1f", s, b)
+	}
+	if flat := laplacianVariance(image.NewGray(image.Rect(0, 0, 8, 8))); flat != 0 {
+		t.Errorf("flat image variance = %v, want 0", flat)
+	}
+}
@@ -0,0 +1,183 @@
+package video
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// WaveformSampleRate is the rate of the PCM that waveforms are computed from (see ExtractAudio).
+const WaveformSampleRate = 16000
+
+// WaveformZoomLevels are the samples per peak of each waveform zoom level, coarsest first. Each level
+// is 4x more detailed than the previous one; the finest is 4 ms per peak at WaveformSampleRate.
+var WaveformZoomLevels = []int{16384, 4096, 1024, 256, 64}
+
+// Waveform is one zoom level of peak data: a min/max pair per SamplesPerPixel input samples, scaled
+// to 8 bits. It uses the data layout of the audiowaveform tool so existing front-end players can use it.
+type Waveform struct {
+	SampleRate      int    `json:"sample_rate"`
+	SamplesPerPixel int    `json:"samples_per_pixel"`
+	Data            []int8 `json:"data"`
+}
+
+// Length is the number of min/max pairs.
+func (w *Waveform) Length() int { return len(w.Data) / 2 }
+
+// waveformHeader is the audiowaveform binary (.dat) version 1 header.
+type waveformHeader struct {
+	Version         int32
+	Flags           uint32 // bit 0 set: 8-bit samples
+	SampleRate      int32
+	SamplesPerPixel int32
+	Length          uint32
+}
+
+// MarshalBinary encodes w in the audiowaveform binary format (version 1, 8-bit).
+func (w *Waveform) MarshalBinary() ([]byte, error) {
+	h := waveformHeader{Version: 1, Flags: 1, SampleRate: int32(w.SampleRate), SamplesPerPixel: int32(w.SamplesPerPixel), Length: uint32(w.Length())}
+	buf := make([]byte, binary.Size(h), binary.Size(h)+len(w.Data))
+	if _, err := binary.Encode(buf, binary.LittleEndian, h); err != nil {
+		return nil, err
+	}
+	for _, v := range w.Data {
+		buf = append(buf, byte(v))
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes the audiowaveform binary format written by MarshalBinary.
+func (w *Waveform) UnmarshalBinary(data []byte) error {
+	var h waveformHeader
+	n, err := binary.Decode(data, binary.LittleEndian, &h)
+	if err != nil {
+		return fmt.Errorf("waveform header: %w", err)
+	}
+	if h.Version != 1 || h.Flags&1 == 0 {
+		return fmt.Errorf("waveform: unsupported version %d flags %d", h.Version, h.Flags)
+	}
+	body := data[n:]
+	if uint64(len(body)) < 2*uint64(h.Length) {
+		return errors.New("waveform: truncated data")
+	}
+	w.SampleRate, w.SamplesPerPixel = int(h.SampleRate), int(h.SamplesPerPixel)
+	w.Data = make([]int8, 2*h.Length)
+	for i := range w.Data {
+		w.Data[i] = int8(body[i])
+	}
+	return nil
+}
+
+// ComputeWaveforms reads a mono 16-bit PCM WAV stream (as written by ExtractAudio) once and returns a
+// waveform per entry of samplesPerPixel.
+func ComputeWaveforms(r io.Reader, samplesPerPixel []int) ([]*Waveform, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	sampleRate, dataSize, err := readWAVHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	var samples io.Reader = br
+	if dataSize != 0 && dataSize != 0xFFFFFFFF {
+		// Streams written to a pipe carry a placeholder size; files have the real one.
+		samples = io.LimitReader(br, dataSize)
+	}
+	type acc struct {
+		min, max int16
+		n        int
+	}
+	out := make([]*Waveform, len(samplesPerPixel))
+	accs := make([]acc, len(samplesPerPixel))
+	for i, spp := range samplesPerPixel {
+		if spp <= 0 {
+			return nil, fmt.Errorf("waveform: invalid samples per pixel %d", spp)
+		}
+		out[i] = &Waveform{SampleRate: sampleRate, SamplesPerPixel: spp}
+	}
+	flush := func(i int) {
+		out[i].Data = append(out[i].Data, int8(accs[i].min>>8), int8(accs[i].max>>8))
+		accs[i] = acc{}
+	}
+
+	buf := make([]byte, 32<<10)
+	var carry []byte
+	for {
+		n, err := samples.Read(buf)
+		chunk := append(carry, buf[:n]...)
+		for j := 0; j+1 < len(chunk); j += 2 {
+			s := int16(binary.LittleEndian.Uint16(chunk[j:]))
+			for i := range accs {
+				a := &accs[i]
+				if a.n == 0 || s < a.min {
+					a.min = s
+				}
+				if a.n == 0 || s > a.max {
+					a.max = s
+				}
+				a.n++
+				if a.n == samplesPerPixel[i] {
+					flush(i)
+				}
+			}
+		}
+		carry = append(carry[:0], chunk[len(chunk)&^1:]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i := range accs {
+		if accs[i].n > 0 {
+			flush(i)
+		}
+	}
+	return out, nil
+}
+
+// readWAVHeader consumes the RIFF header up to the start of the data chunk, checks the format is
+// 16-bit mono PCM and returns the sample rate and the declared data size.
+func readWAVHeader(r io.Reader) (sampleRate int, dataSize int64, err error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return 0, 0, fmt.Errorf("wav header: %w", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return 0, 0, errors.New("wav header: not a RIFF/WAVE stream")
+	}
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return 0, 0, fmt.Errorf("wav header: %w", err)
+		}
+		size := int64(binary.LittleEndian.Uint32(hdr[4:]))
+		switch string(hdr[0:4]) {
+		case "fmt ":
+			if size < 16 {
+				return 0, 0, errors.New("wav: short fmt chunk")
+			}
+			fmtChunk := make([]byte, size+size&1)
+			if _, err := io.ReadFull(r, fmtChunk); err != nil {
+				return 0, 0, fmt.Errorf("wav fmt chunk: %w", err)
+			}
+			format := binary.LittleEndian.Uint16(fmtChunk[0:])
+			channels := binary.LittleEndian.Uint16(fmtChunk[2:])
+			bits := binary.LittleEndian.Uint16(fmtChunk[14:])
+			if format != 1 || channels != 1 || bits != 16 {
+				return 0, 0, fmt.Errorf("wav: want 16-bit mono PCM, got format %d, %d channels, %d bits", format, channels, bits)
+			}
+			sampleRate = int(binary.LittleEndian.Uint32(fmtChunk[4:]))
+		case "data":
+			if sampleRate == 0 {
+				return 0, 0, errors.New("wav: data before fmt chunk")
+			}
+			return sampleRate, size, nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, size+size&1); err != nil {
+				return 0, 0, fmt.Errorf("wav header: %w", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// testWAV builds a mono 16-bit PCM WAV with a LIST chunk before the data, as FFmpeg writes it.
+func testWAV(samples []int16) []byte {
+	var b bytes.Buffer
+	data := new(bytes.Buffer)
+	binary.Write(data, binary.LittleEndian, samples)
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(4+8+16+8+4+8+data.Len()))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, struct {
+		Size                 uint32
+		Format, Channels     uint16
+		SampleRate, ByteRate uint32
+		Align, Bits          uint16
+	}{16, 1, 1, 16000, 32000, 2, 16})
+	b.WriteString("LIST")
+	binary.Write(&b, binary.LittleEndian, uint32(4))
+	b.WriteString("INFO")
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(data.Len()))
+	b.Write(data.Bytes())
+	return b.Bytes()
+}
+
+func TestComputeWaveforms(t *testing.T) {
+	samples := []int16{0, 256, -512, 32767, -32768, 1024, 100}
+	levels, err := ComputeWaveforms(bytes.NewReader(testWAV(samples)), []int{2, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]int8{
+		{0, 1, -2, 127, -128, 4, 0, 0},
+		{-2, 127, -128, 4},
+	}
+	for i, w := range levels {
+		if w.SampleRate != 16000 || !reflect.DeepEqual(w.Data, want[i]) {
+			t.Errorf("level %d: rate %d data %v, want %v", i, w.SampleRate, w.Data, want[i])
+		}
+	}
+}
+
+func TestWaveformBinaryRoundTrip(t *testing.T) {
+	w := &Waveform{SampleRate: 16000, SamplesPerPixel: 64, Data: []int8{-3, 5, -128, 127}}
+	data, err := w.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 20+4 {
+		t.Fatalf("encoded %d bytes, want 24", len(data))
+	}
+	var got Waveform
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, w) {
+		t.Errorf("round trip = %+v, want %+v", got, *w)
+	}
+}
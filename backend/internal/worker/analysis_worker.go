@@ -3,9 +3,7 @@ package worker
 import (
 	"context"
 	"encoding/json"
-	"io"
-	"os"
-	"path/filepath"
+	"log/slog"
 
 	"reelcut/internal/ai"
 	"reelcut/internal/domain"
@@ -22,7 +20,7 @@ type AnalysisWorker struct {
 	videoRepo         repository.VideoRepository
 	transcriptionRepo repository.TranscriptionRepository
 	segmentRepo       repository.TranscriptSegmentRepository
-	storageSvc        *service.StorageService
+	sources           *service.SourceCache
 }
 
 func NewAnalysisWorker(
@@ -30,14 +28,14 @@ func NewAnalysisWorker(
 	videoRepo repository.VideoRepository,
 	transcriptionRepo repository.TranscriptionRepository,
 	segmentRepo repository.TranscriptSegmentRepository,
-	storageSvc *service.StorageService,
+	sources *service.SourceCache,
 ) *AnalysisWorker {
 	return &AnalysisWorker{
 		videoAnalysisRepo: videoAnalysisRepo,
 		videoRepo:         videoRepo,
 		transcriptionRepo: transcriptionRepo,
 		segmentRepo:       segmentRepo,
-		storageSvc:        storageSvc,
+		sources:           sources,
 	}
 }
 
@@ -56,18 +54,18 @@ func (w *AnalysisWorker) Handle(ctx context.Context, t *asynq.Task) error {
 	}
 
 	var scenesJSON json.RawMessage = []byte("[]")
+	facesJSON, _ := ai.FacesToJSON(nil)
 	if video.StoragePath != "" {
-		tmpFile, err := os.CreateTemp("", "analysis-video-*"+filepath.Ext(video.StoragePath))
-		if err == nil {
-			defer os.Remove(tmpFile.Name())
-			defer tmpFile.Close()
-			rc, err := w.storageSvc.Download(ctx, video.StoragePath)
-			if err == nil {
-				_, _ = io.Copy(tmpFile, rc)
-				rc.Close()
-				_ = tmpFile.Sync()
-				scenes, _ := ai.DetectScenes(ctx, tmpFile.Name())
-				scenesJSON, _ = ai.ScenesToJSON(scenes)
+		if localPath, release, err := w.sources.Acquire(ctx, video.StoragePath); err == nil {
+			defer release()
+			scenes, _ := ai.DetectScenes(ctx, localPath)
+			scenesJSON, _ = ai.ScenesToJSON(scenes)
+			if video.Width != nil && video.Height != nil {
+				faces, err := ai.DetectFaces(ctx, localPath, *video.Width, *video.Height)
+				if err != nil {
+					slog.Warn("analysis: face detection failed", "video_id", payload.VideoID, "err", err)
+				}
+				facesJSON, _ = ai.FacesToJSON(ai.TrackFaces(faces))
 			}
 		}
 	}
@@ -82,8 +80,6 @@ func (w *AnalysisWorker) Handle(ctx context.Context, t *asynq.Task) error {
 		}
 	}
 
-	facesJSON, _ := ai.FacesToJSON(nil)
-
 	a := &domain.VideoAnalysis{
 		ID:                uuid.New(),
 		VideoID:           uuid.MustParse(payload.VideoID),
@@ -3,13 +3,14 @@ package worker
 import (
 	"context"
 	"fmt"
-	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"reelcut/internal/ai"
 	"reelcut/internal/domain"
+	"reelcut/internal/notifier"
 	"reelcut/internal/queue"
 	"reelcut/internal/repository"
 	"reelcut/internal/service"
@@ -24,7 +25,7 @@ type analysisSuggestClips interface {
 	SuggestClips(ctx context.Context, videoID string, minDur, maxDur float64, maxSuggestions int) ([]ai.ClipSuggestion, error)
 }
 type clipCreator interface {
-	Create(ctx context.Context, userID uuid.UUID, videoID, name string, startTime, endTime float64, aspectRatio string, viralityScore *float64, isAISuggested bool) (*domain.Clip, error)
+	Create(ctx context.Context, userID uuid.UUID, videoID, name string, startTime, endTime float64, aspectRatio, resolution string, viralityScore *float64, isAISuggested bool) (*domain.Clip, error)
 }
 
 const (
@@ -40,19 +41,29 @@ type AutoCutWorker struct {
 	analysisSvc      analysisSuggestClips
 	clipSvc          clipCreator
 	storage          *service.StorageService
+	sources          *service.SourceCache
 	transcriptionRepo repository.TranscriptionRepository
 	segmentRepo      repository.TranscriptSegmentRepository
+	cutMode          videopkg.CutMode
+	jobRepo          repository.ProcessingJobRepository
+	notifier         notifier.JobNotifier
 }
 
 // NewAutoCutWorker builds an AutoCut worker. analysisSvc and clipSvc can be *service.AnalysisService and *service.ClipService or test mocks.
+// cutMode selects how cut.mp4 files are trimmed (see video.CutWithMode). jobRepo may be nil; when set,
+// cutting is tracked as an auto_cut job with live progress.
 func NewAutoCutWorker(
 	videoRepo repository.VideoRepository,
 	clipRepo repository.ClipRepository,
 	analysisSvc analysisSuggestClips,
 	clipSvc clipCreator,
 	storage *service.StorageService,
+	sources *service.SourceCache,
 	transcriptionRepo repository.TranscriptionRepository,
 	segmentRepo repository.TranscriptSegmentRepository,
+	cutMode videopkg.CutMode,
+	jobRepo repository.ProcessingJobRepository,
+	jobNotifier notifier.JobNotifier,
 ) *AutoCutWorker {
 	return &AutoCutWorker{
 		videoRepo:         videoRepo,
@@ -60,8 +71,12 @@ func NewAutoCutWorker(
 		analysisSvc:       analysisSvc,
 		clipSvc:           clipSvc,
 		storage:           storage,
+		sources:           sources,
 		transcriptionRepo: transcriptionRepo,
 		segmentRepo:       segmentRepo,
+		cutMode:           cutMode,
+		jobRepo:           jobRepo,
+		notifier:          jobNotifier,
 	}
 }
 
@@ -108,7 +123,7 @@ func (w *AutoCutWorker) Handle(ctx context.Context, t *asynq.Task) error {
 		// Test path: only create clip records (no FFmpeg cut or upload)
 		for i, s := range suggestions {
 			name := clipNameFromTranscript(transcriptSlice(segments, s.StartTime, s.EndTime), i+1)
-			_, err := w.clipSvc.Create(ctx, video.UserID, videoID, name, s.StartTime, s.EndTime, "9:16", &s.ViralityScore, true)
+			_, err := w.clipSvc.Create(ctx, video.UserID, videoID, name, s.StartTime, s.EndTime, "9:16", "", &s.ViralityScore, true)
 			if err != nil {
 				return fmt.Errorf("create clip %d: %w", i+1, err)
 			}
@@ -116,37 +131,37 @@ func (w *AutoCutWorker) Handle(ctx context.Context, t *asynq.Task) error {
 		return nil
 	}
 
-	// Download source video to temp (non-destructive: original in storage is never modified)
+	job, err := startTaskJob(ctx, w.jobRepo, w.notifier, service.JobTypeAutoCut, video)
+	if err != nil {
+		return err
+	}
+	err = w.cutClips(ctx, video, suggestions, segments, job)
+	job.Finish(ctx, err)
+	return err
+}
+
+// cutClips creates a clip per suggestion and stores its cut, each with an equal share of the job's progress.
+func (w *AutoCutWorker) cutClips(ctx context.Context, video *domain.Video, suggestions []ai.ClipSuggestion, segments []*domain.TranscriptSegment, job *taskJob) error {
+	videoID := video.ID.String()
+	// Cut each clip from the source, reading only the byte ranges it needs (non-destructive: original in storage is never modified)
 	tmpDir := filepath.Join(os.TempDir(), "reelcut", "autocut", videoID)
 	if err := os.MkdirAll(tmpDir, 0755); err != nil {
 		return fmt.Errorf("create temp dir: %w", err)
 	}
 	defer os.RemoveAll(tmpDir)
-	sourcePath := filepath.Join(tmpDir, "source.mp4")
-	rc, err := w.storage.Download(ctx, video.StoragePath)
-	if err != nil {
-		return fmt.Errorf("download source: %w", err)
-	}
-	f, err := os.Create(sourcePath)
-	if err != nil {
-		rc.Close()
-		return err
-	}
-	_, err = io.Copy(f, rc)
-	rc.Close()
-	f.Close()
-	if err != nil {
-		return err
-	}
 
 	for i, s := range suggestions {
 		name := clipNameFromTranscript(transcriptSlice(segments, s.StartTime, s.EndTime), i+1)
-		c, err := w.clipSvc.Create(ctx, video.UserID, videoID, name, s.StartTime, s.EndTime, "9:16", &s.ViralityScore, true)
+		c, err := w.clipSvc.Create(ctx, video.UserID, videoID, name, s.StartTime, s.EndTime, "9:16", "", &s.ViralityScore, true)
 		if err != nil {
 			return fmt.Errorf("create clip %d: %w", i+1, err)
 		}
 		clipPath := filepath.Join(tmpDir, c.ID.String()+".mp4")
-		if err := videopkg.Cut(ctx, sourcePath, clipPath, s.StartTime, s.EndTime); err != nil {
+		cutCtx := job.Context(ctx, s.EndTime-s.StartTime, 95*i/len(suggestions), 95*(i+1)/len(suggestions))
+		err = w.sources.WithSource(ctx, video.StoragePath, func(source string) error {
+			return videopkg.CutWithMode(cutCtx, source, clipPath, s.StartTime, s.EndTime, w.cutMode)
+		})
+		if err != nil {
 			return fmt.Errorf("cut clip %d: %w", i+1, err)
 		}
 		clipFile, err := os.Open(clipPath)
@@ -159,6 +174,9 @@ func (w *AutoCutWorker) Handle(ctx context.Context, t *asynq.Task) error {
 			return fmt.Errorf("upload clip %d: %w", i+1, err)
 		}
 		clipFile.Close()
+		if err := service.GenerateClipCovers(ctx, w.storage, c, clipPath, storageKey, s.EndTime-s.StartTime); err != nil {
+			slog.Warn("autocut: clip covers failed", "clip_id", c.ID, "err", err)
+		}
 		c.StoragePath = &storageKey
 		c.Status = "ready"
 		if err := w.clipRepo.Update(ctx, c); err != nil {
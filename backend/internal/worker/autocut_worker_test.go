@@ -9,6 +9,7 @@ import (
 	"reelcut/internal/ai"
 	"reelcut/internal/domain"
 	"reelcut/internal/queue"
+	videopkg "reelcut/internal/video"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
@@ -82,7 +83,7 @@ type mockClipCreator struct {
 	err   error
 }
 
-func (m *mockClipCreator) Create(ctx context.Context, userID uuid.UUID, videoID, name string, startTime, endTime float64, aspectRatio string, viralityScore *float64, isAISuggested bool) (*domain.Clip, error) {
+func (m *mockClipCreator) Create(ctx context.Context, userID uuid.UUID, videoID, name string, startTime, endTime float64, aspectRatio, resolution string, viralityScore *float64, isAISuggested bool) (*domain.Clip, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -153,8 +154,12 @@ func TestAutoCutWorker_Handle(t *testing.T) {
 			analysisSvc,
 			clipCreator,
 			nil, // no storage in test: worker only creates clip records
+			nil,
 			trRepo,
 			segRepo,
+			videopkg.CutModeCopy,
+			nil,
+			nil,
 		)
 		payload, _ := json.Marshal(queue.AutoCutPayload{VideoID: vid.String()})
 		task := asynq.NewTask(queue.TypeAutoCut, payload)
@@ -192,8 +197,12 @@ func TestAutoCutWorker_Handle(t *testing.T) {
 			&mockAnalysisSvc{suggestions: []ai.ClipSuggestion{{Transcript: "x"}}},
 			clipCreator,
 			nil,
+			nil,
 			&mockTranscriptionRepo{},
 			&mockSegmentRepo{},
+			videopkg.CutModeCopy,
+			nil,
+			nil,
 		)
 		payload, _ := json.Marshal(queue.AutoCutPayload{VideoID: vid.String()})
 		task := asynq.NewTask(queue.TypeAutoCut, payload)
@@ -214,8 +223,12 @@ func TestAutoCutWorker_Handle(t *testing.T) {
 			&mockAnalysisSvc{},
 			&mockClipCreator{},
 			nil,
+			nil,
 			&mockTranscriptionRepo{},
 			&mockSegmentRepo{},
+			videopkg.CutModeCopy,
+			nil,
+			nil,
 		)
 		payload, _ := json.Marshal(queue.AutoCutPayload{VideoID: vid.String()})
 		task := asynq.NewTask(queue.TypeAutoCut, payload)
@@ -233,8 +246,12 @@ func TestAutoCutWorker_Handle(t *testing.T) {
 			&mockAnalysisSvc{},
 			&mockClipCreator{},
 			nil,
+			nil,
 			&mockTranscriptionRepo{},
 			&mockSegmentRepo{},
+			videopkg.CutModeCopy,
+			nil,
+			nil,
 		)
 		task := asynq.NewTask(queue.TypeAutoCut, []byte("invalid json"))
 
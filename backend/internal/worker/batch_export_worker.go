@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"context"
+
+	"reelcut/internal/notifier"
+	"reelcut/internal/queue"
+	"reelcut/internal/service"
+
+	"github.com/hibiken/asynq"
+)
+
+// BatchExportWorker packages a finished batch render as a ZIP.
+type BatchExportWorker struct {
+	batchSvc *service.BatchRenderService
+	notifier notifier.JobNotifier
+}
+
+func NewBatchExportWorker(batchSvc *service.BatchRenderService, jobNotifier notifier.JobNotifier) *BatchExportWorker {
+	return &BatchExportWorker{batchSvc: batchSvc, notifier: jobNotifier}
+}
+
+func (w *BatchExportWorker) Register(mux *asynq.ServeMux) {
+	mux.Handle(queue.TypeBatchExport, asynq.HandlerFunc(w.Handle))
+}
+
+func (w *BatchExportWorker) Handle(ctx context.Context, t *asynq.Task) error {
+	payload, err := queue.ParseBatchExportPayload(t.Payload())
+	if err != nil {
+		return err
+	}
+	job, err := w.batchSvc.Export(ctx, payload.JobID)
+	if err != nil {
+		return err
+	}
+	if w.notifier != nil {
+		w.notifier.NotifyJob(ctx, job)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/notifier"
+	"reelcut/internal/repository"
+	"reelcut/internal/video"
+
+	"github.com/google/uuid"
+)
+
+// progressInterval is the minimum time between persisted/broadcast progress updates for one job.
+const progressInterval = time.Second
+
+// jobProgress maps FFmpeg progress onto a ProcessingJob's [from, to] percentage range, persisting and
+// notifying at most once per progressInterval. Progress never moves backwards, so several FFmpeg runs
+// under the same context (e.g. a smart cut) cannot make the bar jump back.
+type jobProgress struct {
+	ctx      context.Context
+	job      *domain.ProcessingJob
+	jobRepo  repository.ProcessingJobRepository
+	notifier notifier.JobNotifier
+	from, to int
+	last     time.Time
+	now      func() time.Time
+	// persisted, when set, is called after each progress update that was stored.
+	persisted func()
+}
+
+func newJobProgress(ctx context.Context, job *domain.ProcessingJob, jobRepo repository.ProcessingJobRepository, jobNotifier notifier.JobNotifier, from, to int) *jobProgress {
+	return &jobProgress{ctx: ctx, job: job, jobRepo: jobRepo, notifier: jobNotifier, from: from, to: to, now: time.Now}
+}
+
+// Context returns ctx with FFmpeg progress wired to p.
+func (p *jobProgress) Context(ctx context.Context) context.Context {
+	return video.WithProgress(ctx, p.Report)
+}
+
+// Report records fraction (0-1) of the FFmpeg work as done.
+func (p *jobProgress) Report(fraction float64) {
+	if p.ctx.Err() != nil {
+		return
+	}
+	pct := p.from + int(fraction*float64(p.to-p.from))
+	if pct <= p.job.Progress {
+		return
+	}
+	now := p.now()
+	if now.Sub(p.last) < progressInterval && pct < p.to {
+		return
+	}
+	p.last = now
+	p.job.Progress = pct
+	_ = p.jobRepo.UpdateProgress(p.ctx, p.job.ID.String(), pct)
+	if p.notifier != nil {
+		p.notifier.NotifyJob(p.ctx, p.job)
+	}
+	if p.persisted != nil {
+		p.persisted()
+	}
+}
+
+// taskJob is the ProcessingJob of a background FFmpeg task on an upload (transcode, waveform, sprites,
+// autocut), so its progress shows up like a render's. The job ID is derived from the task's, so asynq
+// retries reuse the job instead of adding one per attempt. A nil *taskJob tracks nothing.
+type taskJob struct {
+	job      *domain.ProcessingJob
+	jobRepo  repository.ProcessingJobRepository
+	notifier notifier.JobNotifier
+}
+
+// startTaskJob marks the jobType job of v as processing, creating it on the first attempt.
+func startTaskJob(ctx context.Context, jobRepo repository.ProcessingJobRepository, jobNotifier notifier.JobNotifier, jobType string, v *domain.Video) (*taskJob, error) {
+	if jobRepo == nil {
+		return nil, nil
+	}
+	id := uuid.NewSHA1(v.ID, []byte(jobType))
+	job, err := jobRepo.GetByID(ctx, id.String())
+	now := time.Now()
+	if err != nil || job == nil {
+		job = &domain.ProcessingJob{ID: id, UserID: v.UserID, JobType: jobType, EntityType: "video", EntityID: v.ID, Status: "processing", StartedAt: &now}
+		if err := jobRepo.Create(ctx, job); err != nil {
+			return nil, fmt.Errorf("create %s job: %w", jobType, err)
+		}
+	} else {
+		job.Status, job.Progress, job.ErrorMessage, job.StartedAt, job.CompletedAt = "processing", 0, nil, &now, nil
+		if err := jobRepo.Update(ctx, job); err != nil {
+			return nil, err
+		}
+	}
+	t := &taskJob{job: job, jobRepo: jobRepo, notifier: jobNotifier}
+	t.notify(ctx)
+	return t, nil
+}
+
+// Context returns ctx reporting the progress of FFmpeg runs expected to output duration seconds onto
+// the [from, to] range of the job.
+func (t *taskJob) Context(ctx context.Context, duration float64, from, to int) context.Context {
+	if t == nil {
+		return ctx
+	}
+	ctx = newJobProgress(ctx, t.job, t.jobRepo, t.notifier, from, to).Context(ctx)
+	return video.WithExpectedDuration(ctx, duration)
+}
+
+// Finish completes the job, or fails it with err.
+func (t *taskJob) Finish(ctx context.Context, err error) {
+	if t == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	now := time.Now()
+	t.job.CompletedAt = &now
+	if err != nil {
+		msg := err.Error()
+		t.job.Status, t.job.ErrorMessage = "failed", &msg
+	} else {
+		t.job.Status, t.job.Progress = "completed", 100
+	}
+	_ = t.jobRepo.Update(ctx, t.job)
+	t.notify(ctx)
+}
+
+func (t *taskJob) notify(ctx context.Context) {
+	if t.notifier != nil {
+		t.notifier.NotifyJob(ctx, t.job)
+	}
+}
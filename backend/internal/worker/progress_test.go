@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"reelcut/internal/domain"
+	"reelcut/internal/service"
+
+	"github.com/google/uuid"
+)
+
+// memJobRepo keeps processing jobs in memory.
+type memJobRepo struct {
+	jobs map[string]*domain.ProcessingJob
+}
+
+func (m *memJobRepo) Create(ctx context.Context, j *domain.ProcessingJob) error {
+	cp := *j
+	m.jobs[j.ID.String()] = &cp
+	return nil
+}
+func (m *memJobRepo) GetByID(ctx context.Context, id string) (*domain.ProcessingJob, error) {
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, errors.New("no rows in result set")
+	}
+	cp := *j
+	return &cp, nil
+}
+func (m *memJobRepo) ListByUserID(ctx context.Context, userID string, status *string, limit, offset int) ([]*domain.ProcessingJob, int, error) {
+	return nil, 0, nil
+}
+func (m *memJobRepo) GetByEntity(ctx context.Context, entityType, entityID string) (*domain.ProcessingJob, error) {
+	return nil, nil
+}
+func (m *memJobRepo) GetByEntityAndType(ctx context.Context, entityType, entityID, jobType string) (*domain.ProcessingJob, error) {
+	return nil, nil
+}
+func (m *memJobRepo) Update(ctx context.Context, j *domain.ProcessingJob) error {
+	cp := *j
+	m.jobs[j.ID.String()] = &cp
+	return nil
+}
+func (m *memJobRepo) UpdateUnlessCancelled(ctx context.Context, j *domain.ProcessingJob) (bool, error) {
+	if cur, ok := m.jobs[j.ID.String()]; ok && cur.Status == "cancelled" {
+		return false, nil
+	}
+	return true, m.Update(ctx, j)
+}
+func (m *memJobRepo) UpdateProgress(ctx context.Context, id string, progress int) error {
+	if j, ok := m.jobs[id]; ok && j.Status == "processing" {
+		j.Progress = progress
+	}
+	return nil
+}
+
+func TestTaskJob(t *testing.T) {
+	ctx := context.Background()
+	repo := &memJobRepo{jobs: map[string]*domain.ProcessingJob{}}
+	v := &domain.Video{ID: uuid.New(), UserID: uuid.New()}
+
+	job, err := startTaskJob(ctx, repo, nil, service.JobTypeVideoSprites, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newJobProgress(ctx, job.job, repo, nil, 0, 95)
+	p.Report(0.5)
+	stored := repo.jobs[job.job.ID.String()]
+	if stored.JobType != service.JobTypeVideoSprites || stored.EntityID != v.ID || stored.Progress != 47 {
+		t.Errorf("job = %s on %s at %d%%, want video_sprites on the video at 47%%", stored.JobType, stored.EntityID, stored.Progress)
+	}
+	job.Finish(ctx, errors.New("ffmpeg sprites: exit status 1"))
+	if stored := repo.jobs[job.job.ID.String()]; stored.Status != "failed" || stored.ErrorMessage == nil {
+		t.Errorf("after a failure: status = %s, error %v; want failed with the error", stored.Status, stored.ErrorMessage)
+	}
+
+	// The retry reuses the job, starting over.
+	retry, err := startTaskJob(ctx, repo, nil, service.JobTypeVideoSprites, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.jobs) != 1 || retry.job.ID != job.job.ID || retry.job.Progress != 0 || retry.job.ErrorMessage != nil {
+		t.Errorf("retry: %d jobs, progress %d, error %v; want the same job reset", len(repo.jobs), retry.job.Progress, retry.job.ErrorMessage)
+	}
+	retry.Finish(ctx, nil)
+	if stored := repo.jobs[job.job.ID.String()]; stored.Status != "completed" || stored.Progress != 100 {
+		t.Errorf("after success: %s at %d%%, want completed at 100%%", stored.Status, stored.Progress)
+	}
+
+	// Another task on the same video gets a job of its own.
+	if other, _ := startTaskJob(ctx, repo, nil, service.JobTypeVideoWaveform, v); other.job.ID == job.job.ID {
+		t.Error("waveform and sprites share a job")
+	}
+
+	var none *taskJob
+	if got := none.Context(ctx, 10, 0, 100); got != ctx {
+		t.Error("nil taskJob changed the context")
+	}
+	none.Finish(ctx, nil)
+}
@@ -2,9 +2,12 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"reelcut/internal/domain"
 	"reelcut/internal/notifier"
 	"reelcut/internal/queue"
 	"reelcut/internal/repository"
@@ -15,13 +18,16 @@ import (
 
 type RenderingWorker struct {
 	renderingSvc *service.RenderingService
+	batchSvc     *service.BatchRenderService
 	clipRepo     repository.ClipRepository
 	jobRepo      repository.ProcessingJobRepository
 	notifier     notifier.JobNotifier
 }
 
-func NewRenderingWorker(renderingSvc *service.RenderingService, clipRepo repository.ClipRepository, jobRepo repository.ProcessingJobRepository, jobNotifier notifier.JobNotifier) *RenderingWorker {
-	return &RenderingWorker{renderingSvc: renderingSvc, clipRepo: clipRepo, jobRepo: jobRepo, notifier: jobNotifier}
+// NewRenderingWorker builds the render worker. batchSvc may be nil; when set, renders that belong to a
+// batch report back to their parent job when they finish.
+func NewRenderingWorker(renderingSvc *service.RenderingService, batchSvc *service.BatchRenderService, clipRepo repository.ClipRepository, jobRepo repository.ProcessingJobRepository, jobNotifier notifier.JobNotifier) *RenderingWorker {
+	return &RenderingWorker{renderingSvc: renderingSvc, batchSvc: batchSvc, clipRepo: clipRepo, jobRepo: jobRepo, notifier: jobNotifier}
 }
 
 func (w *RenderingWorker) Register(mux *asynq.ServeMux) {
@@ -37,24 +43,46 @@ func (w *RenderingWorker) Handle(ctx context.Context, t *asynq.Task) error {
 	if err != nil || job == nil {
 		return fmt.Errorf("job not found: %s", payload.JobID)
 	}
+	defer w.finishBatchChild(ctx, job)
 	if job.Status == "cancelled" {
+		w.restoreClip(ctx, payload.ClipID)
 		return nil
 	}
 	job.Status = "processing"
 	job.Progress = 10
 	now := time.Now()
 	job.StartedAt = &now
-	_ = w.jobRepo.Update(ctx, job)
+	if ok, err := w.jobRepo.UpdateUnlessCancelled(ctx, job); err == nil && !ok {
+		// Cancelled since it was loaded.
+		job.Status = "cancelled"
+		w.restoreClip(ctx, payload.ClipID)
+		return nil
+	}
 	if w.notifier != nil {
 		w.notifier.NotifyJob(ctx, job)
 	}
 
-	if err := w.renderingSvc.Render(ctx, payload.ClipID); err != nil {
+	if err := w.render(ctx, job, payload.ClipID); err != nil {
+		if ctx.Err() != nil && w.cancelled(ctx, payload.JobID) {
+			// FFmpeg was killed with the task context and Render removed its temp dir; the clip keeps
+			// its previous output.
+			bg := context.WithoutCancel(ctx)
+			w.restoreClip(bg, payload.ClipID)
+			job.Status = "cancelled"
+			if w.notifier != nil {
+				w.notifier.NotifyJob(bg, job)
+			}
+			return nil
+		}
 		job.Status = "failed"
 		if errMsg := err.Error(); errMsg != "" {
 			job.ErrorMessage = &errMsg
 		}
-		_ = w.jobRepo.Update(ctx, job)
+		if ok, err := w.jobRepo.UpdateUnlessCancelled(ctx, job); err == nil && !ok {
+			job.Status = "cancelled"
+			w.restoreClip(ctx, payload.ClipID)
+			return nil
+		}
 		if w.notifier != nil {
 			w.notifier.NotifyJob(ctx, job)
 		}
@@ -70,11 +98,82 @@ func (w *RenderingWorker) Handle(ctx context.Context, t *asynq.Task) error {
 	job.Status = "completed"
 	completed := time.Now()
 	job.CompletedAt = &completed
-	if err := w.jobRepo.Update(ctx, job); err != nil {
+	ok, err := w.jobRepo.UpdateUnlessCancelled(ctx, job)
+	if err != nil {
 		return err
 	}
+	if !ok {
+		// Cancelled after FFmpeg finished: the job stays cancelled (a batch leaves the clip out of its
+		// ZIP), while the clip keeps the output it already has.
+		job.Status = "cancelled"
+	}
 	if w.notifier != nil {
 		w.notifier.NotifyJob(ctx, job)
 	}
 	return nil
 }
+
+// render runs the job's render: one output per export preset in its metadata, each with an equal share
+// of the progress bar, or the clip's own.
+func (w *RenderingWorker) render(ctx context.Context, job *domain.ProcessingJob, clipID string) error {
+	var meta domain.RenderJobMetadata
+	if len(job.Metadata) > 0 {
+		_ = json.Unmarshal(job.Metadata, &meta)
+	}
+	if len(meta.Presets) == 0 {
+		progress := w.newProgress(ctx, job, meta, 10, 95)
+		return w.renderingSvc.Render(progress.Context(ctx), clipID)
+	}
+	for i, preset := range meta.Presets {
+		from, to := 10+85*i/len(meta.Presets), 10+85*(i+1)/len(meta.Presets)
+		progress := w.newProgress(ctx, job, meta, from, to)
+		if err := w.renderingSvc.RenderExport(progress.Context(ctx), clipID, preset); err != nil {
+			return fmt.Errorf("%s export: %w", preset, err)
+		}
+	}
+	return nil
+}
+
+// newProgress reports the render's progress on job and, for a batch child, on its parent.
+func (w *RenderingWorker) newProgress(ctx context.Context, job *domain.ProcessingJob, meta domain.RenderJobMetadata, from, to int) *jobProgress {
+	progress := newJobProgress(ctx, job, w.jobRepo, w.notifier, from, to)
+	if w.batchSvc != nil && meta.ParentJobID != "" {
+		progress.persisted = func() {
+			if err := w.batchSvc.ChildProgressed(ctx, job); err != nil {
+				slog.Warn("render: batch progress update failed", "job_id", job.ID, "err", err)
+			}
+		}
+	}
+	return progress
+}
+
+// cancelled reports whether the job was cancelled by the user (as opposed to the worker shutting down).
+func (w *RenderingWorker) cancelled(ctx context.Context, jobID string) bool {
+	job, err := w.jobRepo.GetByID(context.WithoutCancel(ctx), jobID)
+	return err == nil && job != nil && job.Status == "cancelled"
+}
+
+// restoreClip takes a clip out of "rendering" after a cancelled render: it is ready again if it already
+// has an output, otherwise back to draft.
+func (w *RenderingWorker) restoreClip(ctx context.Context, clipID string) {
+	c, _ := w.clipRepo.GetByID(ctx, clipID)
+	if c == nil || c.Status != "rendering" {
+		return
+	}
+	if (c.StoragePath != nil && *c.StoragePath != "") || len(c.Exports) > 0 {
+		c.Status = "ready"
+	} else {
+		c.Status = "draft"
+	}
+	_ = w.clipRepo.Update(ctx, c)
+}
+
+// finishBatchChild reports a finished render to its batch parent, if any.
+func (w *RenderingWorker) finishBatchChild(ctx context.Context, job *domain.ProcessingJob) {
+	if w.batchSvc == nil || !domain.JobFinished(job.Status) {
+		return
+	}
+	if err := w.batchSvc.ChildFinished(context.WithoutCancel(ctx), job); err != nil {
+		slog.Warn("render: batch progress update failed", "job_id", job.ID, "err", err)
+	}
+}
@@ -3,7 +3,6 @@ package worker
 import (
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 
@@ -22,13 +21,14 @@ type TranscriptionWorker struct {
 	segmentRepo       repository.TranscriptSegmentRepository
 	wordRepo          repository.TranscriptWordRepository
 	videoRepo         repository.VideoRepository
-	storage           StorageDownloader
+	sources           SourceProvider
 	transcriber       ai.Transcriber
 	queue             *queue.QueueClient
 }
 
-type StorageDownloader interface {
-	Download(ctx context.Context, key string) (io.ReadCloser, error)
+// SourceProvider hands out local copies of source videos; *service.SourceCache implements it.
+type SourceProvider interface {
+	Acquire(ctx context.Context, key string) (path string, release func(), err error)
 }
 
 func NewTranscriptionWorker(
@@ -36,7 +36,7 @@ func NewTranscriptionWorker(
 	segmentRepo repository.TranscriptSegmentRepository,
 	wordRepo repository.TranscriptWordRepository,
 	videoRepo repository.VideoRepository,
-	storage StorageDownloader,
+	sources SourceProvider,
 	transcriber ai.Transcriber,
 	queue *queue.QueueClient,
 ) *TranscriptionWorker {
@@ -45,7 +45,7 @@ func NewTranscriptionWorker(
 		segmentRepo:      segmentRepo,
 		wordRepo:         wordRepo,
 		videoRepo:        videoRepo,
-		storage:          storage,
+		sources:          sources,
 		transcriber:      transcriber,
 		queue:            queue,
 	}
@@ -72,16 +72,12 @@ func (w *TranscriptionWorker) Handle(ctx context.Context, t *asynq.Task) error {
 	}
 	tmpDir := filepath.Join(os.TempDir(), "reelcut", v.ID.String(), tr.ID.String())
 	os.MkdirAll(tmpDir, 0755)
-	videoPath := filepath.Join(tmpDir, "video.mp4")
-	rc, err := w.storage.Download(ctx, v.StoragePath)
+	videoPath, release, err := w.sources.Acquire(ctx, v.StoragePath)
 	if err != nil {
 		w.updateStatusWithError(ctx, payload.TranscriptionID, "failed", err.Error())
 		return err
 	}
-	f, _ := os.Create(videoPath)
-	io.Copy(f, rc)
-	rc.Close()
-	f.Close()
+	defer release()
 	defer os.RemoveAll(tmpDir)
 
 	meta, err := video.GetMetadata(ctx, videoPath)
@@ -1,13 +1,17 @@
 package worker
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"reelcut/internal/domain"
 	"reelcut/internal/notifier"
 	"reelcut/internal/queue"
 	"reelcut/internal/repository"
@@ -18,19 +22,30 @@ import (
 )
 
 type VideoWorker struct {
-	videoRepo repository.VideoRepository
-	jobRepo   repository.ProcessingJobRepository
-	storage   *service.StorageService
-	notifier  notifier.JobNotifier
+	videoRepo      repository.VideoRepository
+	jobRepo        repository.ProcessingJobRepository
+	storage        *service.StorageService
+	sources        *service.SourceCache
+	spriteInterval time.Duration
+	notifier       notifier.JobNotifier
 }
 
-func NewVideoWorker(videoRepo repository.VideoRepository, jobRepo repository.ProcessingJobRepository, storage *service.StorageService, jobNotifier notifier.JobNotifier) *VideoWorker {
-	return &VideoWorker{videoRepo: videoRepo, jobRepo: jobRepo, storage: storage, notifier: jobNotifier}
+// defaultSpriteInterval is used when no positive sprite interval is configured.
+const defaultSpriteInterval = 2 * time.Second
+
+func NewVideoWorker(videoRepo repository.VideoRepository, jobRepo repository.ProcessingJobRepository, storage *service.StorageService, sources *service.SourceCache, spriteInterval time.Duration, jobNotifier notifier.JobNotifier) *VideoWorker {
+	if spriteInterval <= 0 {
+		spriteInterval = defaultSpriteInterval
+	}
+	return &VideoWorker{videoRepo: videoRepo, jobRepo: jobRepo, storage: storage, sources: sources, spriteInterval: spriteInterval, notifier: jobNotifier}
 }
 
 func (w *VideoWorker) Register(mux *asynq.ServeMux) {
 	mux.Handle(queue.TypeVideoMetadata, asynq.HandlerFunc(w.HandleMetadata))
 	mux.Handle(queue.TypeVideoThumbnail, asynq.HandlerFunc(w.HandleThumbnail))
+	mux.Handle(queue.TypeVideoTranscode, asynq.HandlerFunc(w.HandleTranscode))
+	mux.Handle(queue.TypeVideoWaveform, asynq.HandlerFunc(w.HandleWaveform))
+	mux.Handle(queue.TypeVideoSprites, asynq.HandlerFunc(w.HandleSprites))
 }
 
 func (w *VideoWorker) HandleMetadata(ctx context.Context, t *asynq.Task) error {
@@ -42,29 +57,11 @@ func (w *VideoWorker) HandleMetadata(ctx context.Context, t *asynq.Task) error {
 	if err != nil || v == nil {
 		return fmt.Errorf("video not found: %s", payload.VideoID)
 	}
-	// Download to temp file
-	tmpDir := os.TempDir()
-	localPath := filepath.Join(tmpDir, "reelcut", v.ID.String()+".mp4")
-	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
-		return err
-	}
-	rc, err := w.storage.Download(ctx, v.StoragePath)
+	localPath, release, err := w.sources.Acquire(ctx, v.StoragePath)
 	if err != nil {
 		return fmt.Errorf("download video: %w", err)
 	}
-	f, err := os.Create(localPath)
-	if err != nil {
-		rc.Close()
-		return err
-	}
-	_, err = io.Copy(f, rc)
-	rc.Close()
-	f.Close()
-	if err != nil {
-		os.Remove(localPath)
-		return err
-	}
-	defer os.Remove(localPath)
+	defer release()
 
 	meta, err := video.GetMetadata(ctx, localPath)
 	if err != nil {
@@ -81,7 +78,7 @@ func (w *VideoWorker) HandleMetadata(ctx context.Context, t *asynq.Task) error {
 	if err := w.videoRepo.Update(ctx, v); err != nil {
 		return err
 	}
-	if job, _ := w.jobRepo.GetByEntity(ctx, "video", payload.VideoID); job != nil {
+	if job, _ := w.jobRepo.GetByEntityAndType(ctx, "video", payload.VideoID, service.JobTypeVideoProcessing); job != nil {
 		job.Progress = 50
 		_ = w.jobRepo.Update(ctx, job)
 		if w.notifier != nil {
@@ -100,30 +97,16 @@ func (w *VideoWorker) HandleThumbnail(ctx context.Context, t *asynq.Task) error
 	if err != nil || v == nil {
 		return fmt.Errorf("video not found: %s", payload.VideoID)
 	}
-	tmpDir := os.TempDir()
-	localPath := filepath.Join(tmpDir, "reelcut", v.ID.String()+".mp4")
-	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
-		return err
-	}
-	rc, err := w.storage.Download(ctx, v.StoragePath)
+	localPath, release, err := w.sources.Acquire(ctx, v.StoragePath)
 	if err != nil {
 		return fmt.Errorf("download video: %w", err)
 	}
-	f, err := os.Create(localPath)
-	if err != nil {
-		rc.Close()
-		return err
-	}
-	_, err = io.Copy(f, rc)
-	rc.Close()
-	f.Close()
-	if err != nil {
-		os.Remove(localPath)
+	defer release()
+
+	thumbPath := filepath.Join(os.TempDir(), "reelcut", v.ID.String()+"_thumb.jpg")
+	if err := os.MkdirAll(filepath.Dir(thumbPath), 0755); err != nil {
 		return err
 	}
-	defer os.Remove(localPath)
-
-	thumbPath := filepath.Join(tmpDir, "reelcut", v.ID.String()+"_thumb.jpg")
 	if err := video.ExtractFrame(ctx, localPath, 0, thumbPath); err != nil {
 		return fmt.Errorf("extract frame: %w", err)
 	}
@@ -142,7 +125,7 @@ func (w *VideoWorker) HandleThumbnail(ctx context.Context, t *asynq.Task) error
 	if err := w.videoRepo.Update(ctx, v); err != nil {
 		return err
 	}
-	if job, _ := w.jobRepo.GetByEntity(ctx, "video", payload.VideoID); job != nil {
+	if job, _ := w.jobRepo.GetByEntityAndType(ctx, "video", payload.VideoID, service.JobTypeVideoProcessing); job != nil {
 		job.Progress = 100
 		job.Status = "completed"
 		now := time.Now()
@@ -154,3 +137,253 @@ func (w *VideoWorker) HandleThumbnail(ctx context.Context, t *asynq.Task) error
 	}
 	return nil
 }
+
+// proxyShortSide is the short side of the editing proxy in pixels.
+const proxyShortSide = 480
+
+// renditionContentTypes maps rendition and sprite file extensions to their upload content type.
+var renditionContentTypes = map[string]string{
+	".m3u8": "application/vnd.apple.mpegurl",
+	".ts":   "video/mp2t",
+	".mp4":  "video/mp4",
+	".jpg":  "image/jpeg",
+	".vtt":  "text/vtt",
+}
+
+// HandleTranscode produces the playback renditions of an upload: an HLS ladder that never upscales
+// the source, and a low-res proxy with dense keyframes for the editor.
+func (w *VideoWorker) HandleTranscode(ctx context.Context, t *asynq.Task) error {
+	payload, err := queue.ParseVideoTranscodePayload(t.Payload())
+	if err != nil {
+		return err
+	}
+	if err := w.setRenditions(ctx, payload.VideoID, service.RenditionsProcessing, nil); err != nil {
+		return err
+	}
+	v, err := w.videoRepo.GetByID(ctx, payload.VideoID)
+	if err != nil || v == nil {
+		return fmt.Errorf("video not found: %s", payload.VideoID)
+	}
+	job, err := startTaskJob(ctx, w.jobRepo, w.notifier, service.JobTypeVideoTranscode, v)
+	if err != nil {
+		return err
+	}
+	err = w.transcode(ctx, v, job)
+	job.Finish(ctx, err)
+	if err != nil {
+		if ctx.Err() == nil {
+			_ = w.setRenditions(context.WithoutCancel(ctx), payload.VideoID, service.RenditionsFailed, nil)
+		}
+		return err
+	}
+	return nil
+}
+
+func (w *VideoWorker) transcode(ctx context.Context, v *domain.Video, job *taskJob) error {
+	videoID := v.ID.String()
+	localPath, release, err := w.sources.Acquire(ctx, v.StoragePath)
+	if err != nil {
+		return fmt.Errorf("download video: %w", err)
+	}
+	defer release()
+	meta, err := video.GetMetadata(ctx, localPath)
+	if err != nil {
+		return fmt.Errorf("get metadata: %w", err)
+	}
+	tmpDir := filepath.Join(os.TempDir(), "reelcut", "transcode", videoID)
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	ladder := video.LadderFor(video.HLSLadder, meta.Width, meta.Height)
+	sizes := make([][2]int, len(ladder))
+	for i, r := range ladder {
+		rw, rh := video.RenditionSize(meta.Width, meta.Height, r.Height)
+		sizes[i] = [2]int{rw, rh}
+	}
+	pw, ph := video.RenditionSize(meta.Width, meta.Height, min(proxyShortSide, meta.Width, meta.Height))
+	if err := video.Transcode(job.Context(ctx, meta.DurationSeconds, 0, 95), localPath, tmpDir, ladder, sizes, [2]int{pw, ph}); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "hls", "master.m3u8"), []byte(video.MasterPlaylist(ladder, sizes)), 0644); err != nil {
+		return err
+	}
+
+	prefix := service.RenditionsPrefix(videoID)
+	if err := w.uploadDir(ctx, tmpDir, prefix); err != nil {
+		return fmt.Errorf("upload renditions: %w", err)
+	}
+	return w.setRenditions(ctx, videoID, service.RenditionsReady, func(v *domain.Video) {
+		hlsKey := path.Join(prefix, "hls", "master.m3u8")
+		proxyKey := path.Join(prefix, "proxy.mp4")
+		v.HLSPath, v.ProxyPath = &hlsKey, &proxyKey
+	})
+}
+
+// HandleWaveform decodes the audio of an upload once and stores its peaks at every zoom level of
+// video.WaveformZoomLevels, for the editor timeline.
+func (w *VideoWorker) HandleWaveform(ctx context.Context, t *asynq.Task) error {
+	payload, err := queue.ParseVideoWaveformPayload(t.Payload())
+	if err != nil {
+		return err
+	}
+	v, err := w.videoRepo.GetByID(ctx, payload.VideoID)
+	if err != nil || v == nil {
+		return fmt.Errorf("video not found: %s", payload.VideoID)
+	}
+	localPath, release, err := w.sources.Acquire(ctx, v.StoragePath)
+	if err != nil {
+		return fmt.Errorf("download video: %w", err)
+	}
+	defer release()
+
+	job, err := startTaskJob(ctx, w.jobRepo, w.notifier, service.JobTypeVideoWaveform, v)
+	if err != nil {
+		return err
+	}
+	err = w.waveform(ctx, localPath, v.ID.String(), job)
+	job.Finish(ctx, err)
+	return err
+}
+
+func (w *VideoWorker) waveform(ctx context.Context, localPath, videoID string, job *taskJob) error {
+	levels, err := w.waveforms(ctx, localPath, videoID, job)
+	if err != nil {
+		return err
+	}
+	for zoom, wf := range levels {
+		data, err := wf.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if err := w.storage.Upload(ctx, service.WaveformKey(videoID, zoom), bytes.NewReader(data), "application/octet-stream"); err != nil {
+			return fmt.Errorf("upload waveform: %w", err)
+		}
+	}
+	return nil
+}
+
+// waveforms returns the waveform of localPath at every zoom level. A source without audio gets empty
+// waveforms, so the editor shows a flat timeline instead of the task failing on every retry.
+func (w *VideoWorker) waveforms(ctx context.Context, localPath, videoID string, job *taskJob) ([]*video.Waveform, error) {
+	hasAudio, err := video.HasAudio(ctx, localPath)
+	if err != nil {
+		return nil, fmt.Errorf("probe audio: %w", err)
+	}
+	if !hasAudio {
+		levels := make([]*video.Waveform, len(video.WaveformZoomLevels))
+		for i, spp := range video.WaveformZoomLevels {
+			levels[i] = &video.Waveform{SampleRate: video.WaveformSampleRate, SamplesPerPixel: spp}
+		}
+		return levels, nil
+	}
+	meta, err := video.GetMetadata(ctx, localPath)
+	if err != nil {
+		return nil, fmt.Errorf("get metadata: %w", err)
+	}
+	audioPath := filepath.Join(os.TempDir(), "reelcut", videoID+"_waveform.wav")
+	if err := video.ExtractAudio(job.Context(ctx, meta.DurationSeconds, 0, 90), localPath, audioPath); err != nil {
+		return nil, fmt.Errorf("extract audio: %w", err)
+	}
+	defer os.Remove(audioPath)
+	f, err := os.Open(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	levels, err := video.ComputeWaveforms(f, video.WaveformZoomLevels)
+	if err != nil {
+		return nil, fmt.Errorf("compute waveform: %w", err)
+	}
+	return levels, nil
+}
+
+// HandleSprites packs a thumbnail every spriteInterval into sprite sheets and stores them with a
+// WebVTT track indexing them, so the editor can preview frames while scrubbing.
+func (w *VideoWorker) HandleSprites(ctx context.Context, t *asynq.Task) error {
+	payload, err := queue.ParseVideoSpritesPayload(t.Payload())
+	if err != nil {
+		return err
+	}
+	v, err := w.videoRepo.GetByID(ctx, payload.VideoID)
+	if err != nil || v == nil {
+		return fmt.Errorf("video not found: %s", payload.VideoID)
+	}
+	localPath, release, err := w.sources.Acquire(ctx, v.StoragePath)
+	if err != nil {
+		return fmt.Errorf("download video: %w", err)
+	}
+	defer release()
+	job, err := startTaskJob(ctx, w.jobRepo, w.notifier, service.JobTypeVideoSprites, v)
+	if err != nil {
+		return err
+	}
+	err = w.sprites(ctx, localPath, payload.VideoID, job)
+	job.Finish(ctx, err)
+	return err
+}
+
+func (w *VideoWorker) sprites(ctx context.Context, localPath, videoID string, job *taskJob) error {
+	meta, err := video.GetMetadata(ctx, localPath)
+	if err != nil {
+		return fmt.Errorf("get metadata: %w", err)
+	}
+	tmpDir := filepath.Join(os.TempDir(), "reelcut", "sprites", videoID)
+	defer os.RemoveAll(tmpDir)
+
+	layout := video.SpriteLayoutFor(meta.Width, meta.Height, w.spriteInterval)
+	sheets, err := video.SpriteSheets(job.Context(ctx, meta.DurationSeconds, 0, 95), localPath, tmpDir, layout)
+	if err != nil {
+		return err
+	}
+	// The track only references sheets that were written, in case FFmpeg stopped short of the
+	// container duration.
+	duration := min(meta.DurationSeconds, float64(len(sheets)*layout.PerSheet())*layout.Interval.Seconds())
+	track := video.ThumbnailTrack(layout, duration, video.SpriteSheetName)
+	if err := os.WriteFile(filepath.Join(tmpDir, service.ThumbnailTrackName), []byte(track), 0644); err != nil {
+		return err
+	}
+	// uploadDir walks in lexical order, so the track is stored after the sheets it points to.
+	if err := w.uploadDir(ctx, tmpDir, service.SpritesPrefix(videoID)); err != nil {
+		return fmt.Errorf("upload sprites: %w", err)
+	}
+	return nil
+}
+
+// setRenditions reloads the video and records the rendition status (and whatever update sets), so
+// fields written by the metadata and thumbnail jobs in the meantime are kept.
+func (w *VideoWorker) setRenditions(ctx context.Context, videoID, status string, update func(*domain.Video)) error {
+	v, err := w.videoRepo.GetByID(ctx, videoID)
+	if err != nil || v == nil {
+		return fmt.Errorf("video not found: %s", videoID)
+	}
+	v.RenditionsStatus = &status
+	if update != nil {
+		update(v)
+	}
+	return w.videoRepo.Update(ctx, v)
+}
+
+// uploadDir uploads every file under dir to storage below prefix, keeping relative paths.
+func (w *VideoWorker) uploadDir(ctx context.Context, dir, prefix string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		contentType, ok := renditionContentTypes[strings.ToLower(filepath.Ext(p))]
+		if !ok {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return w.storage.Upload(ctx, path.Join(prefix, filepath.ToSlash(rel)), f, contentType)
+	})
+}
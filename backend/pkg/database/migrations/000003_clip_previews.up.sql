@@ -0,0 +1,2 @@
+-- Animated preview (storage key of a looping WebP) generated next to each cut/render; thumbnail_url already exists.
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS preview_url TEXT;
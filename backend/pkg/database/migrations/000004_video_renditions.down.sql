@@ -0,0 +1,3 @@
+ALTER TABLE videos DROP COLUMN IF EXISTS renditions_status;
+ALTER TABLE videos DROP COLUMN IF EXISTS proxy_path;
+ALTER TABLE videos DROP COLUMN IF EXISTS hls_path;
@@ -0,0 +1,5 @@
+-- Playback renditions transcoded after upload: HLS master playlist and editing proxy (storage keys).
+-- renditions_status: pending, processing, ready or failed.
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS hls_path TEXT;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS proxy_path TEXT;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS renditions_status VARCHAR(20);
@@ -0,0 +1,2 @@
+ALTER TABLE clip_styles DROP COLUMN IF EXISTS silence_min_duration;
+ALTER TABLE clip_styles DROP COLUMN IF EXISTS remove_silences;
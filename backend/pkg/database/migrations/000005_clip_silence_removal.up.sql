@@ -0,0 +1,3 @@
+-- Jump cuts: remove pauses of at least silence_min_duration seconds from rendered clips.
+ALTER TABLE clip_styles ADD COLUMN IF NOT EXISTS remove_silences BOOLEAN DEFAULT false;
+ALTER TABLE clip_styles ADD COLUMN IF NOT EXISTS silence_min_duration DECIMAL(4, 2) DEFAULT 0.6;
@@ -0,0 +1 @@
+ALTER TABLE clip_styles DROP COLUMN IF EXISTS remove_fillers;
@@ -0,0 +1,2 @@
+-- Cut filler words and stutters (found with transcript word timings) from rendered clips.
+ALTER TABLE clip_styles ADD COLUMN IF NOT EXISTS remove_fillers BOOLEAN DEFAULT false;
@@ -0,0 +1,2 @@
+ALTER TABLE clips DROP COLUMN IF EXISTS fit_color;
+ALTER TABLE clips DROP COLUMN IF EXISTS fit_mode;
@@ -0,0 +1,4 @@
+-- How the source fills the clip's aspect ratio: crop, blur (fit over a blurred copy) or color
+-- (fit over fit_color, #RRGGBB).
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS fit_mode VARCHAR(20) NOT NULL DEFAULT 'crop';
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS fit_color VARCHAR(7);
@@ -0,0 +1,2 @@
+-- Output resolution tier (720p, 1080p, 1440p, 4k): the short side of the rendered frame.
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS resolution VARCHAR(10) NOT NULL DEFAULT '1080p';
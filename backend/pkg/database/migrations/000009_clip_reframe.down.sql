@@ -0,0 +1 @@
+ALTER TABLE clips DROP COLUMN IF EXISTS reframe;
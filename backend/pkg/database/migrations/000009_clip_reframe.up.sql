@@ -0,0 +1,2 @@
+-- Reframe keyframes: [{"time", "x", "y", "zoom"}, ...] steering the crop window over the clip.
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS reframe JSONB;
@@ -0,0 +1,2 @@
+-- Multi-region layout: {"preset", "regions": [{"x", "y", "w", "h"}, ...], "pip_position", "pip_scale"}.
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS layout JSONB;
@@ -0,0 +1,3 @@
+-- Multi-segment clips: [{"video_id", "start_time", "end_time"}, ...] joined in order. NULL for
+-- single-range clips, which use start_time/end_time.
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS segments JSONB;
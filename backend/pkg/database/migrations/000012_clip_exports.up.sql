@@ -0,0 +1,2 @@
+-- Platform export renders: {"<preset>": "<storage key>", ...}. NULL until the clip is exported.
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS exports JSONB;
@@ -0,0 +1,2 @@
+UPDATE clip_styles SET caption_color = LEFT(caption_color, 7) WHERE LENGTH(caption_color) > 7;
+ALTER TABLE clip_styles ALTER COLUMN caption_color TYPE VARCHAR(7);
@@ -0,0 +1,2 @@
+-- Caption colours may carry an alpha byte: "#RRGGBBAA" (caption_bg_color already fits it).
+ALTER TABLE clip_styles ALTER COLUMN caption_color TYPE VARCHAR(9);